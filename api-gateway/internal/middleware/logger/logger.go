@@ -0,0 +1,49 @@
+package mwLogger
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// secretParams are query parameters whose values must not reach the logs,
+// such as the calendar feed token, which calendar apps can only send in the
+// URL.
+var secretParams = []string{"token"}
+
+// Redacted is middleware.Logger with the values of secretParams replaced by
+// "REDACTED" in the logged URI. The proxies after it get the request as
+// it came.
+func Redacted(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri := r.RequestURI
+		restore := http.HandlerFunc(func(w http.ResponseWriter, logged *http.Request) {
+			r := logged.WithContext(logged.Context())
+			r.RequestURI = uri
+			next.ServeHTTP(w, r)
+		})
+		logged := r.WithContext(r.Context())
+		logged.RequestURI = redactQuery(uri)
+		middleware.Logger(restore).ServeHTTP(w, logged)
+	})
+}
+
+// redactQuery replaces the values of secretParams, and of parameters whose
+// name cannot be decoded, in the query of uri, leaving the rest as it is.
+func redactQuery(uri string) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok {
+		return uri
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(key); err != nil || slices.Contains(secretParams, name) {
+			params[i] = key + "=REDACTED"
+		}
+	}
+	return path + "?" + strings.Join(params, "&")
+}
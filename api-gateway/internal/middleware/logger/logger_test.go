@@ -0,0 +1,38 @@
+package mwLogger
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := middleware.DefaultLogger
+	middleware.DefaultLogger = middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: log.New(&buf, "", 0), NoColor: true})
+	t.Cleanup(func() { middleware.DefaultLogger = defaultLogger })
+
+	var got string
+	handler := Redacted(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RequestURI
+	}))
+	uri := "/calendar.ics?token=s3cr3t-feed-token&lang=en"
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, uri, nil))
+
+	line := buf.String()
+	if strings.Contains(line, "s3cr3t-feed-token") {
+		t.Errorf("logged the token: %q", line)
+	}
+	if !strings.Contains(line, "/calendar.ics?token=REDACTED&lang=en") {
+		t.Errorf("logged %q, want the redacted URI", line)
+	}
+	// The proxy still gets the token.
+	if got != uri {
+		t.Errorf("handler got %q, want %q", got, uri)
+	}
+}
@@ -2,12 +2,14 @@ package routes
 
 import (
 	"api-gateway/internal/config"
+	mwLogger "api-gateway/internal/middleware/logger"
 	"github.com/go-chi/render"
 	"log"
 	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -30,12 +32,36 @@ func newProxy(target string) http.HandlerFunc {
 	return proxy.ServeHTTP
 }
 
+// newStreamProxy is newProxy for long-lived requests such as server-sent
+// events, WebSockets and attachment uploads and downloads: every write is
+// flushed at once, and the server's read and write timeouts are lifted for the
+// request.
+func newStreamProxy(target string) http.HandlerFunc {
+	parsedURL, err := url.Parse(target)
+	if err != nil {
+		log.Fatalf("invalid proxy target %q: %v", target, err)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(parsedURL)
+	proxy.FlushInterval = -1
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.Printf("proxy error: %v", err)
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+		proxy.ServeHTTP(w, r)
+	}
+}
+
 func NewRouter(log *slog.Logger, cfg *config.Config) http.Handler {
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
 	router.Use(middleware.Recoverer)
-	router.Use(middleware.Logger)
+	router.Use(mwLogger.Redacted)
 
 	router.Get("/about", func(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, "THIS IS ABOUT PAGE")
@@ -53,10 +79,68 @@ func NewRouter(log *slog.Logger, cfg *config.Config) http.Handler {
 	router.Route("/tasks", func(r chi.Router) {
 		r.Post("/", newProxy(todoApp))
 		r.Get("/", newProxy(todoApp))
+		r.Get("/trash", newProxy(todoApp))
+		r.Get("/search", newProxy(todoApp))
+		r.Get("/events", newStreamProxy(todoApp))
+		r.Get("/calendar.ics", newProxy(todoApp))
+		r.Get("/export", newStreamProxy(todoApp))
+		r.Post("/import", newProxy(todoApp))
 		r.Get("/{id}", newProxy(todoApp))
 		r.Put("/{id}", newProxy(todoApp))
 		r.Patch("/{id}", newProxy(todoApp))
 		r.Delete("/{id}", newProxy(todoApp))
+		r.Post("/{id}/subtasks", newProxy(todoApp))
+		r.Get("/{id}/subtasks", newProxy(todoApp))
+		r.Get("/{id}/occurrences", newProxy(todoApp))
+		r.Post("/{id}/restore", newProxy(todoApp))
+		r.Get("/{id}/history", newProxy(todoApp))
+		r.Post("/{id}/revert", newProxy(todoApp))
+		r.Post("/{id}/comments", newProxy(todoApp))
+		r.Get("/{id}/comments", newProxy(todoApp))
+		r.Patch("/{id}/comments/{cid}", newProxy(todoApp))
+		r.Delete("/{id}/comments/{cid}", newProxy(todoApp))
+		r.Post("/{id}/attachments", newStreamProxy(todoApp))
+		r.Get("/{id}/attachments", newProxy(todoApp))
+		r.Get("/{id}/attachments/{aid}", newStreamProxy(todoApp))
+		r.Delete("/{id}/attachments/{aid}", newProxy(todoApp))
+		r.Post("/{id}/reminders", newProxy(todoApp))
+		r.Get("/{id}/reminders", newProxy(todoApp))
+		r.Delete("/{id}/reminders/{rid}", newProxy(todoApp))
+		r.Post("/{id}/shares", newProxy(todoApp))
+		r.Get("/{id}/shares", newProxy(todoApp))
+		r.Delete("/{id}/shares/{userId}", newProxy(todoApp))
+	})
+	router.Post("/tasks:batch", newProxy(todoApp))
+	router.Route("/projects", func(r chi.Router) {
+		r.Post("/", newProxy(todoApp))
+		r.Get("/", newProxy(todoApp))
+		r.Get("/{id}", newProxy(todoApp))
+		r.Patch("/{id}", newProxy(todoApp))
+		r.Delete("/{id}", newProxy(todoApp))
+		r.Get("/{id}/tasks", newProxy(todoApp))
+		r.Post("/{id}/shares", newProxy(todoApp))
+		r.Get("/{id}/shares", newProxy(todoApp))
+		r.Delete("/{id}/shares/{userId}", newProxy(todoApp))
+	})
+	router.Route("/webhooks", func(r chi.Router) {
+		r.Post("/", newProxy(todoApp))
+		r.Get("/", newProxy(todoApp))
+		r.Get("/{id}", newProxy(todoApp))
+		r.Patch("/{id}", newProxy(todoApp))
+		r.Delete("/{id}", newProxy(todoApp))
+		r.Get("/{id}/deliveries", newProxy(todoApp))
+		r.Post("/{id}/deliveries/{did}/redeliver", newProxy(todoApp))
+	})
+	router.Route("/calendar", func(r chi.Router) {
+		r.Post("/token", newProxy(todoApp))
+		r.Get("/token", newProxy(todoApp))
+		r.Delete("/token", newProxy(todoApp))
+	})
+	router.Route("/notifications", func(r chi.Router) {
+		r.Get("/", newProxy(todoApp))
+		r.Post("/read", newProxy(todoApp))
+		r.Get("/settings", newProxy(todoApp))
+		r.Put("/settings", newProxy(todoApp))
 	})
 	// DB := cfg.DBServiceAddress
 	// router.Route("/tasks", func(r chi.Router) {
@@ -4,6 +4,7 @@ import (
 	"auth/internal/config"
 	"auth/internal/handlers/login"
 	"auth/internal/handlers/logout"
+	"auth/internal/handlers/lookup"
 	"auth/internal/handlers/refresh"
 	"auth/internal/handlers/register"
 	mwLogger "auth/internal/middleware/logger"
@@ -71,6 +72,7 @@ func main() {
 	router.Post("/auth/login", login.Login(log, storage, tokemMn))
 	router.Post("/auth/logout", logout.Logout(log, redisRepository, tokemMn))
 	router.Post("/auth/refresh", refresh.RefreshTokens(log, redisRepository, tokemMn))
+	router.Get("/auth/users/lookup", lookup.Lookup(log, storage, tokemMn))
 
 	log.Info("starting server", slog.String("address", cfg.Address))
 
@@ -13,3 +13,8 @@ type Login struct {
 type RFToken struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+// LookupUser is the query of GET /auth/users/lookup.
+type LookupUser struct {
+	Email string `validate:"required,email"`
+}
@@ -15,3 +15,9 @@ func Error(msg string) Response {
 		Error:  msg,
 	}
 }
+
+// User is what other services may learn about a user: never the password.
+type User struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
@@ -0,0 +1,88 @@
+package lookup
+
+import (
+	"auth/internal/domain/model"
+	"auth/internal/domain/requests"
+	"auth/internal/domain/response"
+	"auth/internal/lib/logger/sl"
+	"auth/internal/lib/validate"
+	"auth/internal/storage/db"
+	"auth/internal/token"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserGetter
+type UserGetter interface {
+	GetByEmail(email string) (*model.User, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TokenParser
+type TokenParser interface {
+	ParseToken(tokenStr string) (*token.Claims, error)
+}
+
+// Lookup finds a user by ?email=, so that other services can refer to
+// people by the address their users know them by, as todo-app does when a
+// task is shared. The caller forwards the access token of the user asking;
+// without one the endpoint would tell anybody who has an account.
+func Lookup(log *slog.Logger, users UserGetter, tokenMn TokenParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.lookup.Lookup"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			log.Warn("access token missing")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("invalid token"))
+			return
+		}
+		claims, err := tokenMn.ParseToken(bearer)
+		if err != nil {
+			log.Warn("failed to parse access token", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("invalid token"))
+			return
+		}
+		if claims.TokenType != "access" {
+			log.Warn("invalid token type", slog.String("type", claims.TokenType))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("invalid token type"))
+			return
+		}
+
+		req := requests.LookupUser{Email: r.URL.Query().Get("email")}
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid email"))
+			return
+		}
+
+		user, err := users.GetByEmail(req.Email)
+		if err != nil {
+			if errors.Is(err, db.ErrUserNotFound) {
+				log.Info("user not found", slog.Int64("user_id", claims.UserID))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.Error("user not found"))
+				return
+			}
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.User{ID: user.ID, Email: user.Email})
+	}
+}
@@ -0,0 +1,125 @@
+package lookup
+
+import (
+	"auth/internal/domain/model"
+	"auth/internal/domain/response"
+	"auth/internal/handlers/lookup/mocks"
+	"auth/internal/lib/logger/slogdiscard"
+	"auth/internal/storage/db"
+	"auth/internal/token"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		email          string
+		authorization  string
+		claims         *token.Claims
+		tokenError     error
+		mockUser       *model.User
+		mockError      error
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:           "Success",
+			email:          "ann@mail.com",
+			authorization:  "Bearer access_token",
+			claims:         &token.Claims{UserID: 1, TokenType: "access"},
+			mockUser:       &model.User{ID: 7, Email: "ann@mail.com", Password: "hash"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "User not found",
+			email:          "nobody@mail.com",
+			authorization:  "Bearer access_token",
+			claims:         &token.Claims{UserID: 1, TokenType: "access"},
+			mockError:      db.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+			respError:      "user not found",
+		},
+		{
+			name:           "Storage error",
+			email:          "ann@mail.com",
+			authorization:  "Bearer access_token",
+			claims:         &token.Claims{UserID: 1, TokenType: "access"},
+			mockError:      errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+		{
+			name:           "Invalid email",
+			email:          "ann",
+			authorization:  "Bearer access_token",
+			claims:         &token.Claims{UserID: 1, TokenType: "access"},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid email",
+		},
+		{
+			name:           "No token",
+			email:          "ann@mail.com",
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid token",
+		},
+		{
+			name:           "Invalid token",
+			email:          "ann@mail.com",
+			authorization:  "Bearer forged",
+			tokenError:     errors.New("parse token: signature is invalid"),
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid token",
+		},
+		{
+			name:           "Refresh token",
+			email:          "ann@mail.com",
+			authorization:  "Bearer refresh_token",
+			claims:         &token.Claims{UserID: 1, TokenType: "refresh"},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid token type",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokenMn := mocks.NewTokenParser(t)
+			authMock := mocks.NewUserGetter(t)
+
+			if tc.claims != nil || tc.tokenError != nil {
+				tokenMn.On("ParseToken", tc.authorization[len("Bearer "):]).Return(tc.claims, tc.tokenError)
+			}
+			if tc.mockUser != nil || tc.mockError != nil {
+				authMock.On("GetByEmail", tc.email).Return(tc.mockUser, tc.mockError)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/auth/users/lookup?email="+tc.email, nil)
+			require.NoError(t, err)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rr := httptest.NewRecorder()
+			Lookup(slogdiscard.NewDiscardLogger(), authMock, tokenMn).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+
+			if tc.expectedStatus == http.StatusOK {
+				var resp map[string]any
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, map[string]any{"id": float64(7), "email": "ann@mail.com"}, resp)
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ const (
 func main() {
 	cfg := config.MustLoad()
 	log := setupLogger(cfg.Env)
+	// Handlers have no logger of their own and log through the default one.
+	slog.SetDefault(log)
 	// grpc
 	server.Run(log,cfg)
 
@@ -6,16 +6,25 @@ import (
 	"os"
 	"db/internal/storage/db/postgres"
 	"db/internal/storage/cache/redis"
+	"db/internal/outbox"
 	"time"
 )
 
 type Config struct {
 	Postgres postgres.Config
 	Redis redis.Config
+	Outbox outbox.Config  `yaml:"outbox"`
 	Env                   string        `yaml:"env" env-default:"local"`
 	Address               string        `yaml:"address"`
 	Timeout               time.Duration `yaml:"timeout"`
 	IdleTimeout           time.Duration `yaml:"idle_timeout"`
+	// Trashed tasks are purged once they are older than TrashRetention.
+	TrashRetention        time.Duration `yaml:"trash_retention" env:"TRASH_RETENTION" env-default:"720h"`
+	TrashPurgeInterval    time.Duration `yaml:"trash_purge_interval" env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+	// MetricsAddress serves Prometheus metrics on /metrics; empty disables it.
+	MetricsAddress        string        `yaml:"metrics_address" env:"METRICS_ADDRESS"`
+	// AttachmentQuota is how many bytes of attachments a user may keep; 0 disables it.
+	AttachmentQuota       int64         `yaml:"attachment_quota" env:"ATTACHMENT_QUOTA" env-default:"1073741824"`
 }
 
 func MustLoad() *Config {
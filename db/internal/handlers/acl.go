@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Roles a user can have on a task or project. Only editor and viewer are
+// stored; the owner is whoever created the task or project.
+const (
+	roleOwner  = "owner"
+	roleEditor = "editor"
+	roleViewer = "viewer"
+)
+
+var roleRank = map[string]int{roleViewer: 1, roleEditor: 2, roleOwner: 3}
+
+// scopeShared lists the tasks other users shared with the caller.
+const scopeShared = "shared"
+
+const shareColumns = "user_id, email, role, created_by, created_at"
+
+var errShareNotFound = status.Error(codes.NotFound, "share not found")
+
+func scanShare(row pgx.Row) (*dbpb.Share, error) {
+	share := dbpb.Share{}
+	var createdAt time.Time
+	if err := row.Scan(&share.UserId, &share.Email, &share.Role, &share.CreatedBy, &createdAt); err != nil {
+		return nil, err
+	}
+	share.CreatedAt = timestamppb.New(createdAt)
+	return &share, nil
+}
+
+// checkRole turns a role that falls short of want into an error. Users
+// without any role get notFound, so they cannot tell what exists.
+func checkRole(role, want string, notFound error) error {
+	if role == "" {
+		return notFound
+	}
+	if roleRank[role] < roleRank[want] {
+		return status.Errorf(codes.PermissionDenied, "%s role required", want)
+	}
+	return nil
+}
+
+// taskOwner checks that uid has at least the role want on a task and returns
+// the owner of the task, whose id the queries on the task then use. Shares of
+// the task, of any of its ancestors and of the projects of those count, and
+// the best of them wins. Only the owner sees trashed tasks.
+func taskOwner(ctx context.Context, db dbtx, op string, uid int64, taskID any, want string) (int64, error) {
+	var owner int64
+	var trashed bool
+	var role string
+	// editor sorts before viewer, so min picks the better role.
+	query := `WITH RECURSIVE chain AS (
+			      SELECT id, parent_id, project_id FROM task WHERE id = $1
+			      UNION ALL
+			      SELECT t.id, t.parent_id, t.project_id FROM task t JOIN chain ON t.id = chain.parent_id
+			  )
+			  SELECT user_id, deleted_at IS NOT NULL,
+			         COALESCE((SELECT min(s.role) FROM task_share s
+			                   WHERE s.user_id = $2
+			                     AND (s.task_id IN (SELECT id FROM chain)
+			                          OR s.project_id IN (SELECT project_id FROM chain))), '')
+			  FROM task
+			  WHERE id = $1`
+	if err := db.QueryRow(ctx, query, taskID, uid).Scan(&owner, &trashed, &role); err != nil {
+		return 0, taskError(op, err)
+	}
+	if owner == uid {
+		role = roleOwner
+	} else if trashed {
+		role = ""
+	}
+	if err := checkRole(role, want, errTaskNotFound); err != nil {
+		return 0, err
+	}
+	return owner, nil
+}
+
+// projectOwner is taskOwner for projects.
+func projectOwner(ctx context.Context, db dbtx, op string, uid, projectID int64, want string) (int64, error) {
+	var owner int64
+	var role string
+	query := `SELECT user_id,
+			         COALESCE((SELECT role FROM task_share WHERE project_id = project.id AND user_id = $2), '')
+			  FROM project
+			  WHERE id = $1`
+	if err := db.QueryRow(ctx, query, projectID, uid).Scan(&owner, &role); err != nil {
+		return 0, projectError(op, err)
+	}
+	if owner == uid {
+		role = roleOwner
+	}
+	if err := checkRole(role, want, errProjectNotFound); err != nil {
+		return 0, err
+	}
+	return owner, nil
+}
+
+// newTaskOwner is the user a new task belongs to: the owner of its parent or
+// project, to which editors may add tasks, or else the caller.
+func newTaskOwner(ctx context.Context, db dbtx, op string, uid int64, req *dbpb.CreateTaskRequest) (int64, error) {
+	switch {
+	case req.GetParentId() != 0:
+		owner, err := taskOwner(ctx, db, op, uid, req.GetParentId(), roleEditor)
+		if errors.Is(err, errTaskNotFound) {
+			return 0, status.Error(codes.NotFound, "parent task not found")
+		}
+		return owner, err
+	case req.GetProjectId() != 0:
+		return projectOwner(ctx, db, op, uid, req.GetProjectId(), roleEditor)
+	}
+	return uid, nil
+}
+
+// shareTarget is what a share RPC works on: tasks or projects.
+type shareTarget struct {
+	// column is the column of task_share that refers to the target.
+	column string
+	owner  func(ctx context.Context, db dbtx, op string, uid, id int64, want string) (int64, error)
+}
+
+var (
+	taskTarget = shareTarget{
+		column: "task_id",
+		owner: func(ctx context.Context, db dbtx, op string, uid, id int64, want string) (int64, error) {
+			return taskOwner(ctx, db, op, uid, id, want)
+		},
+	}
+	projectTarget = shareTarget{column: "project_id", owner: projectOwner}
+)
+
+func (s *Server) ShareTask(ctx context.Context, req *dbpb.ShareRequest) (*dbpb.Share, error) {
+	return s.share(ctx, "db/internal/handlers|ShareTask()", taskTarget, req)
+}
+
+func (s *Server) ListTaskShares(ctx context.Context, req *dbpb.ListSharesRequest) (*dbpb.ListSharesResponse, error) {
+	return s.listShares(ctx, "db/internal/handlers|ListTaskShares()", taskTarget, req)
+}
+
+func (s *Server) UnshareTask(ctx context.Context, req *dbpb.UnshareRequest) (*emptypb.Empty, error) {
+	return s.unshare(ctx, "db/internal/handlers|UnshareTask()", taskTarget, req)
+}
+
+func (s *Server) ShareProject(ctx context.Context, req *dbpb.ShareRequest) (*dbpb.Share, error) {
+	return s.share(ctx, "db/internal/handlers|ShareProject()", projectTarget, req)
+}
+
+func (s *Server) ListProjectShares(ctx context.Context, req *dbpb.ListSharesRequest) (*dbpb.ListSharesResponse, error) {
+	return s.listShares(ctx, "db/internal/handlers|ListProjectShares()", projectTarget, req)
+}
+
+func (s *Server) UnshareProject(ctx context.Context, req *dbpb.UnshareRequest) (*emptypb.Empty, error) {
+	return s.unshare(ctx, "db/internal/handlers|UnshareProject()", projectTarget, req)
+}
+
+// share gives a user a role on the target, or changes the role they have.
+// Sharing again without an email keeps the one given before.
+func (s *Server) share(ctx context.Context, op string, target shareTarget, req *dbpb.ShareRequest) (*dbpb.Share, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetRole() != roleEditor && req.GetRole() != roleViewer {
+		return nil, status.Error(codes.InvalidArgument, "role must be editor or viewer")
+	}
+	if req.GetUserId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	owner, err := target.owner(ctx, s.DB, op, uid, req.GetId(), roleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetUserId() == owner {
+		return nil, status.Error(codes.InvalidArgument, "cannot share with the owner")
+	}
+	query := `INSERT INTO task_share (` + target.column + `, user_id, email, role, created_by)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (` + target.column + `, user_id) WHERE ` + target.column + ` IS NOT NULL
+			  DO UPDATE SET role = EXCLUDED.role,
+			                email = COALESCE(NULLIF(EXCLUDED.email, ''), task_share.email)
+			  RETURNING ` + shareColumns
+	share, err := scanShare(s.DB.QueryRow(ctx, query, req.GetId(), req.GetUserId(), req.GetEmail(), req.GetRole(), uid))
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	return share, nil
+}
+
+// listShares returns the owner of the target and who it is shared with, in
+// the order they were invited. Every collaborator may see them.
+func (s *Server) listShares(ctx context.Context, op string, target shareTarget, req *dbpb.ListSharesRequest) (*dbpb.ListSharesResponse, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := target.owner(ctx, s.DB, op, uid, req.GetId(), roleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + shareColumns + `
+			  FROM task_share
+			  WHERE ` + target.column + ` = $1
+			  ORDER BY id`
+	rows, err := s.DB.Query(ctx, query, req.GetId())
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListSharesResponse{OwnerId: owner}
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		resp.Shares = append(resp.Shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
+
+// unshare takes a user's role on the target away. The owner removes anybody;
+// collaborators can only leave.
+func (s *Server) unshare(ctx context.Context, op string, target shareTarget, req *dbpb.UnshareRequest) (*emptypb.Empty, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	want := roleOwner
+	if req.GetUserId() == uid {
+		want = roleViewer
+	}
+	if _, err := target.owner(ctx, s.DB, op, uid, req.GetId(), want); err != nil {
+		return nil, err
+	}
+
+	query := `DELETE FROM task_share WHERE ` + target.column + ` = $1 AND user_id = $2`
+	tag, err := s.DB.Exec(ctx, query, req.GetId(), req.GetUserId())
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errShareNotFound
+	}
+	return &emptypb.Empty{}, nil
+}
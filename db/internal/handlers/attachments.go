@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/outbox"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const attachmentColumns = "id, task_id, filename, content_type, size, sha256, created_at"
+
+var errAttachmentNotFound = status.Error(codes.NotFound, "attachment not found")
+
+const maxBlobDeletionClaim = 100
+
+var sha256Hex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func scanAttachment(row pgx.Row) (*dbpb.Attachment, error) {
+	attachment := dbpb.Attachment{}
+	var createdAt time.Time
+	err := row.Scan(
+		&attachment.Id,
+		&attachment.TaskId,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Sha256,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	attachment.CreatedAt = timestamppb.New(createdAt)
+	return &attachment, nil
+}
+
+// attachmentError is storageError for attachment queries, where a missing row
+// means a missing attachment rather than a missing task.
+func attachmentError(op string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errAttachmentNotFound
+	}
+	return storageError(op, err)
+}
+
+// CreateAttachment records the metadata of an uploaded file. The user's quota,
+// Server.AttachmentQuota, is checked under a per-user advisory lock, so
+// parallel uploads cannot both squeeze under it.
+func (s *Server) CreateAttachment(ctx context.Context, req *dbpb.CreateAttachmentRequest) (*dbpb.Attachment, error) {
+	const op = "db/internal/handlers|CreateAttachment()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetFilename() == "" {
+		return nil, status.Error(codes.InvalidArgument, "filename is required")
+	}
+	if req.GetSize() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "size must not be negative")
+	}
+	if !sha256Hex.MatchString(req.GetSha256()) {
+		return nil, status.Error(codes.InvalidArgument, "sha256 must be 64 lowercase hex digits")
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	owner, err := checkTask(ctx, tx, op, uid, req.GetTaskId(), roleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if quota := s.AttachmentQuota; quota > 0 {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, uid); err != nil {
+			return nil, storageError(op, err)
+		}
+		var used int64
+		query := `SELECT COALESCE(sum(size), 0) FROM task_attachment WHERE user_id = $1`
+		if err := tx.QueryRow(ctx, query, uid).Scan(&used); err != nil {
+			return nil, storageError(op, err)
+		}
+		if used+req.GetSize() > quota {
+			return nil, status.Errorf(codes.ResourceExhausted,
+				"attachment quota exceeded: %d of %d bytes used", used, quota)
+		}
+	}
+
+	query := `INSERT INTO task_attachment (task_id, user_id, filename, content_type, size, sha256)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING ` + attachmentColumns
+	attachment, err := scanAttachment(tx.QueryRow(ctx, query,
+		req.GetTaskId(), uid, req.GetFilename(), req.GetContentType(), req.GetSize(), req.GetSha256()))
+	if err != nil {
+		return nil, attachmentError(op, err)
+	}
+	if err := enqueue(ctx, tx, op, attachmentEvent(ctx, owner, outbox.AttachmentAdded, attachment.GetTaskId(), attachment.GetId())); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return attachment, nil
+}
+
+func (s *Server) GetAttachment(ctx context.Context, req *dbpb.GetAttachmentRequest) (*dbpb.Attachment, error) {
+	const op = "db/internal/handlers|GetAttachment()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkTask(ctx, s.DB, op, uid, req.GetTaskId(), roleViewer); err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + attachmentColumns + `
+			  FROM task_attachment
+			  WHERE id = $1 AND task_id = $2`
+	attachment, err := scanAttachment(s.DB.QueryRow(ctx, query, req.GetId(), req.GetTaskId()))
+	if err != nil {
+		return nil, attachmentError(op, err)
+	}
+	return attachment, nil
+}
+
+func (s *Server) ListAttachments(ctx context.Context, req *dbpb.ListAttachmentsRequest) (*dbpb.ListAttachmentsResponse, error) {
+	const op = "db/internal/handlers|ListAttachments()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkTask(ctx, s.DB, op, uid, req.GetTaskId(), roleViewer); err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + attachmentColumns + `
+			  FROM task_attachment
+			  WHERE task_id = $1
+			  ORDER BY id`
+	rows, err := s.DB.Query(ctx, query, req.GetTaskId())
+	if err != nil {
+		return nil, attachmentError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListAttachmentsResponse{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, attachmentError(op, err)
+		}
+		resp.Attachments = append(resp.Attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, attachmentError(op, err)
+	}
+	return resp, nil
+}
+
+func (s *Server) DeleteAttachment(ctx context.Context, req *dbpb.DeleteAttachmentRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|DeleteAttachment()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	owner, err := checkTask(ctx, tx, op, uid, req.GetTaskId(), roleEditor)
+	if err != nil {
+		return nil, err
+	}
+	query := `DELETE FROM task_attachment WHERE id = $1 AND task_id = $2`
+	tag, err := tx.Exec(ctx, query, req.GetId(), req.GetTaskId())
+	if err != nil {
+		return nil, attachmentError(op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errAttachmentNotFound
+	}
+	if err := enqueue(ctx, tx, op, attachmentEvent(ctx, owner, outbox.AttachmentDeleted, req.GetTaskId(), req.GetId())); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ClaimBlobDeletions leases the tombstones the task_attachment trigger leaves
+// for deleted attachments, of all users. Replicas of the sweeper skip each
+// other's rows, and a lease that runs out hands a tombstone out again.
+func (s *Server) ClaimBlobDeletions(ctx context.Context, req *dbpb.ClaimBlobDeletionsRequest) (*dbpb.BlobDeletions, error) {
+	const op = "db/internal/handlers|ClaimBlobDeletions()"
+	limit := int(req.GetLimit())
+	if limit <= 0 || limit > maxBlobDeletionClaim {
+		limit = maxBlobDeletionClaim
+	}
+
+	query := `UPDATE attachment_tombstone
+			  SET leased_until = NOW() + $2::int * interval '1 second'
+			  WHERE id IN (
+			      SELECT id FROM attachment_tombstone
+			      WHERE leased_until IS NULL OR leased_until <= NOW()
+			      ORDER BY id
+			      LIMIT $1
+			      FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING id, task_id, attachment_id`
+	rows, err := s.DB.Query(ctx, query, limit, leaseSeconds(req.GetLeaseSeconds()))
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.BlobDeletions{}
+	for rows.Next() {
+		d := &dbpb.BlobDeletion{}
+		if err := rows.Scan(&d.Id, &d.TaskId, &d.AttachmentId); err != nil {
+			return nil, storageError(op, err)
+		}
+		resp.Deletions = append(resp.Deletions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
+
+// CompleteBlobDeletions drops the tombstones whose blobs the sweeper removed.
+func (s *Server) CompleteBlobDeletions(ctx context.Context, req *dbpb.CompleteBlobDeletionsRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|CompleteBlobDeletions()"
+	if len(req.GetIds()) == 0 {
+		return &emptypb.Empty{}, nil
+	}
+	if _, err := s.DB.Exec(ctx, `DELETE FROM attachment_tombstone WHERE id = ANY($1)`, req.GetIds()); err != nil {
+		return nil, storageError(op, err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/lib/pgtest"
+	"db/internal/lib/userctx"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rail52/myprojects/dbpb"
+)
+
+// TestBlobDeletions checks that attachments removed with their task leave
+// their blobs to the sweeper, whichever way the task goes.
+func TestBlobDeletions(t *testing.T) {
+	s := &Server{DB: pgtest.New(t)}
+	ctx := userctx.WithUserID(context.Background(), 1)
+
+	tests := []struct {
+		name   string
+		remove func(t *testing.T, id string)
+	}{
+		{
+			name: "Purged from the trash",
+			remove: func(t *testing.T, id string) {
+				if _, err := s.DeleteTask(ctx, &dbpb.DeleteTaskRequest{Id: id}); err != nil {
+					t.Fatal(err)
+				}
+				n, err := s.PurgeTrash(ctx, time.Now().UTC().Add(time.Hour))
+				if err != nil || n != 1 {
+					t.Fatalf("PurgeTrash = %d, %v; want 1 task", n, err)
+				}
+			},
+		},
+		{
+			name: "Deleted for good",
+			remove: func(t *testing.T, id string) {
+				if _, err := s.DeleteTask(ctx, &dbpb.DeleteTaskRequest{Id: id, Permanent: true}); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := s.CreateTask(ctx, &dbpb.CreateTaskRequest{Title: "report", Content: "with a file"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			attachment, err := s.CreateAttachment(ctx, &dbpb.CreateAttachmentRequest{
+				TaskId:      task.GetId(),
+				Filename:    "report.txt",
+				ContentType: "text/plain",
+				Size:        3,
+				Sha256:      strings.Repeat("a", 64),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.remove(t, strconv.FormatInt(task.GetId(), 10))
+
+			// The sweeper runs without a user.
+			claim := &dbpb.ClaimBlobDeletionsRequest{Limit: 10, LeaseSeconds: 60}
+			got, err := s.ClaimBlobDeletions(context.Background(), claim)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.GetDeletions()) != 1 {
+				t.Fatalf("claimed %d deletions, want 1", len(got.GetDeletions()))
+			}
+			d := got.GetDeletions()[0]
+			if d.GetTaskId() != task.GetId() || d.GetAttachmentId() != attachment.GetId() {
+				t.Fatalf("claimed task %d attachment %d, want %d and %d",
+					d.GetTaskId(), d.GetAttachmentId(), task.GetId(), attachment.GetId())
+			}
+
+			again, err := s.ClaimBlobDeletions(context.Background(), claim)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(again.GetDeletions()) != 0 {
+				t.Fatal("a leased deletion was claimed again")
+			}
+
+			if _, err := s.CompleteBlobDeletions(context.Background(), &dbpb.CompleteBlobDeletionsRequest{Ids: []int64{d.GetId()}}); err != nil {
+				t.Fatal(err)
+			}
+			var left int
+			if err := s.DB.QueryRow(context.Background(), `SELECT count(*) FROM attachment_tombstone`).Scan(&left); err != nil {
+				t.Fatal(err)
+			}
+			if left != 0 {
+				t.Fatalf("%d tombstones left", left)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxBatchSize = 100
+
+const (
+	batchCreate   = "create"
+	batchUpdate   = "update"
+	batchMarkDone = "mark_done"
+	batchDelete   = "delete"
+)
+
+// BatchTasks applies several task writes in one transaction. See
+// BatchTasksRequest.partial for how failures are handled.
+func (s *Server) BatchTasks(ctx context.Context, req *dbpb.BatchTasksRequest) (*dbpb.BatchTasksResponse, error) {
+	const op = "db/internal/handlers|BatchTasks()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ops := req.GetOperations()
+	if len(ops) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "batch is empty")
+	}
+	if len(ops) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch has %d operations, at most %d are allowed", len(ops), maxBatchSize)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	resp := &dbpb.BatchTasksResponse{Results: make([]*dbpb.BatchResult, 0, len(ops))}
+	for i, o := range ops {
+		var task *dbpb.Task
+		if req.GetPartial() {
+			task, err = runInSavepoint(ctx, tx, uid, o)
+		} else {
+			task, err = runBatchOperation(ctx, tx, uid, o)
+		}
+		if err != nil {
+			st := status.Convert(storageError(op, err))
+			if !req.GetPartial() {
+				return nil, status.Errorf(st.Code(), "operation %d: %s", i, st.Message())
+			}
+			resp.Results = append(resp.Results, &dbpb.BatchResult{Code: int32(st.Code()), Error: st.Message()})
+			continue
+		}
+		resp.Results = append(resp.Results, &dbpb.BatchResult{Task: task})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
+
+// runInSavepoint keeps a failed operation from aborting the whole transaction.
+func runInSavepoint(ctx context.Context, tx pgx.Tx, uid int64, o *dbpb.BatchOperation) (*dbpb.Task, error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer sp.Rollback(ctx)
+
+	task, err := runBatchOperation(ctx, sp, uid, o)
+	if err != nil {
+		return nil, err
+	}
+	if err := sp.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func runBatchOperation(ctx context.Context, db dbtx, uid int64, o *dbpb.BatchOperation) (*dbpb.Task, error) {
+	switch o.GetAction() {
+	case batchCreate:
+		if o.GetCreate() == nil {
+			return nil, missingBatchRequest(o.GetAction())
+		}
+		return createTask(ctx, db, uid, o.GetCreate())
+	case batchUpdate:
+		if o.GetUpdate() == nil {
+			return nil, missingBatchRequest(o.GetAction())
+		}
+		return updateTask(ctx, db, uid, o.GetUpdate())
+	case batchMarkDone:
+		if o.GetMarkDone() == nil {
+			return nil, missingBatchRequest(o.GetAction())
+		}
+		return markAsDone(ctx, db, uid, o.GetMarkDone())
+	case batchDelete:
+		if o.GetDelete() == nil {
+			return nil, missingBatchRequest(o.GetAction())
+		}
+		return nil, deleteTask(ctx, db, uid, o.GetDelete())
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown batch action %q", o.GetAction())
+	}
+}
+
+func missingBatchRequest(action string) error {
+	return status.Error(codes.InvalidArgument, fmt.Sprintf("%s operation has no %s request", action, action))
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	errCalendarTokenNotFound = status.Error(codes.NotFound, "calendar token not found")
+	errInvalidCalendarToken  = status.Error(codes.Unauthenticated, "invalid calendar token")
+)
+
+// newCalendarToken returns a random feed token.
+func newCalendarToken() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:])
+	return "cal_" + hex.EncodeToString(b[:])
+}
+
+func hashCalendarToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+func (s *Server) CreateCalendarToken(ctx context.Context, _ *emptypb.Empty) (*dbpb.CalendarToken, error) {
+	const op = "db/internal/handlers|CreateCalendarToken()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token := newCalendarToken()
+	var createdAt time.Time
+	query := `INSERT INTO calendar_token (user_id, token_hash)
+			  VALUES ($1, $2)
+			  ON CONFLICT (user_id) DO UPDATE
+			  SET token_hash = EXCLUDED.token_hash, created_at = NOW(), last_used_at = NULL
+			  RETURNING created_at`
+	if err := s.DB.QueryRow(ctx, query, uid, hashCalendarToken(token)).Scan(&createdAt); err != nil {
+		return nil, storageError(op, err)
+	}
+	return &dbpb.CalendarToken{Token: token, CreatedAt: timestamppb.New(createdAt)}, nil
+}
+
+// GetCalendarToken tells whether the caller has a feed token and when it was
+// last used; the token itself is not kept.
+func (s *Server) GetCalendarToken(ctx context.Context, _ *emptypb.Empty) (*dbpb.CalendarToken, error) {
+	const op = "db/internal/handlers|GetCalendarToken()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdAt time.Time
+	var lastUsedAt *time.Time
+	query := `SELECT created_at, last_used_at FROM calendar_token WHERE user_id = $1`
+	err = s.DB.QueryRow(ctx, query, uid).Scan(&createdAt, &lastUsedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errCalendarTokenNotFound
+	}
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	token := &dbpb.CalendarToken{CreatedAt: timestamppb.New(createdAt)}
+	if lastUsedAt != nil {
+		token.LastUsedAt = timestamppb.New(*lastUsedAt)
+	}
+	return token, nil
+}
+
+func (s *Server) RevokeCalendarToken(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|RevokeCalendarToken()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.DB.Exec(ctx, `DELETE FROM calendar_token WHERE user_id = $1`, uid)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errCalendarTokenNotFound
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ResolveCalendarToken returns the owner of a feed token and notes that the
+// feed was read.
+func (s *Server) ResolveCalendarToken(ctx context.Context, req *dbpb.ResolveCalendarTokenRequest) (*dbpb.ResolveCalendarTokenResponse, error) {
+	const op = "db/internal/handlers|ResolveCalendarToken()"
+	if req.GetToken() == "" {
+		return nil, errInvalidCalendarToken
+	}
+	resp := &dbpb.ResolveCalendarTokenResponse{}
+	query := `UPDATE calendar_token SET last_used_at = NOW() WHERE token_hash = $1 RETURNING user_id`
+	err := s.DB.QueryRow(ctx, query, hashCalendarToken(req.GetToken())).Scan(&resp.UserId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errInvalidCalendarToken
+	}
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
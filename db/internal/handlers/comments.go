@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/outbox"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxCommentLength = 10000
+
+// commentColumns keeps every comment query in the same order as scanComment
+// expects. The last column counts the edits.
+const commentColumns = "id, task_id, user_id, body, created_at, updated_at, " +
+	"(SELECT count(*) FROM task_comment_revision r WHERE r.comment_id = task_comment.id)"
+
+// commentCursorSort marks comment cursors so a task cursor is not accepted.
+const commentCursorSort = "comment"
+
+var errCommentNotFound = status.Error(codes.NotFound, "comment not found")
+
+func scanComment(row pgx.Row) (*dbpb.Comment, error) {
+	comment := dbpb.Comment{}
+	var createdAt, updatedAt time.Time
+	err := row.Scan(
+		&comment.Id,
+		&comment.TaskId,
+		&comment.AuthorId,
+		&comment.Body,
+		&createdAt,
+		&updatedAt,
+		&comment.EditCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	comment.CreatedAt = timestamppb.New(createdAt)
+	comment.UpdatedAt = timestamppb.New(updatedAt)
+	return &comment, nil
+}
+
+// commentError is storageError for comment queries, where a missing row means
+// a missing comment rather than a missing task.
+func commentError(op string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errCommentNotFound
+	}
+	return storageError(op, err)
+}
+
+func checkCommentBody(body string) error {
+	if body == "" {
+		return status.Error(codes.InvalidArgument, "body is required")
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return status.Errorf(codes.InvalidArgument, "body must be up to %d characters", maxCommentLength)
+	}
+	return nil
+}
+
+// checkTask makes sure the task is live and the user has at least the role
+// want on it, and returns the owner of the task, whose stream its events go to.
+func checkTask(ctx context.Context, db dbtx, op string, uid, taskID int64, want string) (int64, error) {
+	owner, err := taskOwner(ctx, db, op, uid, taskID, want)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	query := `SELECT id FROM task WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	if err := db.QueryRow(ctx, query, taskID, owner).Scan(&id); err != nil {
+		return 0, taskError(op, err)
+	}
+	return owner, nil
+}
+
+// commentMissError explains why a write by the author matched no comment:
+// either it is not on this task or somebody else wrote it.
+func commentMissError(ctx context.Context, db dbtx, op string, taskID, id int64) error {
+	var author int64
+	query := `SELECT user_id FROM task_comment WHERE id = $1 AND task_id = $2`
+	if err := db.QueryRow(ctx, query, id, taskID).Scan(&author); err != nil {
+		return commentError(op, err)
+	}
+	return status.Error(codes.PermissionDenied, "only the author can change a comment")
+}
+
+// loadHistory fills the history of comments with their previous bodies.
+func loadHistory(ctx context.Context, db dbtx, comments []*dbpb.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*dbpb.Comment, len(comments))
+	ids := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		byID[c.GetId()] = c
+		ids = append(ids, c.GetId())
+	}
+	query := `SELECT comment_id, body, edited_at
+			  FROM task_comment_revision
+			  WHERE comment_id = ANY($1)
+			  ORDER BY comment_id, id`
+	rows, err := db.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var commentID int64
+		var editedAt time.Time
+		rev := &dbpb.CommentRevision{}
+		if err := rows.Scan(&commentID, &rev.Body, &editedAt); err != nil {
+			return err
+		}
+		rev.EditedAt = timestamppb.New(editedAt)
+		c := byID[commentID]
+		c.History = append(c.History, rev)
+	}
+	return rows.Err()
+}
+
+func (s *Server) CreateComment(ctx context.Context, req *dbpb.CreateCommentRequest) (*dbpb.Comment, error) {
+	const op = "db/internal/handlers|CreateComment()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCommentBody(req.GetBody()); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	owner, err := checkTask(ctx, tx, op, uid, req.GetTaskId(), roleEditor)
+	if err != nil {
+		return nil, err
+	}
+	query := `INSERT INTO task_comment (task_id, user_id, body)
+			  VALUES ($1, $2, $3)
+			  RETURNING ` + commentColumns
+	comment, err := scanComment(tx.QueryRow(ctx, query, req.GetTaskId(), uid, req.GetBody()))
+	if err != nil {
+		return nil, commentError(op, err)
+	}
+	if err := enqueue(ctx, tx, op, commentEvent(ctx, owner, outbox.CommentAdded, comment.GetTaskId(), comment.GetId())); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return comment, nil
+}
+
+// ListComments pages through the comments of a task, oldest first.
+func (s *Server) ListComments(ctx context.Context, req *dbpb.ListCommentsRequest) (*dbpb.ListCommentsResponse, error) {
+	const op = "db/internal/handlers|ListComments()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkTask(ctx, s.DB, op, uid, req.GetTaskId(), roleViewer); err != nil {
+		return nil, err
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	var afterID int64
+	if req.GetAfter() != "" {
+		c, err := decodeCursor(req.GetAfter())
+		if err != nil || c.Sort != commentCursorSort {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		afterID = c.ID
+	}
+
+	// One extra row tells whether another page exists.
+	query := `SELECT ` + commentColumns + `
+			  FROM task_comment
+			  WHERE task_id = $1 AND id > $2
+			  ORDER BY id
+			  LIMIT $3`
+	rows, err := s.DB.Query(ctx, query, req.GetTaskId(), afterID, limit+1)
+	if err != nil {
+		return nil, commentError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListCommentsResponse{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, commentError(op, err)
+		}
+		resp.Comments = append(resp.Comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, commentError(op, err)
+	}
+
+	if len(resp.Comments) > limit {
+		resp.Comments = resp.Comments[:limit]
+		resp.NextCursor = encodeCursor(cursor{Sort: commentCursorSort, ID: resp.Comments[limit-1].GetId()})
+	}
+	if req.GetIncludeHistory() {
+		if err := loadHistory(ctx, s.DB, resp.Comments); err != nil {
+			return nil, commentError(op, err)
+		}
+	}
+	return resp, nil
+}
+
+// UpdateComment replaces the body of a comment and keeps the old one in its
+// history. Unchanged bodies are not recorded.
+func (s *Server) UpdateComment(ctx context.Context, req *dbpb.UpdateCommentRequest) (*dbpb.Comment, error) {
+	const op = "db/internal/handlers|UpdateComment()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCommentBody(req.GetBody()); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	owner, err := checkTask(ctx, tx, op, uid, req.GetTaskId(), roleEditor)
+	if err != nil {
+		return nil, err
+	}
+	query := `INSERT INTO task_comment_revision (comment_id, body)
+			  SELECT id, body FROM task_comment
+			  WHERE id = $1 AND task_id = $2 AND user_id = $3 AND body <> $4`
+	if _, err := tx.Exec(ctx, query, req.GetId(), req.GetTaskId(), uid, req.GetBody()); err != nil {
+		return nil, commentError(op, err)
+	}
+	query = `UPDATE task_comment
+			 SET body = $4, updated_at = CASE WHEN body = $4 THEN updated_at ELSE NOW() END
+			 WHERE id = $1 AND task_id = $2 AND user_id = $3
+			 RETURNING ` + commentColumns
+	comment, err := scanComment(tx.QueryRow(ctx, query, req.GetId(), req.GetTaskId(), uid, req.GetBody()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, commentMissError(ctx, tx, op, req.GetTaskId(), req.GetId())
+	}
+	if err != nil {
+		return nil, commentError(op, err)
+	}
+	if err := loadHistory(ctx, tx, []*dbpb.Comment{comment}); err != nil {
+		return nil, commentError(op, err)
+	}
+	if err := enqueue(ctx, tx, op, commentEvent(ctx, owner, outbox.CommentUpdated, comment.GetTaskId(), comment.GetId())); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return comment, nil
+}
+
+func (s *Server) DeleteComment(ctx context.Context, req *dbpb.DeleteCommentRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|DeleteComment()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	owner, err := checkTask(ctx, tx, op, uid, req.GetTaskId(), roleEditor)
+	if err != nil {
+		return nil, err
+	}
+	query := `DELETE FROM task_comment WHERE id = $1 AND task_id = $2 AND user_id = $3`
+	tag, err := tx.Exec(ctx, query, req.GetId(), req.GetTaskId(), uid)
+	if err != nil {
+		return nil, commentError(op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, commentMissError(ctx, tx, op, req.GetTaskId(), req.GetId())
+	}
+	if err := enqueue(ctx, tx, op, commentEvent(ctx, owner, outbox.CommentDeleted, req.GetTaskId(), req.GetId())); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Postgres SQLSTATE codes the handlers translate into specific gRPC codes.
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgNotNullViolation     = "23502"
+	pgCheckViolation       = "23514"
+	pgStringTooLong        = "22001"
+	pgInvalidTextRepr      = "22P02"
+	pgNumericOutOfRange    = "22003"
+	pgTooManyConnections   = "53300"
+	pgAdminShutdown        = "57P01"
+	pgCannotConnectNow     = "57P03"
+	pgConnectionExceptions = "08"
+)
+
+// errNotFound is what storageError makes of a query that found no row. It does
+// not know what the row was: handlers that can name it wrap storageError, as
+// taskError and projectError do.
+var errNotFound = status.Error(codes.NotFound, "not found")
+
+// storageError converts an error from pgx into a gRPC status error, so todo-app
+// can tell a missing row from bad input or an unavailable database. Errors
+// that already carry a status are returned untouched.
+func storageError(op string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return errNotFound
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "request timed out")
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == pgUniqueViolation:
+			// The detail quotes the conflicting values, which may belong to
+			// somebody else; it is only fit for the log.
+			slog.Warn("unique violation", slog.String("op", op),
+				slog.String("constraint", pgErr.ConstraintName), slog.String("detail", pgErr.Detail))
+			return status.Error(codes.AlreadyExists, "already exists")
+		case pgErr.Code == pgForeignKeyViolation,
+			pgErr.Code == pgNotNullViolation,
+			pgErr.Code == pgCheckViolation,
+			pgErr.Code == pgStringTooLong,
+			pgErr.Code == pgInvalidTextRepr,
+			pgErr.Code == pgNumericOutOfRange:
+			return status.Error(codes.InvalidArgument, pgErr.Message)
+		case pgErr.Code == pgTooManyConnections,
+			pgErr.Code == pgAdminShutdown,
+			pgErr.Code == pgCannotConnectNow,
+			strings.HasPrefix(pgErr.Code, pgConnectionExceptions):
+			return status.Error(codes.Unavailable, "database is unavailable")
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return status.Error(codes.Unavailable, "database is unavailable")
+	}
+
+	return status.Error(codes.Internal, fmt.Sprintf("%s: %v", op, err))
+}
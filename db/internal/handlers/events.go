@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/lib/userctx"
+	"db/internal/outbox"
+	"strconv"
+)
+
+// historyEvents maps the task_history actions to the events published for
+// them, so every recorded change is also announced.
+var historyEvents = map[string]string{
+	historyCreated:  outbox.TaskCreated,
+	historyUpdated:  outbox.TaskUpdated,
+	historyDone:     outbox.TaskDone,
+	historyTrashed:  outbox.TaskTrashed,
+	historyRestored: outbox.TaskRestored,
+	historyReverted: outbox.TaskReverted,
+}
+
+// newEvent starts an event of uid in the request of ctx. When a collaborator
+// of uid made the change, actor_id tells who.
+func newEvent(ctx context.Context, uid int64, eventType string) *outbox.Event {
+	event := outbox.New(eventType, uid, userctx.RequestID(ctx))
+	if actor := actorID(ctx, uid); actor != uid {
+		event = event.With("actor_id", strconv.FormatInt(actor, 10))
+	}
+	return event
+}
+
+// actorID is the user making the request of ctx. Changes of shared tasks run
+// as the owner of the task, uid, while their actor is the collaborator.
+func actorID(ctx context.Context, uid int64) int64 {
+	if actor, ok := userctx.UserID(ctx); ok {
+		return actor
+	}
+	return uid
+}
+
+// enqueue adds event to the outbox. It has to run in the transaction of the
+// change, so the event is published exactly when the change is committed.
+func enqueue(ctx context.Context, db dbtx, op string, event *outbox.Event) error {
+	if err := outbox.Enqueue(ctx, db, event); err != nil {
+		return storageError(op, err)
+	}
+	return nil
+}
+
+func commentEvent(ctx context.Context, uid int64, eventType string, taskID, commentID int64) *outbox.Event {
+	return newEvent(ctx, uid, eventType).WithTaskID(taskID).With("comment_id", strconv.FormatInt(commentID, 10))
+}
+
+func attachmentEvent(ctx context.Context, uid int64, eventType string, taskID, attachmentID int64) *outbox.Event {
+	return newEvent(ctx, uid, eventType).WithTaskID(taskID).With("attachment_id", strconv.FormatInt(attachmentID, 10))
+}
+
+func projectEvent(ctx context.Context, uid int64, eventType string, projectID int64) *outbox.Event {
+	return newEvent(ctx, uid, eventType).With("project_id", strconv.FormatInt(projectID, 10))
+}
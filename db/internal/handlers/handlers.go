@@ -2,10 +2,16 @@ package handlers
 
 import (
 	"context"
-	"fmt"
+	"db/internal/lib/userctx"
+	"db/internal/outbox"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rail52/myprojects/dbpb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -13,184 +19,604 @@ import (
 	// "net"
 	// "os"
 	"time"
+	"unicode/utf8"
 	// "todo-app/internal/config"
 	// "todo-app/internal/lib/logger/handlers/slogpretty"
 )
 
+// taskColumns keeps every task query in the same order as scanTask expects.
+// The last two columns count the direct subtasks for TaskProgress.
+const taskColumns = "id, title, content, is_done, created_at, updated_at, version, due_at, priority, tags, description, project_id, parent_id, rrule, deleted_at, " +
+	"COALESCE(external_id, ''), " +
+	"(SELECT count(*) FILTER (WHERE sub.is_done) FROM task sub WHERE sub.parent_id = task.id AND sub.deleted_at IS NULL), " +
+	"(SELECT count(*) FROM task sub WHERE sub.parent_id = task.id AND sub.deleted_at IS NULL)"
+
+var errTaskNotFound = status.Error(codes.NotFound, "task not found")
+
+// maxDescription caps the Markdown description, which is TEXT in the db and
+// would otherwise take any size the gRPC message allows.
+const maxDescription = 10000
+
+func checkDescription(description string) error {
+	if utf8.RuneCountInString(description) > maxDescription {
+		return status.Errorf(codes.InvalidArgument, "description must be at most %d characters", maxDescription)
+	}
+	return nil
+}
+
+// taskError is storageError for task queries, where a missing row means a
+// missing task.
+func taskError(op string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errTaskNotFound
+	}
+	return storageError(op, err)
+}
+
 type Server struct {
 	dbpb.UnimplementedPostgresServer
 	DB *pgxpool.Pool
+	// Events wakes WatchTasks streams; nil disables them.
+	Events *outbox.Listener
+	// AttachmentQuota is how many bytes of attachments a user may keep; 0
+	// means no limit.
+	AttachmentQuota int64
 }
 
-func (s *Server) CreateTask(ctx context.Context, req *dbpb.CreateTaskRequest) (*dbpb.Task, error) {
-	const op = "db/internal/handlers|CreateTask()"
+// dbtx is what task writes need from Postgres. Both the pool and a pgx.Tx
+// satisfy it, so BatchTasks can run the same code inside its transaction;
+// Begin on a pgx.Tx opens a savepoint.
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// scanTask reads the taskColumns of row; extra receives any columns selected
+// after them.
+func scanTask(row pgx.Row, extra ...any) (*dbpb.Task, error) {
 	task := dbpb.Task{}
 	var createdAt, updatedAt time.Time
-	query := `INSERT INTO task (title, content) 
-			  VALUES ($1, $2)
-			  RETURNING id, title, content, is_done, created_at, updated_at`
-	err := s.DB.QueryRow(ctx, query, req.GetTitle(), req.GetContent()).Scan(
+	var dueAt, deletedAt *time.Time
+	var projectID, parentID *int64
+	var done, total int32
+	dest := []any{
 		&task.Id,
 		&task.Title,
 		&task.Content,
 		&task.IsDone,
 		&createdAt,
 		&updatedAt,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("%s: failed to execute query: %w", op, err)
+		&task.Version,
+		&dueAt,
+		&task.Priority,
+		&task.Tags,
+		&task.Description,
+		&projectID,
+		&parentID,
+		&task.Rrule,
+		&deletedAt,
+		&task.ExternalId,
+		&done,
+		&total,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
 	}
 	task.CreatedAt = timestamppb.New(createdAt)
 	task.UpdatedAt = timestamppb.New(updatedAt)
-
+	if dueAt != nil {
+		task.DueAt = timestamppb.New(*dueAt)
+	}
+	if projectID != nil {
+		task.ProjectId = *projectID
+	}
+	if parentID != nil {
+		task.ParentId = *parentID
+	}
+	if deletedAt != nil {
+		task.DeletedAt = timestamppb.New(*deletedAt)
+	}
+	if total > 0 {
+		task.Progress = &dbpb.TaskProgress{Done: done, Total: total}
+	}
 	return &task, nil
 }
+
+// optionalTime turns an unset timestamp into SQL NULL.
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// SystemMethods are the RPCs that serve background jobs for all users at once,
+// or find the user of a request that has no token. They are called without a
+// user id.
+var SystemMethods = []string{
+	"/dbpb.Postgres/ClaimWebhookDeliveries",
+	"/dbpb.Postgres/ClaimDueReminders",
+	"/dbpb.Postgres/ResolveCalendarToken",
+	"/dbpb.Postgres/ClaimBlobDeletions",
+	"/dbpb.Postgres/CompleteBlobDeletions",
+}
+
+func userID(ctx context.Context) (int64, error) {
+	id, ok := userctx.UserID(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "user id is missing")
+	}
+	return id, nil
+}
+
+// writeMissError explains why a conditional write matched no row: either the
+// task does not exist for this user or its version has moved on.
+func writeMissError(ctx context.Context, db dbtx, op string, id any, uid int64, expected *int64) error {
+	if expected == nil {
+		return errTaskNotFound
+	}
+	var version int64
+	query := `SELECT version FROM task WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	if err := db.QueryRow(ctx, query, id, uid).Scan(&version); err != nil {
+		return taskError(op, err)
+	}
+	return status.Errorf(codes.FailedPrecondition, "task version is %d, not %d", version, *expected)
+}
+
+// parentProject checks that the parent task belongs to the user and returns
+// its project, which new subtasks inherit.
+func parentProject(ctx context.Context, db dbtx, op string, uid, parentID int64) (int64, error) {
+	var projectID *int64
+	query := `SELECT project_id FROM task WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	if err := db.QueryRow(ctx, query, parentID, uid).Scan(&projectID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, status.Error(codes.NotFound, "parent task not found")
+		}
+		return 0, storageError(op, err)
+	}
+	if projectID == nil {
+		return 0, nil
+	}
+	return *projectID, nil
+}
+
+func (s *Server) CreateTask(ctx context.Context, req *dbpb.CreateTaskRequest) (*dbpb.Task, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return createTask(ctx, s.DB, uid, req)
+}
+
+func createTask(ctx context.Context, db dbtx, uid int64, req *dbpb.CreateTaskRequest) (*dbpb.Task, error) {
+	const op = "db/internal/handlers|CreateTask()"
+	uid, err := newTaskOwner(ctx, db, op, uid, req)
+	if err != nil {
+		return nil, err
+	}
+	values, err := checkNewTask(ctx, db, op, uid, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO task (title, content, user_id, due_at, priority, tags, description, project_id, parent_id, rrule, recur_start)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), NULLIF($9, 0), $10, $11)
+			  RETURNING ` + taskColumns
+	task, err := scanTask(tx.QueryRow(ctx, query,
+		req.GetTitle(), req.GetContent(), uid, optionalTime(req.GetDueAt()), values.priority, values.tags, req.GetDescription(), values.projectID, req.GetParentId(),
+		req.GetRrule(), values.recurStart))
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	if err := recordHistory(ctx, tx, op, uid, historyCreated, nil, task, diffTasks(nil, task)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return task, nil
+}
+
+// newTaskValues are the columns of a new task that are not taken from the
+// request as they are.
+type newTaskValues struct {
+	priority  string
+	tags      []string
+	projectID int64
+	// The first due date anchors a recurring series.
+	recurStart *time.Time
+}
+
+// checkNewTask validates req and fills in the defaults of a new task.
+func checkNewTask(ctx context.Context, db dbtx, op string, uid int64, req *dbpb.CreateTaskRequest) (newTaskValues, error) {
+	if req.GetTitle() == "" {
+		return newTaskValues{}, status.Error(codes.InvalidArgument, "title is required")
+	}
+	if err := checkDescription(req.GetDescription()); err != nil {
+		return newTaskValues{}, err
+	}
+	values := newTaskValues{
+		priority:  req.GetPriority(),
+		tags:      req.GetTags(),
+		projectID: req.GetProjectId(),
+	}
+	if values.priority == "" {
+		values.priority = "normal"
+	}
+	if values.tags == nil {
+		values.tags = []string{}
+	}
+	if req.GetParentId() != 0 {
+		parentProject, err := parentProject(ctx, db, op, uid, req.GetParentId())
+		if err != nil {
+			return newTaskValues{}, err
+		}
+		if values.projectID == 0 {
+			values.projectID = parentProject
+		}
+	}
+	if err := checkProject(ctx, db, op, uid, values.projectID); err != nil {
+		return newTaskValues{}, err
+	}
+	if req.GetRrule() != "" {
+		if err := checkRRule(req.GetRrule()); err != nil {
+			return newTaskValues{}, err
+		}
+		if req.GetDueAt() == nil {
+			return newTaskValues{}, status.Error(codes.InvalidArgument, "recurring tasks need a due date")
+		}
+		values.recurStart = optionalTime(req.GetDueAt())
+	}
+	return values, nil
+}
+
 func (s *Server) GetTasks(_ *emptypb.Empty, stream grpc.ServerStreamingServer[dbpb.Task]) error {
 	const op = "db/internal/handlers|GetTasks()"
-	var task dbpb.Task
-	var createdAt, updatedAt time.Time
+	ctx := stream.Context()
+	uid, err := userID(ctx)
+	if err != nil {
+		return err
+	}
 
-	query := "SELECT * from task"
-	rows, err := s.DB.Query(context.Background(), query)
+	query := `SELECT ` + taskColumns + `
+			  FROM task
+			  WHERE user_id = $1 AND deleted_at IS NULL
+			  ORDER BY id`
+	rows, err := s.DB.Query(ctx, query, uid)
 	if err != nil {
-		return fmt.Errorf("%s: failed to execute query: %w", op, err)
+		return storageError(op, err)
 	}
+	defer rows.Close()
 	for rows.Next() {
-		err := rows.Scan(
-			&task.Id,
-			&task.Title,
-			&task.Content,
-			&task.IsDone,
-			&createdAt,
-			&updatedAt,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
-			return fmt.Errorf("%s: failed to Scan() the part of query: %w", op, err)
+			return storageError(op, err)
 		}
-		task.CreatedAt = timestamppb.New(createdAt)
-		task.UpdatedAt = timestamppb.New(updatedAt)
-		if err := stream.Send(&task); err != nil {
+		if err := stream.Send(task); err != nil {
 			return err
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return storageError(op, err)
+	}
 	return nil
 }
 
 func (s *Server) GetTask(ctx context.Context, req *dbpb.GetTaskRequest) (*dbpb.Task, error) {
-	const op = "db/internal/handlers|CreateTask()"
-	task := dbpb.Task{}
-	var createdAt, updatedAt time.Time
+	const op = "db/internal/handlers|GetTask()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := taskOwner(ctx, s.DB, op, uid, req.GetId(), roleViewer)
+	if err != nil {
+		return nil, err
+	}
 
-	// query := "select * from task WHERE id ="+string(id)
-	query := `select * 
-			  from task 
-			  WHERE id = $1`
-	err := s.DB.QueryRow(ctx, query, req.GetId()).Scan(
-		&task.Id,
-		&task.Title,
-		&task.Content,
-		&task.IsDone,
-		&createdAt,
-		&updatedAt,
-	)
+	query := `SELECT ` + taskColumns + `
+			  FROM task
+			  WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	task, err := scanTask(s.DB.QueryRow(ctx, query, req.GetId(), owner))
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to Scan(): %w", op, err)
+		return nil, taskError(op, err)
 	}
-	task.CreatedAt = timestamppb.New(createdAt)
-	task.UpdatedAt = timestamppb.New(updatedAt)
-	return &task, nil
+	return task, nil
 }
 
-type UpdateQueryParams struct {
-	Title   string
-	Content string
-	IsDone  bool
+// UpdateTask changes only the fields set in req, so a request without title
+// keeps the stored title instead of overwriting it.
+func (s *Server) UpdateTask(ctx context.Context, req *dbpb.UpdateTaskRequest) (*dbpb.Task, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return updateTask(ctx, s.DB, uid, req)
 }
 
-func (s *Server) UpdateTask(ctx context.Context, req *dbpb.UpdateTaskRequest) (*dbpb.Task, error) {
+func updateTask(ctx context.Context, db dbtx, uid int64, req *dbpb.UpdateTaskRequest) (*dbpb.Task, error) {
 	const op = "db/internal/handlers|UpdateTask()"
-	task := dbpb.Task{}
-	var createdAt, updatedAt time.Time
-	var updateQueryParams UpdateQueryParams
-	if req.Title == nil {
-		updateQueryParams.Title = "title"
-	} else {
-		updateQueryParams.Title = req.GetTitle()
-
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
 	}
-	if req.Content == nil {
-		updateQueryParams.Content = "content"
-	} else {
-		updateQueryParams.Content = req.GetContent()
+	defer tx.Rollback(ctx)
 
+	// Collaborators change the task as its owner; ctx keeps them as the actor.
+	uid, err = taskOwner(ctx, tx, op, uid, req.GetId(), roleEditor)
+	if err != nil {
+		return nil, err
 	}
-
-	query := `update task 
-			  set title = $2, content = $3, is_done = $4
-			  where id = $1;`
-	_, err := s.DB.Exec(ctx, query, req.GetId(), updateQueryParams.Title, updateQueryParams.Content, req.GetIsDone())
+	before, err := lockTask(ctx, tx, op, uid, req.GetId())
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to execute query: %w", op, err)
+		return nil, err
 	}
-	query = `select * 
-			 from task 
-			 where id = $1`
-	err = s.DB.QueryRow(ctx, query, req.GetId()).Scan(
-		&task.Id,
-		&task.Title,
-		&task.Content,
-		&task.IsDone,
-		&createdAt,
-		&updatedAt,
-	)
+	task, err := applyUpdate(ctx, tx, uid, req, before, historyUpdated)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to execute query: %w", op, err)
+		return nil, err
 	}
-	task.CreatedAt = timestamppb.New(createdAt)
-	task.UpdatedAt = timestamppb.New(updatedAt)
-	return &task, nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return task, nil
+}
 
+// applyUpdate writes req over before, which the caller has locked, and records
+// the change in the task history as action.
+func applyUpdate(ctx context.Context, db dbtx, uid int64, req *dbpb.UpdateTaskRequest, before *dbpb.Task, action string) (*dbpb.Task, error) {
+	const op = "db/internal/handlers|UpdateTask()"
+	if req.Title != nil && req.GetTitle() == "" {
+		return nil, status.Error(codes.InvalidArgument, "title must not be empty")
+	}
+	if err := checkDescription(req.GetDescription()); err != nil {
+		return nil, err
+	}
+	if err := checkProject(ctx, db, op, uid, req.GetProjectId()); err != nil {
+		return nil, err
+	}
+	if req.GetRrule() != "" {
+		if err := checkRRule(req.GetRrule()); err != nil {
+			return nil, err
+		}
+	}
+
+	// A new rule restarts the series at the task's (new) due date; the
+	// task_rrule_due_at_check constraint rejects rules without one.
+	query := `UPDATE task
+			  SET title = COALESCE($3, title),
+			      content = COALESCE($4, content),
+			      is_done = COALESCE($5, is_done),
+			      due_at = CASE WHEN $8 THEN NULL ELSE COALESCE($7, due_at) END,
+			      priority = COALESCE($9, priority),
+			      tags = COALESCE($10, tags),
+			      description = COALESCE($11, description),
+			      project_id = CASE WHEN $12::bigint IS NULL THEN project_id ELSE NULLIF($12, 0) END,
+			      rrule = COALESCE($13, rrule),
+			      recur_start = CASE
+			          WHEN $13::text IS NULL THEN recur_start
+			          WHEN $13 = '' THEN NULL
+			          WHEN $8 THEN NULL
+			          ELSE COALESCE($7, due_at)
+			      END,
+			      updated_at = NOW(),
+			      version = version + 1
+			  WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+			    AND ($6::bigint IS NULL OR version = $6)
+			  RETURNING ` + taskColumns
+	var tags []string
+	if req.Tags != nil {
+		tags = append([]string{}, req.GetTags().GetValues()...)
+	}
+	task, err := scanTask(db.QueryRow(ctx, query,
+		req.GetId(), uid, req.Title, req.Content, req.IsDone, req.ExpectedVersion,
+		optionalTime(req.GetDueAt()), req.GetClearDueAt(), req.Priority, tags, req.Description, req.ProjectId, req.Rrule))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, writeMissError(ctx, db, op, req.GetId(), uid, req.ExpectedVersion)
+	}
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	if err := recordHistory(ctx, db, op, uid, action, before, task, diffTasks(before, task)); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
+
+// MarkAsDone completes a task. Open subtasks either block it or, with
+// req.Cascade, are completed in the same transaction at any depth. Completing
+// a recurring task creates its next occurrence.
 func (s *Server) MarkAsDone(ctx context.Context, req *dbpb.MarkAsDoneRequest) (*dbpb.Task, error) {
-	const op = "db/internal/handlers|MarkAsDone()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return markAsDone(ctx, s.DB, uid, req)
+}
 
-	task := dbpb.Task{}
-	var createdAt, updatedAt time.Time
+func markAsDone(ctx context.Context, db dbtx, uid int64, req *dbpb.MarkAsDoneRequest) (*dbpb.Task, error) {
+	const op = "db/internal/handlers|MarkAsDone()"
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
 
-	query := `update task 
-			  set is_done = True
-			  where id = $1;`
-	_, err := s.DB.Exec(ctx, query, req.GetId())
+	uid, err = taskOwner(ctx, tx, op, uid, req.GetId(), roleEditor)
+	if err != nil {
+		return nil, err
+	}
+	var prev recurrence
+	query := `SELECT ` + taskColumns + `, recur_start, recur_next_id IS NOT NULL
+			  FROM task
+			  WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+			  FOR UPDATE`
+	before, err := scanTask(tx.QueryRow(ctx, query, req.GetId(), uid), &prev.start, &prev.spawned)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to execute query: %w", op, err)
+		return nil, taskError(op, err)
 	}
+	prev.done = before.GetIsDone()
+	prev.rule = before.GetRrule()
+	prev.due = optionalTime(before.GetDueAt())
 
-	query = `select * 
-			from task 
-			where id = $1`
-	err = s.DB.QueryRow(ctx, query, req.GetId()).Scan(
-		&task.Id,
-		&task.Title,
-		&task.Content,
-		&task.IsDone,
-		&createdAt,
-		&updatedAt,
-	)
+	if req.GetCascade() {
+		query = `WITH RECURSIVE subtree AS (
+				      SELECT id FROM task WHERE parent_id = $1 AND user_id = $2 AND deleted_at IS NULL
+				      UNION ALL
+				      SELECT t.id FROM task t JOIN subtree ON t.parent_id = subtree.id WHERE t.deleted_at IS NULL
+				  )
+				  UPDATE task
+				  SET is_done = True, updated_at = NOW(), version = version + 1
+				  WHERE id IN (SELECT id FROM subtree) AND NOT is_done
+				  RETURNING ` + taskColumns
+		rows, err := tx.Query(ctx, query, req.GetId(), uid)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		subtasks, err := collectTasks(rows)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		err = recordEach(ctx, tx, op, uid, historyDone, subtasks, func(*dbpb.Task) map[string]fieldChange {
+			return map[string]fieldChange{"is_done": {Before: false, After: true}}
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var open int
+		query = `SELECT count(*) FROM task WHERE parent_id = $1 AND user_id = $2 AND NOT is_done AND deleted_at IS NULL`
+		if err := tx.QueryRow(ctx, query, req.GetId(), uid).Scan(&open); err != nil {
+			return nil, storageError(op, err)
+		}
+		if open > 0 {
+			return nil, status.Errorf(codes.Aborted, "task has %d open subtasks", open)
+		}
+	}
+
+	query = `UPDATE task
+			  SET is_done = True, updated_at = NOW(), version = version + 1
+			  WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+			    AND ($3::bigint IS NULL OR version = $3)
+			  RETURNING ` + taskColumns
+	task, err := scanTask(tx.QueryRow(ctx, query, req.GetId(), uid, req.ExpectedVersion))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, writeMissError(ctx, tx, op, req.GetId(), uid, req.ExpectedVersion)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to execute query: %w", op, err)
+		return nil, storageError(op, err)
 	}
-	task.CreatedAt = timestamppb.New(createdAt)
-	task.UpdatedAt = timestamppb.New(updatedAt)
-	return &task, nil
+	if err := recordHistory(ctx, tx, op, uid, historyDone, before, task, diffTasks(before, task)); err != nil {
+		return nil, err
+	}
+	if !prev.done {
+		if err := spawnNext(ctx, tx, op, task.GetId(), uid, prev); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return task, nil
 }
+
+// DeleteTask moves a task and its subtasks to the trash, stamping them with the
+// same deleted_at so RestoreTask can bring back exactly that group. A
+// permanent delete removes the row, trashed or not.
 func (s *Server) DeleteTask(ctx context.Context, req *dbpb.DeleteTaskRequest) (*emptypb.Empty, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := deleteTask(ctx, s.DB, uid, req); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func deleteTask(ctx context.Context, db dbtx, uid int64, req *dbpb.DeleteTaskRequest) error {
 	const op = "db/internal/handlers|DeleteTask()"
-	query := `delete from task
-			  where id = $1`
-	_, err := s.DB.Exec(ctx, query, req.GetId())
+	tx, err := db.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to execute query: %w", op, err)
+		return storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := taskOwner(ctx, tx, op, uid, req.GetId(), roleOwner); err != nil {
+		return err
+	}
+	if req.GetPermanent() {
+		// Subtasks go with their parent (ON DELETE CASCADE); deleting them in
+		// the same statement returns them for their events.
+		query := `WITH RECURSIVE subtree AS (
+				      SELECT id FROM task
+				      WHERE id = $1 AND user_id = $2
+				        AND ($3::bigint IS NULL OR version = $3)
+				      UNION ALL
+				      SELECT t.id FROM task t JOIN subtree ON t.parent_id = subtree.id
+				  )
+				  DELETE FROM task
+				  WHERE id IN (SELECT id FROM subtree)
+				  RETURNING ` + taskColumns
+		rows, err := tx.Query(ctx, query, req.GetId(), uid, req.ExpectedVersion)
+		if err != nil {
+			return storageError(op, err)
+		}
+		deleted, err := collectTasks(rows)
+		if err != nil {
+			return storageError(op, err)
+		}
+		if len(deleted) == 0 {
+			return writeMissError(ctx, tx, op, req.GetId(), uid, req.ExpectedVersion)
+		}
+		for _, task := range deleted {
+			if err := enqueue(ctx, tx, op, newEvent(ctx, uid, outbox.TaskDeleted).WithTask(task, nil)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return storageError(op, err)
+		}
+		return nil
 	}
-	return nil, nil
 
+	query := `WITH RECURSIVE subtree AS (
+			      SELECT id FROM task
+			      WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+			        AND ($3::bigint IS NULL OR version = $3)
+			      UNION ALL
+			      SELECT t.id FROM task t JOIN subtree ON t.parent_id = subtree.id WHERE t.deleted_at IS NULL
+			  )
+			  UPDATE task
+			  SET deleted_at = NOW(), updated_at = NOW(), version = version + 1
+			  WHERE id IN (SELECT id FROM subtree)
+			  RETURNING ` + taskColumns
+	rows, err := tx.Query(ctx, query, req.GetId(), uid, req.ExpectedVersion)
+	if err != nil {
+		return storageError(op, err)
+	}
+	trashed, err := collectTasks(rows)
+	if err != nil {
+		return storageError(op, err)
+	}
+	if len(trashed) == 0 {
+		return writeMissError(ctx, tx, op, req.GetId(), uid, req.ExpectedVersion)
+	}
+	err = recordEach(ctx, tx, op, uid, historyTrashed, trashed, func(task *dbpb.Task) map[string]fieldChange {
+		return trashChange(task.GetDeletedAt().AsTime(), true)
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return storageError(op, err)
+	}
+	return nil
 }
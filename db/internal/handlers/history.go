@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"db/internal/lib/userctx"
+	"encoding/json"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Actions recorded in task_history.
+const (
+	historyCreated  = "created"
+	historyUpdated  = "updated"
+	historyDone     = "done"
+	historyTrashed  = "trashed"
+	historyRestored = "restored"
+	historyReverted = "reverted"
+)
+
+const historyCursorSort = "history"
+
+const maxRequestID = 255
+
+// snapshotFields lists the task fields a history snapshot keeps, in the order
+// changes are reported.
+var snapshotFields = []string{"title", "content", "is_done", "due_at", "priority", "tags", "description", "project_id", "rrule"}
+
+// fieldChange is one entry of task_history.changes.
+type fieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// taskSnapshot is the typed form of task_history.snapshot, which RevertTask
+// turns back into an update.
+type taskSnapshot struct {
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	IsDone      bool       `json:"is_done"`
+	DueAt       *time.Time `json:"due_at"`
+	Priority    string     `json:"priority"`
+	Tags        []string   `json:"tags"`
+	Description string     `json:"description"`
+	ProjectID   *int64     `json:"project_id"`
+	Rrule       string     `json:"rrule"`
+}
+
+// taskState returns the snapshotFields of task as JSON-friendly values, with
+// nil for unset ones.
+func taskState(task *dbpb.Task) map[string]any {
+	var dueAt, projectID any
+	if task.GetDueAt() != nil {
+		dueAt = task.GetDueAt().AsTime().UTC().Format(time.RFC3339Nano)
+	}
+	if task.GetProjectId() != 0 {
+		projectID = task.GetProjectId()
+	}
+	tags := task.GetTags()
+	if tags == nil {
+		tags = []string{}
+	}
+	return map[string]any{
+		"title":       task.GetTitle(),
+		"content":     task.GetContent(),
+		"is_done":     task.GetIsDone(),
+		"due_at":      dueAt,
+		"priority":    task.GetPriority(),
+		"tags":        tags,
+		"description": task.GetDescription(),
+		"project_id":  projectID,
+		"rrule":       task.GetRrule(),
+	}
+}
+
+// diffTasks returns the snapshotFields that differ between before and after.
+// A nil before means the task was just created, so every field is reported.
+func diffTasks(before, after *dbpb.Task) map[string]fieldChange {
+	changes := map[string]fieldChange{}
+	newState := taskState(after)
+	if before == nil {
+		for _, field := range snapshotFields {
+			changes[field] = fieldChange{After: newState[field]}
+		}
+		return changes
+	}
+	oldState := taskState(before)
+	for _, field := range snapshotFields {
+		oldJSON, _ := json.Marshal(oldState[field])
+		newJSON, _ := json.Marshal(newState[field])
+		if !bytes.Equal(oldJSON, newJSON) {
+			changes[field] = fieldChange{Before: oldState[field], After: newState[field]}
+		}
+	}
+	return changes
+}
+
+// trashChange describes moving a task trashed at deletedAt into or out of the
+// trash.
+func trashChange(deletedAt time.Time, trashed bool) map[string]fieldChange {
+	stamp := deletedAt.UTC().Format(time.RFC3339Nano)
+	if trashed {
+		return map[string]fieldChange{"deleted_at": {After: stamp}}
+	}
+	return map[string]fieldChange{"deleted_at": {Before: stamp}}
+}
+
+// recordHistory appends a task_history row for task, which must already hold
+// the values after the change, and enqueues the matching event with before as
+// the earlier state, if known. It has to run in the transaction of the change.
+func recordHistory(ctx context.Context, db dbtx, op string, uid int64, action string, before, task *dbpb.Task, changes map[string]fieldChange) error {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return status.Errorf(codes.Internal, "%s: %v", op, err)
+	}
+	snapshotJSON, err := json.Marshal(taskState(task))
+	if err != nil {
+		return status.Errorf(codes.Internal, "%s: %v", op, err)
+	}
+	requestID := cutRequestID(userctx.RequestID(ctx))
+	query := `INSERT INTO task_history (task_id, user_id, request_id, action, version, changes, snapshot)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	if _, err := db.Exec(ctx, query, task.GetId(), actorID(ctx, uid), requestID, action, task.GetVersion(), changesJSON, snapshotJSON); err != nil {
+		return storageError(op, err)
+	}
+	return enqueue(ctx, db, op, newEvent(ctx, uid, historyEvents[action]).WithTask(before, task))
+}
+
+// cutRequestID shortens id to maxRequestID bytes on a rune boundary, since
+// Postgres refuses invalid UTF-8.
+func cutRequestID(id string) string {
+	if len(id) <= maxRequestID {
+		return id
+	}
+	cut := maxRequestID
+	for cut > 0 && !utf8.RuneStart(id[cut]) {
+		cut--
+	}
+	return id[:cut]
+}
+
+// recordEach records the same action for every task of a multi-row change.
+func recordEach(ctx context.Context, db dbtx, op string, uid int64, action string, tasks []*dbpb.Task, changes func(*dbpb.Task) map[string]fieldChange) error {
+	for _, task := range tasks {
+		if err := recordHistory(ctx, db, op, uid, action, nil, task, changes(task)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectTasks reads every row of a RETURNING taskColumns query.
+func collectTasks(rows pgx.Rows) ([]*dbpb.Task, error) {
+	defer rows.Close()
+	var tasks []*dbpb.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// lockTask reads a live task and locks it for the rest of the transaction, so
+// the history diff is taken against the row that is actually changed.
+func lockTask(ctx context.Context, db dbtx, op string, uid int64, id any) (*dbpb.Task, error) {
+	query := `SELECT ` + taskColumns + `
+			  FROM task
+			  WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+			  FOR UPDATE`
+	task, err := scanTask(db.QueryRow(ctx, query, id, uid))
+	if err != nil {
+		return nil, taskError(op, err)
+	}
+	return task, nil
+}
+
+// ListTaskHistory pages through the changes of a task, newest first. Trashed
+// tasks keep their history readable.
+func (s *Server) ListTaskHistory(ctx context.Context, req *dbpb.ListTaskHistoryRequest) (*dbpb.ListTaskHistoryResponse, error) {
+	const op = "db/internal/handlers|ListTaskHistory()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := taskOwner(ctx, s.DB, op, uid, req.GetTaskId(), roleViewer); err != nil {
+		return nil, err
+	}
+
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	var beforeID *int64
+	if req.GetAfter() != "" {
+		c, err := decodeCursor(req.GetAfter())
+		if err != nil || c.Sort != historyCursorSort {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		beforeID = &c.ID
+	}
+
+	// One extra row tells whether another page exists.
+	query := `SELECT id, task_id, user_id, action, version, request_id, changes, created_at
+			 FROM task_history
+			 WHERE task_id = $1 AND ($2::bigint IS NULL OR id < $2)
+			 ORDER BY id DESC
+			 LIMIT $3`
+	rows, err := s.DB.Query(ctx, query, req.GetTaskId(), beforeID, limit+1)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListTaskHistoryResponse{}
+	for rows.Next() {
+		entry := &dbpb.TaskHistoryEntry{}
+		var changesJSON []byte
+		var createdAt time.Time
+		err := rows.Scan(&entry.Id, &entry.TaskId, &entry.ActorId, &entry.Action, &entry.Version, &entry.RequestId, &changesJSON, &createdAt)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		entry.CreatedAt = timestamppb.New(createdAt)
+		if entry.Changes, err = fieldChanges(changesJSON); err != nil {
+			return nil, status.Errorf(codes.Internal, "%s: stored changes: %v", op, err)
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+
+	if len(resp.Entries) > limit {
+		resp.Entries = resp.Entries[:limit]
+		resp.NextCursor = encodeCursor(cursor{Sort: historyCursorSort, ID: resp.Entries[limit-1].GetId()})
+	}
+	return resp, nil
+}
+
+// fieldChanges turns task_history.changes into FieldChanges, in the order of
+// snapshotFields followed by any other field.
+func fieldChanges(data []byte) ([]*dbpb.FieldChange, error) {
+	var raw map[string]struct {
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var changes []*dbpb.FieldChange
+	add := func(field string) {
+		c, ok := raw[field]
+		if !ok {
+			return
+		}
+		change := &dbpb.FieldChange{Field: field, Before: string(c.Before), After: string(c.After)}
+		if change.Before == "" {
+			change.Before = "null"
+		}
+		if change.After == "" {
+			change.After = "null"
+		}
+		changes = append(changes, change)
+		delete(raw, field)
+	}
+	for _, field := range snapshotFields {
+		add(field)
+	}
+	add("deleted_at")
+	return changes, nil
+}
+
+// RevertTask brings the task back to the values it had at an earlier version.
+// The revert is itself recorded, so it can be reverted too.
+func (s *Server) RevertTask(ctx context.Context, req *dbpb.RevertTaskRequest) (*dbpb.Task, error) {
+	const op = "db/internal/handlers|RevertTask()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	uid, err = taskOwner(ctx, tx, op, uid, req.GetId(), roleEditor)
+	if err != nil {
+		return nil, err
+	}
+	current, err := lockTask(ctx, tx, op, uid, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	var snapshotJSON []byte
+	query := `SELECT snapshot FROM task_history WHERE task_id = $1 AND version = $2 ORDER BY id DESC LIMIT 1`
+	err = tx.QueryRow(ctx, query, req.GetId(), req.GetVersion()).Scan(&snapshotJSON)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "task has no recorded revision %d", req.GetVersion())
+	}
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	var snap taskSnapshot
+	if err := json.Unmarshal(snapshotJSON, &snap); err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: stored snapshot: %v", op, err)
+	}
+
+	update := &dbpb.UpdateTaskRequest{
+		Id:              req.GetId(),
+		Title:           &snap.Title,
+		Content:         &snap.Content,
+		IsDone:          &snap.IsDone,
+		ExpectedVersion: req.ExpectedVersion,
+		Priority:        &snap.Priority,
+		Tags:            &dbpb.TagList{Values: snap.Tags},
+		Description:     &snap.Description,
+		ProjectId:       new(int64),
+	}
+	if snap.DueAt != nil {
+		update.DueAt = timestamppb.New(*snap.DueAt)
+	} else {
+		update.ClearDueAt = true
+	}
+	if snap.ProjectID != nil {
+		update.ProjectId = snap.ProjectID
+	}
+	// Setting the rule restarts the series, so only do it when it changes.
+	if snap.Rrule != current.GetRrule() {
+		update.Rrule = &snap.Rrule
+	}
+
+	task, err := applyUpdate(ctx, tx, uid, update, current, historyReverted)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return task, nil
+}
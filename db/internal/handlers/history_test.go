@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCutRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{name: "Short", id: "req-1", want: "req-1"},
+		{name: "ASCII", id: strings.Repeat("a", maxRequestID+10), want: strings.Repeat("a", maxRequestID)},
+		// "я" is two bytes, so byte 255 is the middle of the 128th.
+		{name: "Multibyte", id: strings.Repeat("я", maxRequestID), want: strings.Repeat("я", maxRequestID/2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cutRequestID(tt.id)
+			if got != tt.want || !utf8.ValidString(got) {
+				t.Errorf("cutRequestID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxImportTasks = 500
+	maxExternalID  = 255
+	// title and content are VARCHAR(255).
+	maxTaskText = 255
+)
+
+var taskPriorities = []string{"low", "normal", "high", "urgent"}
+
+// importRow is an imported task that passed its checks.
+type importRow struct {
+	task   *dbpb.ImportTask
+	values newTaskValues
+}
+
+// ImportTasks inserts tasks from another tool in one statement. Tasks whose
+// external_id the user already has are skipped, so an import that failed
+// half-way, or a newer export of the same tool, can simply be imported again.
+// Invalid tasks are reported in their result; the others are still imported.
+func (s *Server) ImportTasks(ctx context.Context, req *dbpb.ImportTasksRequest) (*dbpb.ImportTasksResponse, error) {
+	const op = "db/internal/handlers|ImportTasks()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks := req.GetTasks()
+	if len(tasks) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "nothing to import")
+	}
+	if len(tasks) > maxImportTasks {
+		return nil, status.Errorf(codes.InvalidArgument, "import has %d tasks, at most %d are allowed", len(tasks), maxImportTasks)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	resp := &dbpb.ImportTasksResponse{Results: make([]*dbpb.ImportResult, len(tasks))}
+	// index maps the external ids of valid tasks to their position.
+	index := make(map[string]int, len(tasks))
+	rows := make([]importRow, 0, len(tasks))
+	for i, t := range tasks {
+		row, err := checkImportTask(ctx, tx, op, uid, t)
+		if err == nil {
+			if first, ok := index[t.GetExternalId()]; ok {
+				err = status.Errorf(codes.InvalidArgument, "external_id repeats task %d", first)
+			}
+		}
+		if err != nil {
+			st := status.Convert(err)
+			if st.Code() != codes.InvalidArgument && st.Code() != codes.NotFound {
+				return nil, err
+			}
+			resp.Results[i] = &dbpb.ImportResult{Code: int32(st.Code()), Error: st.Message()}
+			continue
+		}
+		index[t.GetExternalId()] = i
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return resp, nil
+	}
+
+	created, err := insertImported(ctx, tx, uid, rows)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	for _, task := range created {
+		res := &dbpb.ImportResult{Task: task}
+		if req.GetDryRun() {
+			// Its id is never committed.
+			res.Task = nil
+		} else if err := recordHistory(ctx, tx, op, uid, historyCreated, nil, task, diffTasks(nil, task)); err != nil {
+			return nil, err
+		}
+		resp.Results[index[task.GetExternalId()]] = res
+	}
+
+	var existing []string
+	for _, row := range rows {
+		if resp.Results[index[row.task.GetExternalId()]] == nil {
+			existing = append(existing, row.task.GetExternalId())
+		}
+	}
+	if len(existing) > 0 {
+		query := `SELECT ` + taskColumns + ` FROM task WHERE user_id = $1 AND external_id = ANY($2)`
+		found, err := tx.Query(ctx, query, uid, existing)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		skipped, err := collectTasks(found)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		for _, task := range skipped {
+			resp.Results[index[task.GetExternalId()]] = &dbpb.ImportResult{Task: task, Skipped: true}
+		}
+	}
+	for i, res := range resp.Results {
+		if res == nil {
+			// The conflicting task was deleted after the insert skipped it.
+			resp.Results[i] = &dbpb.ImportResult{Code: int32(codes.Aborted), Error: "task was changed concurrently, import it again"}
+		}
+	}
+
+	if req.GetDryRun() {
+		return resp, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
+
+// checkImportTask validates t up front, so that no row can make the insert of
+// all of them fail.
+func checkImportTask(ctx context.Context, db dbtx, op string, uid int64, t *dbpb.ImportTask) (importRow, error) {
+	req := t.GetTask()
+	switch {
+	case t.GetExternalId() == "" || utf8.RuneCountInString(t.GetExternalId()) > maxExternalID:
+		return importRow{}, status.Errorf(codes.InvalidArgument, "external_id must be 1 to %d characters", maxExternalID)
+	case req == nil:
+		return importRow{}, status.Error(codes.InvalidArgument, "task is required")
+	case req.GetParentId() != 0:
+		return importRow{}, status.Error(codes.InvalidArgument, "imported tasks cannot have a parent")
+	case utf8.RuneCountInString(req.GetTitle()) > maxTaskText:
+		return importRow{}, status.Errorf(codes.InvalidArgument, "title must be at most %d characters", maxTaskText)
+	case utf8.RuneCountInString(req.GetContent()) > maxTaskText:
+		return importRow{}, status.Errorf(codes.InvalidArgument, "content must be at most %d characters", maxTaskText)
+	case req.GetPriority() != "" && !slices.Contains(taskPriorities, req.GetPriority()):
+		return importRow{}, status.Error(codes.InvalidArgument, "priority must be low, normal, high or urgent")
+	}
+	values, err := checkNewTask(ctx, db, op, uid, req)
+	if err != nil {
+		return importRow{}, err
+	}
+	return importRow{task: t, values: values}, nil
+}
+
+// insertImported inserts rows with a single multi-row INSERT and returns the
+// tasks it created; rows whose external_id exists are left out.
+func insertImported(ctx context.Context, tx pgx.Tx, uid int64, rows []importRow) ([]*dbpb.Task, error) {
+	const columns = 12
+	args := make([]any, 0, len(rows)*columns)
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		req := row.task.GetTask()
+		params := make([]string, columns)
+		for i := range params {
+			params[i] = "$" + strconv.Itoa(len(args)+i+1)
+		}
+		// project_id
+		params[7] = "NULLIF(" + params[7] + ", 0)"
+		values = append(values, "("+strings.Join(params, ", ")+")")
+		args = append(args,
+			req.GetTitle(), req.GetContent(), uid, optionalTime(req.GetDueAt()), row.values.priority, row.values.tags, req.GetDescription(),
+			row.values.projectID, req.GetRrule(), row.values.recurStart, row.task.GetIsDone(), row.task.GetExternalId())
+	}
+
+	query := `INSERT INTO task (title, content, user_id, due_at, priority, tags, description, project_id, rrule, recur_start, is_done, external_id)
+			  VALUES ` + strings.Join(values, ",\n") + `
+			  ON CONFLICT (user_id, external_id) WHERE external_id IS NOT NULL DO NOTHING
+			  RETURNING ` + taskColumns
+	result, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectTasks(result)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// sortColumns whitelists the columns ListTasks may order by.
+var sortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+}
+
+// cursor points right after the last task of a page. Value holds the sort
+// column of that task, so the next page continues with keyset pagination.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+func cursorValue(task *dbpb.Task, column string) string {
+	switch column {
+	case "title":
+		return task.GetTitle()
+	case "updated_at":
+		return task.GetUpdatedAt().AsTime().Format(time.RFC3339Nano)
+	default:
+		return task.GetCreatedAt().AsTime().Format(time.RFC3339Nano)
+	}
+}
+
+func (s *Server) ListTasks(ctx context.Context, req *dbpb.ListTasksRequest) (*dbpb.ListTasksResponse, error) {
+	const op = "db/internal/handlers|ListTasks()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := req.GetSort()
+	if sort == "" {
+		sort = "created_at"
+	}
+	desc := strings.HasPrefix(sort, "-")
+	column, ok := sortColumns[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported sort %q", sort)
+	}
+
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	// Subtasks and project tasks are listed as whoever owns the parent or
+	// project, so collaborators see them too.
+	owner := uid
+	switch {
+	case req.GetParentId() != 0:
+		owner, err = taskOwner(ctx, s.DB, op, uid, req.GetParentId(), roleViewer)
+	case req.GetProjectId() != 0:
+		owner, err = projectOwner(ctx, s.DB, op, uid, req.GetProjectId(), roleViewer)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	args := []any{owner}
+	where := []string{"user_id = $1", "deleted_at IS NULL"}
+	if req.GetTrashed() {
+		if owner != uid {
+			return nil, status.Error(codes.PermissionDenied, "only the owner sees the trash")
+		}
+		where[1] = "deleted_at IS NOT NULL"
+	}
+	switch req.GetScope() {
+	case "":
+	case scopeShared:
+		if req.GetTrashed() {
+			return nil, status.Error(codes.InvalidArgument, "the trash is not shared")
+		}
+		if req.GetParentId() == 0 && req.GetProjectId() == 0 {
+			where[0] = `user_id <> $1
+				AND (id IN (SELECT task_id FROM task_share WHERE user_id = $1)
+				     OR project_id IN (SELECT project_id FROM task_share WHERE user_id = $1))`
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported scope %q", req.GetScope())
+	}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if req.IsDone != nil {
+		where = append(where, "is_done = "+arg(req.GetIsDone()))
+	}
+	if req.GetCreatedAfter() != nil {
+		where = append(where, "created_at > "+arg(req.GetCreatedAfter().AsTime()))
+	}
+	if req.GetCreatedBefore() != nil {
+		where = append(where, "created_at < "+arg(req.GetCreatedBefore().AsTime()))
+	}
+	if len(req.GetTags()) > 0 {
+		where = append(where, "tags @> "+arg(req.GetTags()))
+	}
+	if req.GetPriority() != "" {
+		where = append(where, "priority = "+arg(req.GetPriority()))
+	}
+	if req.GetDueAfter() != nil {
+		where = append(where, "due_at > "+arg(req.GetDueAfter().AsTime()))
+	}
+	if req.GetDueBefore() != nil {
+		where = append(where, "due_at < "+arg(req.GetDueBefore().AsTime()))
+	}
+	if req.GetProjectId() != 0 {
+		where = append(where, "project_id = "+arg(req.GetProjectId()))
+	}
+	if req.GetParentId() != 0 {
+		where = append(where, "parent_id = "+arg(req.GetParentId()))
+	}
+
+	if req.GetAfter() != "" {
+		c, err := decodeCursor(req.GetAfter())
+		if err != nil || c.Sort != sort {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		var value any = c.Value
+		if column != "title" {
+			value, err = time.Parse(time.RFC3339Nano, c.Value)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+			}
+		}
+		cmp := ">"
+		if desc {
+			cmp = "<"
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s, %s)", column, cmp, arg(value), arg(c.ID)))
+	}
+
+	order := column + ", id"
+	if desc {
+		order = column + " DESC, id DESC"
+	}
+	// One extra row tells whether another page exists.
+	query := `SELECT ` + taskColumns + `
+			  FROM task
+			  WHERE ` + strings.Join(where, " AND ") + `
+			  ORDER BY ` + order + `
+			  LIMIT ` + arg(limit+1)
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListTasksResponse{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		resp.Tasks = append(resp.Tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+
+	if len(resp.Tasks) > limit {
+		resp.Tasks = resp.Tasks[:limit]
+		last := resp.Tasks[limit-1]
+		resp.NextCursor = encodeCursor(cursor{Sort: sort, Value: cursorValue(last, column), ID: last.GetId()})
+	}
+	return resp, nil
+}
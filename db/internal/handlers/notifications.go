@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxNotificationKind  = 32
+	maxNotificationTitle = 255
+	maxNotificationBody  = 4000
+	maxNotificationIDs   = 100
+	maxEmailLength       = 255
+)
+
+const notificationCursorSort = "notification"
+
+const notificationColumns = "id, kind, title, body, COALESCE(task_id, 0), created_at, read_at"
+
+func scanNotification(row pgx.Row) (*dbpb.Notification, error) {
+	n := dbpb.Notification{}
+	var createdAt time.Time
+	var readAt *time.Time
+	if err := row.Scan(&n.Id, &n.Kind, &n.Title, &n.Body, &n.TaskId, &createdAt, &readAt); err != nil {
+		return nil, err
+	}
+	n.CreatedAt = timestamppb.New(createdAt)
+	if readAt != nil {
+		n.ReadAt = timestamppb.New(*readAt)
+	}
+	return &n, nil
+}
+
+func (s *Server) CreateNotification(ctx context.Context, req *dbpb.CreateNotificationRequest) (*dbpb.Notification, error) {
+	const op = "db/internal/handlers|CreateNotification()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case req.GetKind() == "" || len(req.GetKind()) > maxNotificationKind:
+		return nil, status.Errorf(codes.InvalidArgument, "kind must be 1 to %d characters", maxNotificationKind)
+	case strings.TrimSpace(req.GetTitle()) == "" || utf8.RuneCountInString(req.GetTitle()) > maxNotificationTitle:
+		return nil, status.Errorf(codes.InvalidArgument, "title must be 1 to %d characters", maxNotificationTitle)
+	case utf8.RuneCountInString(req.GetBody()) > maxNotificationBody:
+		return nil, status.Errorf(codes.InvalidArgument, "body must be at most %d characters", maxNotificationBody)
+	}
+	var taskID *int64
+	if req.GetTaskId() != 0 {
+		if _, err := checkTask(ctx, s.DB, op, uid, req.GetTaskId(), roleViewer); err != nil {
+			return nil, err
+		}
+		taskID = &req.TaskId
+	}
+	var dedupKey *string
+	if req.GetDedupKey() != "" {
+		dedupKey = &req.DedupKey
+	}
+
+	query := `INSERT INTO notification (user_id, kind, title, body, task_id, dedup_key)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  ON CONFLICT (user_id, dedup_key) DO NOTHING
+			  RETURNING ` + notificationColumns
+	n, err := scanNotification(s.DB.QueryRow(ctx, query, uid, req.GetKind(), req.GetTitle(), req.GetBody(), taskID, dedupKey))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Created before under the same key.
+		query = `SELECT ` + notificationColumns + ` FROM notification WHERE user_id = $1 AND dedup_key = $2`
+		n, err = scanNotification(s.DB.QueryRow(ctx, query, uid, dedupKey))
+	}
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	return n, nil
+}
+
+// ListNotifications returns the caller's inbox, newest first, and how many
+// notifications are unread.
+func (s *Server) ListNotifications(ctx context.Context, req *dbpb.ListNotificationsRequest) (*dbpb.ListNotificationsResponse, error) {
+	const op = "db/internal/handlers|ListNotifications()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	var beforeID int64
+	if req.GetAfter() != "" {
+		c, err := decodeCursor(req.GetAfter())
+		if err != nil || c.Sort != notificationCursorSort {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		beforeID = c.ID
+	}
+
+	// One extra row tells whether another page exists.
+	query := `SELECT ` + notificationColumns + `
+			  FROM notification
+			  WHERE user_id = $1 AND ($2 = 0 OR id < $2) AND (NOT $3 OR read_at IS NULL)
+			  ORDER BY id DESC
+			  LIMIT $4`
+	rows, err := s.DB.Query(ctx, query, uid, beforeID, req.GetUnreadOnly(), limit+1)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListNotificationsResponse{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		resp.Notifications = append(resp.Notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+	if len(resp.Notifications) > limit {
+		resp.Notifications = resp.Notifications[:limit]
+		resp.NextCursor = encodeCursor(cursor{Sort: notificationCursorSort, ID: resp.Notifications[limit-1].GetId()})
+	}
+
+	query = `SELECT count(*) FROM notification WHERE user_id = $1 AND read_at IS NULL`
+	if err := s.DB.QueryRow(ctx, query, uid).Scan(&resp.UnreadCount); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
+
+// MarkNotificationsRead marks the given notifications of the caller, or all
+// of them, as read. Ids of other users' or unknown notifications are ignored.
+func (s *Server) MarkNotificationsRead(ctx context.Context, req *dbpb.MarkNotificationsReadRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|MarkNotificationsRead()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case req.GetAll():
+		query := `UPDATE notification SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+		_, err = s.DB.Exec(ctx, query, uid)
+	case len(req.GetIds()) == 0:
+		return nil, status.Error(codes.InvalidArgument, "ids or all is required")
+	case len(req.GetIds()) > maxNotificationIDs:
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ids", maxNotificationIDs)
+	default:
+		query := `UPDATE notification SET read_at = NOW() WHERE user_id = $1 AND id = ANY($2) AND read_at IS NULL`
+		_, err = s.DB.Exec(ctx, query, uid, req.GetIds())
+	}
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetNotificationSettings returns the caller's settings, empty ones if they
+// never saved any.
+func (s *Server) GetNotificationSettings(ctx context.Context, _ *emptypb.Empty) (*dbpb.NotificationSettings, error) {
+	const op = "db/internal/handlers|GetNotificationSettings()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings := &dbpb.NotificationSettings{}
+	query := `SELECT email FROM notification_settings WHERE user_id = $1`
+	err = s.DB.QueryRow(ctx, query, uid).Scan(&settings.Email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, storageError(op, err)
+	}
+	return settings, nil
+}
+
+func (s *Server) UpdateNotificationSettings(ctx context.Context, req *dbpb.NotificationSettings) (*dbpb.NotificationSettings, error) {
+	const op = "db/internal/handlers|UpdateNotificationSettings()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if email := req.GetEmail(); email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email || len(email) > maxEmailLength {
+			return nil, status.Error(codes.InvalidArgument, "email must be a plain email address")
+		}
+	}
+
+	settings := &dbpb.NotificationSettings{}
+	query := `INSERT INTO notification_settings (user_id, email)
+			  VALUES ($1, $2)
+			  ON CONFLICT (user_id) DO UPDATE SET email = EXCLUDED.email, updated_at = NOW()
+			  RETURNING email`
+	if err := s.DB.QueryRow(ctx, query, uid, req.GetEmail()).Scan(&settings.Email); err != nil {
+		return nil, storageError(op, err)
+	}
+	return settings, nil
+}
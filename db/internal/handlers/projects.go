@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/outbox"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const projectColumns = "id, name, description, archived_at IS NOT NULL, created_at, updated_at"
+
+const (
+	deleteModeArchive = "archive"
+	deleteModeCascade = "cascade"
+)
+
+var errProjectNotFound = status.Error(codes.NotFound, "project not found")
+
+func scanProject(row pgx.Row) (*dbpb.Project, error) {
+	project := dbpb.Project{}
+	var createdAt, updatedAt time.Time
+	err := row.Scan(
+		&project.Id,
+		&project.Name,
+		&project.Description,
+		&project.Archived,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	project.CreatedAt = timestamppb.New(createdAt)
+	project.UpdatedAt = timestamppb.New(updatedAt)
+	return &project, nil
+}
+
+// projectError is storageError for project queries, where a missing row means
+// a missing project rather than a missing task.
+func projectError(op string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errProjectNotFound
+	}
+	return storageError(op, err)
+}
+
+// checkProject makes sure tasks are only put into active projects of their owner.
+// Zero means "no project" and always passes.
+func checkProject(ctx context.Context, db dbtx, op string, uid, projectID int64) error {
+	if projectID == 0 {
+		return nil
+	}
+	var archived bool
+	query := `SELECT archived_at IS NOT NULL FROM project WHERE id = $1 AND user_id = $2`
+	if err := db.QueryRow(ctx, query, projectID, uid).Scan(&archived); err != nil {
+		return projectError(op, err)
+	}
+	if archived {
+		return status.Error(codes.InvalidArgument, "project is archived")
+	}
+	return nil
+}
+
+func (s *Server) CreateProject(ctx context.Context, req *dbpb.CreateProjectRequest) (*dbpb.Project, error) {
+	const op = "db/internal/handlers|CreateProject()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, projectError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO project (user_id, name, description)
+			  VALUES ($1, $2, $3)
+			  RETURNING ` + projectColumns
+	project, err := scanProject(tx.QueryRow(ctx, query, uid, req.GetName(), req.GetDescription()))
+	if err != nil {
+		return nil, projectError(op, err)
+	}
+	if err := enqueue(ctx, tx, op, projectEvent(ctx, uid, outbox.ProjectCreated, project.GetId())); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, projectError(op, err)
+	}
+	return project, nil
+}
+
+func (s *Server) GetProject(ctx context.Context, req *dbpb.GetProjectRequest) (*dbpb.Project, error) {
+	const op = "db/internal/handlers|GetProject()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := projectOwner(ctx, s.DB, op, uid, req.GetId(), roleViewer)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + projectColumns + `
+			  FROM project
+			  WHERE id = $1 AND user_id = $2`
+	project, err := scanProject(s.DB.QueryRow(ctx, query, req.GetId(), owner))
+	if err != nil {
+		return nil, projectError(op, err)
+	}
+	return project, nil
+}
+
+func (s *Server) ListProjects(ctx context.Context, req *dbpb.ListProjectsRequest) (*dbpb.ListProjectsResponse, error) {
+	const op = "db/internal/handlers|ListProjects()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + projectColumns + `
+			  FROM project
+			  WHERE user_id = $1 AND ($2 OR archived_at IS NULL)
+			  ORDER BY name, id`
+	rows, err := s.DB.Query(ctx, query, uid, req.GetIncludeArchived())
+	if err != nil {
+		return nil, projectError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListProjectsResponse{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, projectError(op, err)
+		}
+		resp.Projects = append(resp.Projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, projectError(op, err)
+	}
+	return resp, nil
+}
+
+func (s *Server) UpdateProject(ctx context.Context, req *dbpb.UpdateProjectRequest) (*dbpb.Project, error) {
+	const op = "db/internal/handlers|UpdateProject()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil && req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name must not be empty")
+	}
+	query := `UPDATE project
+			  SET name = COALESCE($3, name),
+			      description = COALESCE($4, description),
+			      archived_at = CASE
+			          WHEN $5::boolean IS NULL THEN archived_at
+			          WHEN $5 THEN COALESCE(archived_at, NOW())
+			          ELSE NULL
+			      END,
+			      updated_at = NOW()
+			  WHERE id = $1 AND user_id = $2
+			  RETURNING ` + projectColumns
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, projectError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	project, err := scanProject(tx.QueryRow(ctx, query, req.GetId(), uid, req.Name, req.Description, req.Archived))
+	if err != nil {
+		return nil, projectError(op, err)
+	}
+	if err := enqueue(ctx, tx, op, projectEvent(ctx, uid, outbox.ProjectUpdated, project.GetId())); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, projectError(op, err)
+	}
+	return project, nil
+}
+
+// DeleteProject archives the project by default. The cascade mode removes the
+// project and its tasks in one transaction.
+func (s *Server) DeleteProject(ctx context.Context, req *dbpb.DeleteProjectRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|DeleteProject()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := req.GetMode()
+	if mode == "" {
+		mode = deleteModeArchive
+	}
+	if mode != deleteModeArchive && mode != deleteModeCascade {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown delete mode %q", req.GetMode())
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, projectError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	eventType := outbox.ProjectArchived
+	if mode == deleteModeArchive {
+		query := `UPDATE project
+				  SET archived_at = COALESCE(archived_at, NOW()), updated_at = NOW()
+				  WHERE id = $1 AND user_id = $2`
+		tag, err := tx.Exec(ctx, query, req.GetId(), uid)
+		if err != nil {
+			return nil, projectError(op, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, errProjectNotFound
+		}
+	} else {
+		eventType = outbox.ProjectDeleted
+		// Subtasks go with their parents even from other projects, so they
+		// are deleted here too and announced like the rest.
+		query := `WITH RECURSIVE subtree AS (
+				      SELECT id FROM task WHERE project_id = $1 AND user_id = $2
+				      UNION
+				      SELECT t.id FROM task t JOIN subtree ON t.parent_id = subtree.id
+				  )
+				  DELETE FROM task
+				  WHERE id IN (SELECT id FROM subtree)
+				  RETURNING ` + taskColumns
+		rows, err := tx.Query(ctx, query, req.GetId(), uid)
+		if err != nil {
+			return nil, projectError(op, err)
+		}
+		deleted, err := collectTasks(rows)
+		if err != nil {
+			return nil, projectError(op, err)
+		}
+		query = `DELETE FROM project WHERE id = $1 AND user_id = $2`
+		tag, err := tx.Exec(ctx, query, req.GetId(), uid)
+		if err != nil {
+			return nil, projectError(op, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, errProjectNotFound
+		}
+		for _, task := range deleted {
+			if err := enqueue(ctx, tx, op, newEvent(ctx, uid, outbox.TaskDeleted).WithTask(task, nil)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := enqueue(ctx, tx, op, projectEvent(ctx, uid, eventType, req.GetId())); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, projectError(op, err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/lib/rrule"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultOccurrences = 50
+	maxOccurrences     = 100
+	// occurrenceWindow is how far ahead ListOccurrences looks without "to".
+	occurrenceWindow = 365 * 24 * time.Hour
+)
+
+// recurrence is the state MarkAsDone needs to schedule the next occurrence.
+type recurrence struct {
+	done  bool
+	rule  string
+	start *time.Time
+	due   *time.Time
+	// spawned is set once the task has spawned its next occurrence.
+	spawned bool
+}
+
+func checkRRule(s string) error {
+	if _, err := rrule.Parse(s); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// spawnNext copies a completed recurring task into its next occurrence and
+// records it on the task, so completing the task again spawns nothing. Series
+// that have run out of occurrences end quietly.
+func spawnNext(ctx context.Context, tx pgx.Tx, op string, id, uid int64, prev recurrence) error {
+	if prev.rule == "" || prev.start == nil || prev.due == nil || prev.spawned {
+		return nil
+	}
+	rule, err := rrule.Parse(prev.rule)
+	if err != nil {
+		return status.Errorf(codes.Internal, "%s: stored rrule: %v", op, err)
+	}
+	next, ok := rule.Next(*prev.start, *prev.due)
+	if !ok {
+		return nil
+	}
+	query := `INSERT INTO task (title, content, user_id, due_at, priority, tags, description, project_id, parent_id, rrule, recur_start)
+			  SELECT title, content, user_id, $3, priority, tags, description, project_id, parent_id, rrule, recur_start
+			  FROM task
+			  WHERE id = $1 AND user_id = $2
+			  RETURNING ` + taskColumns
+	task, err := scanTask(tx.QueryRow(ctx, query, id, uid, next))
+	if err != nil {
+		return taskError(op, err)
+	}
+	query = `UPDATE task SET recur_next_id = $3 WHERE id = $1 AND user_id = $2`
+	if _, err := tx.Exec(ctx, query, id, uid, task.GetId()); err != nil {
+		return storageError(op, err)
+	}
+	return recordHistory(ctx, tx, op, uid, historyCreated, nil, task, diffTasks(nil, task))
+}
+
+// ListOccurrences expands the upcoming occurrences of a recurring task without
+// storing them.
+func (s *Server) ListOccurrences(ctx context.Context, req *dbpb.ListOccurrencesRequest) (*dbpb.ListOccurrencesResponse, error) {
+	const op = "db/internal/handlers|ListOccurrences()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := taskOwner(ctx, s.DB, op, uid, req.GetTaskId(), roleViewer)
+	if err != nil {
+		return nil, err
+	}
+	var rule string
+	var start *time.Time
+	query := `SELECT rrule, recur_start FROM task WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	if err := s.DB.QueryRow(ctx, query, req.GetTaskId(), owner).Scan(&rule, &start); err != nil {
+		return nil, taskError(op, err)
+	}
+	if rule == "" || start == nil {
+		return nil, status.Error(codes.InvalidArgument, "task is not recurring")
+	}
+	parsed, err := rrule.Parse(rule)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: stored rrule: %v", op, err)
+	}
+
+	from := time.Now().UTC()
+	if req.GetFrom() != nil {
+		from = req.GetFrom().AsTime()
+	}
+	to := from.Add(occurrenceWindow)
+	if req.GetTo() != nil {
+		to = req.GetTo().AsTime()
+	}
+	if to.Before(from) {
+		return nil, status.Error(codes.InvalidArgument, "to is before from")
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultOccurrences
+	}
+	if limit > maxOccurrences {
+		limit = maxOccurrences
+	}
+
+	resp := &dbpb.ListOccurrencesResponse{}
+	for _, t := range parsed.Between(*start, from, to, limit) {
+		resp.Occurrences = append(resp.Occurrences, timestamppb.New(t))
+	}
+	return resp, nil
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxRemindersPerTask = 10
+	// maxReminderOffset is a year, in minutes.
+	maxReminderOffset   = 525600
+	maxReminderClaim    = 100
+	defaultReminderLate = 3600
+)
+
+// reminderChannels are the channels todo-app delivers reminders through.
+var reminderChannels = []string{"email", "webhook", "in_app"}
+
+// reminderColumns select from task_reminder r joined with its task t in the
+// order scanReminder expects. due_at is UTC without a time zone.
+const reminderColumns = "r.id, r.task_id, r.offset_minutes, r.channels, " +
+	"(t.due_at - r.offset_minutes * interval '1 minute') AT TIME ZONE 'UTC', r.created_at"
+
+var errReminderNotFound = status.Error(codes.NotFound, "reminder not found")
+
+func scanReminder(row pgx.Row) (*dbpb.Reminder, error) {
+	reminder := dbpb.Reminder{}
+	var fireAt *time.Time
+	var createdAt time.Time
+	err := row.Scan(
+		&reminder.Id,
+		&reminder.TaskId,
+		&reminder.OffsetMinutes,
+		&reminder.Channels,
+		&fireAt,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if fireAt != nil {
+		reminder.FireAt = timestamppb.New(*fireAt)
+	}
+	reminder.CreatedAt = timestamppb.New(createdAt)
+	return &reminder, nil
+}
+
+// checkChannels accepts a non-empty list of reminder channels, each once.
+func checkChannels(channels []string) error {
+	if len(channels) == 0 {
+		return status.Error(codes.InvalidArgument, "channels must not be empty")
+	}
+	for i, c := range channels {
+		if !slices.Contains(reminderChannels, c) {
+			return status.Errorf(codes.InvalidArgument, "unknown channel %q", c)
+		}
+		if slices.Contains(channels[:i], c) {
+			return status.Errorf(codes.InvalidArgument, "channel %q is listed twice", c)
+		}
+	}
+	return nil
+}
+
+func (s *Server) CreateReminder(ctx context.Context, req *dbpb.CreateReminderRequest) (*dbpb.Reminder, error) {
+	const op = "db/internal/handlers|CreateReminder()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetOffsetMinutes() < 0 || req.GetOffsetMinutes() > maxReminderOffset {
+		return nil, status.Errorf(codes.InvalidArgument, "offset_minutes must be between 0 and %d", maxReminderOffset)
+	}
+	if err := checkChannels(req.GetChannels()); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the task serializes the count below with other inserts.
+	var count int
+	query := `SELECT (SELECT count(*) FROM task_reminder WHERE task_id = task.id)
+			  FROM task
+			  WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+			  FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, req.GetTaskId(), uid).Scan(&count); err != nil {
+		return nil, taskError(op, err)
+	}
+	if count >= maxRemindersPerTask {
+		return nil, status.Errorf(codes.FailedPrecondition, "a task can have at most %d reminders", maxRemindersPerTask)
+	}
+
+	query = `WITH r AS (
+			     INSERT INTO task_reminder (task_id, user_id, offset_minutes, channels)
+			     VALUES ($1, $2, $3, $4)
+			     RETURNING *
+			 )
+			 SELECT ` + reminderColumns + `
+			 FROM r JOIN task t ON t.id = r.task_id`
+	reminder, err := scanReminder(tx.QueryRow(ctx, query, req.GetTaskId(), uid, req.GetOffsetMinutes(), req.GetChannels()))
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return reminder, nil
+}
+
+// ListReminders returns the reminders of a task, the first to fire first.
+func (s *Server) ListReminders(ctx context.Context, req *dbpb.ListRemindersRequest) (*dbpb.ListRemindersResponse, error) {
+	const op = "db/internal/handlers|ListReminders()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkTask(ctx, s.DB, op, uid, req.GetTaskId(), roleOwner); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + reminderColumns + `
+			  FROM task_reminder r JOIN task t ON t.id = r.task_id
+			  WHERE r.task_id = $1
+			  ORDER BY r.offset_minutes DESC`
+	rows, err := s.DB.Query(ctx, query, req.GetTaskId())
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListRemindersResponse{}
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		resp.Reminders = append(resp.Reminders, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
+
+func (s *Server) DeleteReminder(ctx context.Context, req *dbpb.DeleteReminderRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|DeleteReminder()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := `DELETE FROM task_reminder WHERE id = $1 AND task_id = $2 AND user_id = $3`
+	tag, err := s.DB.Exec(ctx, query, req.GetId(), req.GetTaskId(), uid)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errReminderNotFound
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ClaimDueReminders leases the reminders that are due, of all users: those
+// whose fire time has come for the current due date of an open task, and
+// those whose retry is due. Replicas of the scheduler skip each other's rows,
+// and a lease that runs out makes a reminder due again. Reminders that should
+// have fired more than max_lateness_seconds ago are left alone, so a long
+// outage does not end in a burst of stale reminders.
+func (s *Server) ClaimDueReminders(ctx context.Context, req *dbpb.ClaimDueRemindersRequest) (*dbpb.ReminderJobs, error) {
+	const op = "db/internal/handlers|ClaimDueReminders()"
+	limit := int(req.GetLimit())
+	if limit <= 0 || limit > maxReminderClaim {
+		limit = maxReminderClaim
+	}
+	lateness := req.GetMaxLatenessSeconds()
+	if lateness <= 0 {
+		lateness = defaultReminderLate
+	}
+
+	// A new due date starts over with no attempts and no channels sent.
+	query := `WITH due AS (
+			      SELECT r.id
+			      FROM task_reminder r JOIN task t ON t.id = r.task_id
+			      WHERE t.due_at IS NOT NULL AND NOT t.is_done AND t.deleted_at IS NULL
+			        AND r.fired_for IS DISTINCT FROM t.due_at
+			        AND t.due_at - r.offset_minutes * interval '1 minute' <= NOW() AT TIME ZONE 'UTC'
+			        AND t.due_at - r.offset_minutes * interval '1 minute' > NOW() AT TIME ZONE 'UTC' - $3::int * interval '1 second'
+			        AND (r.attempt_for IS DISTINCT FROM t.due_at OR r.next_attempt_at <= NOW())
+			      ORDER BY t.due_at - r.offset_minutes * interval '1 minute'
+			      LIMIT $1
+			      FOR UPDATE OF r SKIP LOCKED
+			  ), claimed AS (
+			      UPDATE task_reminder r
+			      SET attempts = CASE WHEN r.attempt_for IS DISTINCT FROM t.due_at THEN 0 ELSE r.attempts END,
+			          sent_channels = CASE WHEN r.attempt_for IS DISTINCT FROM t.due_at THEN '{}' ELSE r.sent_channels END,
+			          attempt_for = t.due_at,
+			          next_attempt_at = NOW() + $2::int * interval '1 second'
+			      FROM task t
+			      WHERE r.id IN (SELECT id FROM due) AND t.id = r.task_id
+			      RETURNING r.*
+			  )
+			  SELECT c.id, c.user_id, c.task_id, t.title, t.due_at, c.offset_minutes, c.channels,
+			         c.sent_channels, c.attempts, COALESCE(n.email, '')
+			  FROM claimed c
+			  JOIN task t ON t.id = c.task_id
+			  LEFT JOIN notification_settings n ON n.user_id = c.user_id
+			  ORDER BY t.due_at - c.offset_minutes * interval '1 minute'`
+	rows, err := s.DB.Query(ctx, query, limit, leaseSeconds(req.GetLeaseSeconds()), lateness)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ReminderJobs{}
+	for rows.Next() {
+		job := &dbpb.ReminderJob{}
+		var dueAt time.Time
+		err := rows.Scan(
+			&job.ReminderId,
+			&job.UserId,
+			&job.TaskId,
+			&job.TaskTitle,
+			&dueAt,
+			&job.OffsetMinutes,
+			&job.Channels,
+			&job.SentChannels,
+			&job.Attempts,
+			&job.Email,
+		)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		job.DueAt = timestamppb.New(dueAt)
+		resp.Jobs = append(resp.Jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
+
+// RecordReminderAttempt adds the channels that got the caller's reminder and
+// either schedules the next attempt or, without retry_at, marks the reminder
+// as fired for its due date. It does nothing to a reminder whose task got a
+// new due date since the claim; that one is due on its own schedule.
+func (s *Server) RecordReminderAttempt(ctx context.Context, req *dbpb.RecordReminderAttemptRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|RecordReminderAttempt()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetDueAt() == nil {
+		return nil, status.Error(codes.InvalidArgument, "due_at is required")
+	}
+	var retryAt *time.Time
+	if req.GetRetryAt() != nil {
+		t := req.GetRetryAt().AsTime()
+		retryAt = &t
+	}
+
+	query := `UPDATE task_reminder
+			  SET attempts = attempts + 1,
+			      sent_channels = ARRAY(SELECT DISTINCT unnest(sent_channels || $4::text[])),
+			      next_attempt_at = $5,
+			      fired_for = CASE WHEN $5::timestamptz IS NULL THEN attempt_for ELSE fired_for END
+			  WHERE id = $1 AND user_id = $2 AND attempt_for = $3`
+	tag, err := s.DB.Exec(ctx, query, req.GetReminderId(), uid, req.GetDueAt().AsTime(), req.GetSentChannels(), retryAt)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		var exists bool
+		query = `SELECT EXISTS (SELECT 1 FROM task_reminder WHERE id = $1 AND user_id = $2)`
+		if err := s.DB.QueryRow(ctx, query, req.GetReminderId(), uid).Scan(&exists); err != nil {
+			return nil, storageError(op, err)
+		}
+		if !exists {
+			return nil, errReminderNotFound
+		}
+	}
+	return &emptypb.Empty{}, nil
+}
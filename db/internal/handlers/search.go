@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/lib/tsquery"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// escapeHTML is the SQL escaping column as html.EscapeString does, so that the
+// <mark> of ts_headline is the only markup in snippets: the text is the user's,
+// and shared tasks show it to other users.
+func escapeHTML(column string) string {
+	return `replace(replace(replace(replace(replace(` + column +
+		`, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`
+}
+
+// SearchTasks ranks the caller's live tasks, and those shared with them, against
+// the search column added by migration 000010 and highlights the matches. As
+// in taskOwner, a share of a task or project covers the subtasks too.
+func (s *Server) SearchTasks(ctx context.Context, req *dbpb.SearchTasksRequest) (*dbpb.SearchTasksResponse, error) {
+	const op = "db/internal/handlers|SearchTasks()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q, err := tsquery.Build(req.GetQuery())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+
+	query := `WITH RECURSIVE shared AS (
+			      SELECT id FROM task
+			      WHERE id IN (SELECT task_id FROM task_share WHERE user_id = $1)
+			         OR project_id IN (SELECT project_id FROM task_share WHERE user_id = $1)
+			      UNION
+			      SELECT t.id FROM task t JOIN shared ON t.parent_id = shared.id
+			  )
+			  SELECT ` + taskColumns + `,
+			         ts_rank_cd(search, q) AS rank,
+			         ts_headline('simple', ` + escapeHTML("title") + `, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+			         ts_headline('simple', ` + escapeHTML("content") + `, q, 'StartSel=<mark>, StopSel=</mark>, MinWords=10, MaxWords=30')
+			  FROM task, to_tsquery('simple', $2) q
+			  WHERE (user_id = $1 OR id IN (SELECT id FROM shared)) AND deleted_at IS NULL AND search @@ q
+			  ORDER BY rank DESC, id DESC
+			  LIMIT $3 OFFSET $4`
+	rows, err := s.DB.Query(ctx, query, uid, q, limit, req.GetOffset())
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.SearchTasksResponse{}
+	for rows.Next() {
+		hit := &dbpb.SearchHit{}
+		hit.Task, err = scanTask(rows, &hit.Rank, &hit.TitleSnippet, &hit.ContentSnippet)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		resp.Hits = append(resp.Hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
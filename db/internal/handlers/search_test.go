@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/lib/pgtest"
+	"db/internal/lib/userctx"
+	"slices"
+	"testing"
+
+	"github.com/rail52/myprojects/dbpb"
+)
+
+// TestSearchSharedTasks checks that search finds the tasks shared with the
+// caller, with their subtasks, besides their own, and nothing else.
+func TestSearchSharedTasks(t *testing.T) {
+	s := &Server{DB: pgtest.New(t)}
+	owner := userctx.WithUserID(context.Background(), 1)
+	viewer := userctx.WithUserID(context.Background(), 2)
+
+	create := func(ctx context.Context, req *dbpb.CreateTaskRequest) int64 {
+		t.Helper()
+		task, err := s.CreateTask(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return task.GetId()
+	}
+	shared := create(owner, &dbpb.CreateTaskRequest{Title: "budget plan", Content: "for the trip"})
+	sub := create(owner, &dbpb.CreateTaskRequest{Title: "budget review", Content: "with Ann", ParentId: shared})
+	create(owner, &dbpb.CreateTaskRequest{Title: "budget secret", Content: "not shared"})
+	own := create(viewer, &dbpb.CreateTaskRequest{Title: "budget mine", Content: "groceries"})
+	if _, err := s.ShareTask(owner, &dbpb.ShareRequest{Id: shared, UserId: 2, Role: roleViewer}); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(ctx context.Context) []int64 {
+		t.Helper()
+		resp, err := s.SearchTasks(ctx, &dbpb.SearchTasksRequest{Query: "budget"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for _, hit := range resp.GetHits() {
+			ids = append(ids, hit.GetTask().GetId())
+		}
+		slices.Sort(ids)
+		return ids
+	}
+	got := search(viewer)
+	if want := []int64{shared, sub, own}; !slices.Equal(got, want) {
+		t.Errorf("viewer found %v, want %v", got, want)
+	}
+	if got := search(userctx.WithUserID(context.Background(), 3)); len(got) != 0 {
+		t.Errorf("stranger found %v, want none", got)
+	}
+}
+
+// TestSearchEscapesSnippets checks that the text of snippets is escaped, so
+// the <mark> of matches is their only markup.
+func TestSearchEscapesSnippets(t *testing.T) {
+	s := &Server{DB: pgtest.New(t)}
+	ctx := userctx.WithUserID(context.Background(), 1)
+	if _, err := s.CreateTask(ctx, &dbpb.CreateTaskRequest{
+		Title:   `<img src=x onerror="alert(1)"> budget`,
+		Content: `Tom's budget & <b>plan</b>`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := s.SearchTasks(ctx, &dbpb.SearchTasksRequest{Query: "budget"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetHits()) != 1 {
+		t.Fatalf("found %d tasks, want 1", len(resp.GetHits()))
+	}
+	hit := resp.GetHits()[0]
+	if want := `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>budget</mark>`; hit.GetTitleSnippet() != want {
+		t.Errorf("title snippet = %q, want %q", hit.GetTitleSnippet(), want)
+	}
+	if want := `Tom&#39;s <mark>budget</mark> &amp; &lt;b&gt;plan&lt;/b&gt;`; hit.GetContentSnippet() != want {
+		t.Errorf("content snippet = %q, want %q", hit.GetContentSnippet(), want)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const maxStatsPerRequest = 1000
+
+type statKey struct {
+	day       string
+	eventType string
+}
+
+// AddTaskStats adds the counters of the request to the caller's daily event
+// statistics. Entries for the same day and type are summed, so callers may
+// send partial counts as often as they like.
+func (s *Server) AddTaskStats(ctx context.Context, req *dbpb.AddTaskStatsRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|AddTaskStats()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetStats()) > maxStatsPerRequest {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d stats per request", maxStatsPerRequest)
+	}
+
+	// One row may only be upserted once per statement.
+	merged := map[statKey]*dbpb.TaskStat{}
+	var keys []statKey
+	for _, stat := range req.GetStats() {
+		if _, err := time.Parse(time.DateOnly, stat.GetDay()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid day %q", stat.GetDay())
+		}
+		if stat.GetEventType() == "" {
+			return nil, status.Error(codes.InvalidArgument, "event_type is required")
+		}
+		if stat.GetCount() < 0 || stat.GetCompletionSeconds() < 0 {
+			return nil, status.Error(codes.InvalidArgument, "counters must not be negative")
+		}
+		key := statKey{day: stat.GetDay(), eventType: stat.GetEventType()}
+		if m, ok := merged[key]; ok {
+			m.Count += stat.GetCount()
+			m.CompletionSeconds += stat.GetCompletionSeconds()
+			continue
+		}
+		merged[key] = &dbpb.TaskStat{
+			Day:               stat.GetDay(),
+			EventType:         stat.GetEventType(),
+			Count:             stat.GetCount(),
+			CompletionSeconds: stat.GetCompletionSeconds(),
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return &emptypb.Empty{}, nil
+	}
+
+	days := make([]string, 0, len(keys))
+	types := make([]string, 0, len(keys))
+	counts := make([]int64, 0, len(keys))
+	seconds := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		m := merged[key]
+		days = append(days, m.GetDay())
+		types = append(types, m.GetEventType())
+		counts = append(counts, m.GetCount())
+		seconds = append(seconds, m.GetCompletionSeconds())
+	}
+
+	query := `INSERT INTO task_event_stats (user_id, day, event_type, count, completion_seconds)
+			  SELECT $1, s.day::date, s.event_type, s.count, s.seconds
+			  FROM unnest($2::text[], $3::text[], $4::bigint[], $5::bigint[]) AS s(day, event_type, count, seconds)
+			  ON CONFLICT (user_id, day, event_type) DO UPDATE
+			  SET count = task_event_stats.count + EXCLUDED.count,
+			      completion_seconds = task_event_stats.completion_seconds + EXCLUDED.completion_seconds,
+			      updated_at = NOW()`
+	if _, err := s.DB.Exec(ctx, query, uid, days, types, counts, seconds); err != nil {
+		return nil, storageError(op, err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/outbox"
+	"log/slog"
+	"time"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RestoreTask takes a task out of the trash together with the subtasks that
+// were trashed along with it. Restoring a task that is not trashed is a no-op.
+func (s *Server) RestoreTask(ctx context.Context, req *dbpb.RestoreTaskRequest) (*dbpb.Task, error) {
+	const op = "db/internal/handlers|RestoreTask()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedAt *time.Time
+	var parentTrashed bool
+	query := `SELECT t.deleted_at, p.deleted_at IS NOT NULL
+			  FROM task t LEFT JOIN task p ON p.id = t.parent_id
+			  WHERE t.id = $1 AND t.user_id = $2
+			  FOR UPDATE OF t`
+	if err := tx.QueryRow(ctx, query, req.GetId(), uid).Scan(&deletedAt, &parentTrashed); err != nil {
+		return nil, taskError(op, err)
+	}
+	if deletedAt != nil {
+		if parentTrashed {
+			return nil, status.Error(codes.Aborted, "parent task is in the trash, restore it first")
+		}
+		query = `WITH RECURSIVE subtree AS (
+				      SELECT id FROM task WHERE id = $1
+				      UNION ALL
+				      SELECT t.id FROM task t JOIN subtree ON t.parent_id = subtree.id WHERE t.deleted_at = $2
+				  )
+				  UPDATE task
+				  SET deleted_at = NULL, updated_at = NOW(), version = version + 1
+				  WHERE id IN (SELECT id FROM subtree)
+				  RETURNING ` + taskColumns
+		rows, err := tx.Query(ctx, query, req.GetId(), *deletedAt)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		restored, err := collectTasks(rows)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		err = recordEach(ctx, tx, op, uid, historyRestored, restored, func(*dbpb.Task) map[string]fieldChange {
+			return trashChange(*deletedAt, false)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query = `SELECT ` + taskColumns + `
+			 FROM task
+			 WHERE id = $1 AND user_id = $2`
+	task, err := scanTask(tx.QueryRow(ctx, query, req.GetId(), uid))
+	if err != nil {
+		return nil, taskError(op, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return task, nil
+}
+
+// PurgeTrash permanently removes tasks trashed before the cutoff.
+func (s *Server) PurgeTrash(ctx context.Context, cutoff time.Time) (int64, error) {
+	const op = "db/internal/handlers|PurgeTrash()"
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `DELETE FROM task WHERE deleted_at < $1 RETURNING ` + taskColumns + `, user_id`
+	rows, err := tx.Query(ctx, query, cutoff)
+	if err != nil {
+		return 0, storageError(op, err)
+	}
+	type purged struct {
+		task *dbpb.Task
+		uid  int64
+	}
+	var tasks []purged
+	for rows.Next() {
+		var p purged
+		if p.task, err = scanTask(rows, &p.uid); err != nil {
+			rows.Close()
+			return 0, storageError(op, err)
+		}
+		tasks = append(tasks, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, storageError(op, err)
+	}
+	for _, p := range tasks {
+		if err := enqueue(ctx, tx, op, newEvent(ctx, p.uid, outbox.TaskDeleted).WithTask(p.task, nil)); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, storageError(op, err)
+	}
+	return int64(len(tasks)), nil
+}
+
+// RunTrashPurge purges tasks older than retention every interval until ctx is
+// done.
+func (s *Server) RunTrashPurge(ctx context.Context, log *slog.Logger, retention, interval time.Duration) {
+	const op = "db/internal/handlers|RunTrashPurge()"
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := s.PurgeTrash(ctx, time.Now().UTC().Add(-retention))
+		if err != nil {
+			log.Error("failed to purge trash", slog.String("error", err.Error()))
+		} else if n > 0 {
+			log.Info("purged trashed tasks", slog.Int64("count", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchBatchSize bounds the events read from event_outbox at a time.
+const watchBatchSize = 100
+
+// watchRetry is how soon WatchTasks looks again for events it held back
+// while older transactions were running; those end without waking it when
+// they wrote no event of the caller.
+const watchRetry = 200 * time.Millisecond
+
+// watchStartMetadataKey is the header carrying the id WatchTasks streams
+// events after.
+const watchStartMetadataKey = "x-after-id"
+
+// watchCursor is the position of a stream: the transaction of the last event
+// sent and its id. Events are sent in this order rather than by id alone,
+// since ids are taken before commit and a lower one can become visible after
+// a higher one.
+type watchCursor struct {
+	// txID is an xid8 in its text form.
+	txID string
+	id   int64
+}
+
+// WatchTasks streams the events of the caller's tasks and projects, and of
+// those shared with them, from event_outbox: those after req.AfterId first,
+// then each one as it is committed. An event is held back while a transaction
+// that began before its own is running, as that one may still add events the
+// stream has to send first; so every event is sent once. Published events are
+// pruned after the outbox retention, which bounds how far back a stream can
+// resume.
+func (s *Server) WatchTasks(req *dbpb.WatchTasksRequest, stream grpc.ServerStreamingServer[dbpb.TaskEvent]) error {
+	const op = "db/internal/handlers|WatchTasks()"
+	ctx := stream.Context()
+	uid, err := userID(ctx)
+	if err != nil {
+		return err
+	}
+	if s.Events == nil {
+		return status.Error(codes.Unavailable, "event streams are disabled")
+	}
+	if req.GetAfterId() < 0 {
+		return status.Error(codes.InvalidArgument, "after_id must not be negative")
+	}
+
+	// Watch before reading, so nothing committed in between is missed.
+	wake, stop := s.Events.Watch(uid)
+	defer stop()
+
+	cursor, err := s.watchStart(ctx, req.GetAfterId())
+	if err != nil {
+		return storageError(op, err)
+	}
+	// Tell the caller where the stream starts, so that it can resume from
+	// there when it breaks before the first event.
+	if err := stream.SendHeader(metadata.Pairs(watchStartMetadataKey, strconv.FormatInt(cursor.id, 10))); err != nil {
+		return err
+	}
+	for {
+		var retry <-chan time.Time
+		for {
+			events, next, held, err := s.outboxEvents(ctx, uid, cursor)
+			if err != nil {
+				return storageError(op, err)
+			}
+			for _, event := range events {
+				if err := stream.Send(event); err != nil {
+					return err
+				}
+			}
+			cursor = next
+			if held {
+				retry = time.After(watchRetry)
+				break
+			}
+			if len(events) < watchBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-retry:
+		}
+	}
+}
+
+// watchStart returns the cursor of the event after, or for 0 the cursor of
+// the last event every running transaction began after. When after has been
+// pruned, the stream starts at the oldest transaction of the events after it,
+// which may send some again.
+func (s *Server) watchStart(ctx context.Context, after int64) (watchCursor, error) {
+	cursor := watchCursor{txID: "0", id: after}
+	if after == 0 {
+		query := `SELECT tx_id::text, id
+				  FROM event_outbox
+				  WHERE tx_id < pg_snapshot_xmin(pg_current_snapshot())
+				  ORDER BY tx_id DESC, id DESC
+				  LIMIT 1`
+		err := s.DB.QueryRow(ctx, query).Scan(&cursor.txID, &cursor.id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return cursor, nil
+		}
+		return cursor, err
+	}
+	query := `SELECT COALESCE((SELECT tx_id FROM event_outbox WHERE id = $1),
+			                  (SELECT min(tx_id) FROM event_outbox WHERE id > $1),
+			                  pg_snapshot_xmin(pg_current_snapshot()))::text`
+	err := s.DB.QueryRow(ctx, query, after).Scan(&cursor.txID)
+	return cursor, err
+}
+
+// outboxEvents reads a batch of uid's events after cursor and returns them
+// with the cursor of the last one. held reports that an event was held back
+// because an older transaction is still running.
+func (s *Server) outboxEvents(ctx context.Context, uid int64, cursor watchCursor) (events []*dbpb.TaskEvent, next watchCursor, held bool, err error) {
+	// The snapshot is taken once for the statement, so rows at and past its
+	// xmin sort after every row that is sent. @> rather than = ANY, which
+	// cannot use the GIN index on audience.
+	query := `SELECT id, event_id, event_type, payload, created_at, tx_id::text,
+			         tx_id >= pg_snapshot_xmin(pg_current_snapshot())
+			  FROM event_outbox
+			  WHERE audience @> ARRAY[$1::bigint] AND (tx_id, id) > ($2::xid8, $3)
+			  ORDER BY tx_id, id
+			  LIMIT $4`
+	rows, err := s.DB.Query(ctx, query, uid, cursor.txID, cursor.id, watchBatchSize)
+	if err != nil {
+		return nil, cursor, false, err
+	}
+	defer rows.Close()
+
+	next = cursor
+	for rows.Next() {
+		event := &dbpb.TaskEvent{}
+		var occurredAt time.Time
+		var txID string
+		if err := rows.Scan(&event.Id, &event.EventId, &event.EventType, &event.Payload, &occurredAt, &txID, &held); err != nil {
+			return nil, cursor, false, err
+		}
+		if held {
+			break
+		}
+		event.OccurredAt = timestamppb.New(occurredAt)
+		events = append(events, event)
+		next = watchCursor{txID: txID, id: event.GetId()}
+	}
+	return events, next, held, rows.Err()
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"db/internal/lib/pgtest"
+	"db/internal/lib/userctx"
+	"db/internal/outbox"
+	"testing"
+	"time"
+
+	"github.com/rail52/myprojects/dbpb"
+)
+
+// readEvents reads uid's events after cursor until none is held back, as
+// WatchTasks does when it retries.
+func readEvents(t *testing.T, s *Server, uid int64, cursor watchCursor) ([]int64, watchCursor) {
+	t.Helper()
+	var ids []int64
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		events, next, held, err := s.outboxEvents(context.Background(), uid, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, event := range events {
+			ids = append(ids, event.GetId())
+		}
+		cursor = next
+		if !held {
+			return ids, cursor
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("events still held back after %v", ids)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestWatchOutOfOrderCommits commits the later of two events first: the
+// stream holds it back until the earlier one commits, then sends both.
+func TestWatchOutOfOrderCommits(t *testing.T) {
+	pool := pgtest.New(t)
+	s := &Server{DB: pool}
+	ctx := context.Background()
+
+	start, err := s.watchStart(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Rollback(ctx)
+	if err := outbox.Enqueue(ctx, first, outbox.New(outbox.TaskUpdated, 1, "first")); err != nil {
+		t.Fatal(err)
+	}
+	second, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Rollback(ctx)
+	if err := outbox.Enqueue(ctx, second, outbox.New(outbox.TaskUpdated, 1, "second")); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	events, next, held, err := s.outboxEvents(ctx, 1, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 || !held || next != start {
+		t.Fatalf("sent %d events, held %v while the first transaction runs; want none, held", len(events), held)
+	}
+
+	if err := first.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ids, cursor := readEvents(t, s, 1, start)
+	if len(ids) != 2 || ids[0] > ids[1] {
+		t.Fatalf("sent %v, want both events in the order of their transactions", ids)
+	}
+	if again, _ := readEvents(t, s, 1, cursor); len(again) != 0 {
+		t.Fatalf("sent %v again", again)
+	}
+
+	// A stream resuming after the first event gets the second.
+	resumed, err := s.watchStart(ctx, ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rest, _ := readEvents(t, s, 1, resumed); len(rest) != 1 || rest[0] != ids[1] {
+		t.Fatalf("resumed with %v, want [%d]", rest, ids[1])
+	}
+}
+
+// TestWatchSharedTasks checks that the events of a task reach the users it,
+// or its parent, is shared with, and nobody else.
+func TestWatchSharedTasks(t *testing.T) {
+	s := &Server{DB: pgtest.New(t)}
+	owner := userctx.WithUserID(context.Background(), 1)
+
+	start, err := s.watchStart(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := s.CreateTask(owner, &dbpb.CreateTaskRequest{Title: "trip", Content: "plan it"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ShareTask(owner, &dbpb.ShareRequest{Id: parent.GetId(), UserId: 2, Role: roleViewer}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateTask(owner, &dbpb.CreateTaskRequest{Title: "tickets", Content: "buy", ParentId: parent.GetId()}); err != nil {
+		t.Fatal(err)
+	}
+
+	ownerIDs, _ := readEvents(t, s, 1, start)
+	if len(ownerIDs) != 2 {
+		t.Fatalf("owner got %d events, want 2", len(ownerIDs))
+	}
+	// The viewer gets the subtask, created after the share.
+	if viewerIDs, _ := readEvents(t, s, 2, start); len(viewerIDs) != 1 || viewerIDs[0] != ownerIDs[1] {
+		t.Fatalf("viewer got %v, want [%d]", viewerIDs, ownerIDs[1])
+	}
+	if strangerIDs, _ := readEvents(t, s, 3, start); len(strangerIDs) != 0 {
+		t.Fatalf("stranger got %v, want none", strangerIDs)
+	}
+}
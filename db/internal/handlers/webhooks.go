@@ -0,0 +1,572 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"db/internal/outbox"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxWebhookURLLength   = 2048
+	maxWebhookEventTypes  = 20
+	maxWebhooksPerUser    = 20
+	maxWebhookClaim       = 100
+	defaultWebhookLease   = 60
+	maxWebhookErrorLength = 1000
+)
+
+// Statuses of a webhook delivery.
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryFailed    = "failed"
+)
+
+const webhookColumns = "id, url, event_types, description, active, created_at, updated_at"
+
+// deliveryColumns select from webhook_delivery d in the order scanDelivery expects.
+const deliveryColumns = "d.id, d.webhook_id, d.event_id::text, d.event_type, d.status, d.attempts, " +
+	"d.response_code, d.last_error, d.next_attempt_at, d.created_at, d.updated_at"
+
+// jobColumns are the columns of a webhook_delivery d joined with its webhook w
+// that make up a WebhookJob.
+const jobColumns = "d.id, d.webhook_id, w.user_id, w.url, w.secret, d.event_id::text, d.event_type, d.payload, d.attempts"
+
+// deliveryCursorSort marks delivery cursors so other cursors are not accepted.
+const deliveryCursorSort = "delivery"
+
+var (
+	errWebhookNotFound  = status.Error(codes.NotFound, "webhook not found")
+	errDeliveryNotFound = status.Error(codes.NotFound, "delivery not found")
+)
+
+func scanWebhook(row pgx.Row) (*dbpb.Webhook, error) {
+	webhook := dbpb.Webhook{}
+	var createdAt, updatedAt time.Time
+	err := row.Scan(
+		&webhook.Id,
+		&webhook.Url,
+		&webhook.EventTypes,
+		&webhook.Description,
+		&webhook.Active,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	webhook.CreatedAt = timestamppb.New(createdAt)
+	webhook.UpdatedAt = timestamppb.New(updatedAt)
+	return &webhook, nil
+}
+
+func scanDelivery(row pgx.Row) (*dbpb.WebhookDelivery, error) {
+	delivery := dbpb.WebhookDelivery{}
+	var nextAttemptAt *time.Time
+	var createdAt, updatedAt time.Time
+	err := row.Scan(
+		&delivery.Id,
+		&delivery.WebhookId,
+		&delivery.EventId,
+		&delivery.EventType,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.ResponseCode,
+		&delivery.LastError,
+		&nextAttemptAt,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if nextAttemptAt != nil {
+		delivery.NextAttemptAt = timestamppb.New(*nextAttemptAt)
+	}
+	delivery.CreatedAt = timestamppb.New(createdAt)
+	delivery.UpdatedAt = timestamppb.New(updatedAt)
+	return &delivery, nil
+}
+
+func collectJobs(rows pgx.Rows) (*dbpb.WebhookJobs, error) {
+	defer rows.Close()
+	resp := &dbpb.WebhookJobs{}
+	for rows.Next() {
+		job := &dbpb.WebhookJob{}
+		err := rows.Scan(
+			&job.DeliveryId,
+			&job.WebhookId,
+			&job.UserId,
+			&job.Url,
+			&job.Secret,
+			&job.EventId,
+			&job.EventType,
+			&job.Payload,
+			&job.Attempts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		resp.Jobs = append(resp.Jobs, job)
+	}
+	return resp, rows.Err()
+}
+
+// webhookError is storageError for webhook queries, where a missing row means
+// a missing webhook rather than a missing task.
+func webhookError(op string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errWebhookNotFound
+	}
+	return storageError(op, err)
+}
+
+// checkWebhook makes sure the webhook belongs to the user.
+func checkWebhook(ctx context.Context, db dbtx, op string, uid, id int64) error {
+	query := `SELECT id FROM webhook WHERE id = $1 AND user_id = $2`
+	if err := db.QueryRow(ctx, query, id, uid).Scan(&id); err != nil {
+		return webhookError(op, err)
+	}
+	return nil
+}
+
+func checkWebhookURL(raw string) error {
+	if len(raw) > maxWebhookURLLength {
+		return status.Errorf(codes.InvalidArgument, "url must be up to %d characters", maxWebhookURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return status.Error(codes.InvalidArgument, "url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return status.Error(codes.InvalidArgument, "url must not contain credentials")
+	}
+	return nil
+}
+
+// webhookEventTypes are the event types webhooks can subscribe to.
+var webhookEventTypes = append(slices.Clone(outbox.Types), outbox.ReminderDue)
+
+// checkEventTypes accepts the event types webhooks can subscribe to, each once.
+func checkEventTypes(types []string) ([]string, error) {
+	if len(types) > maxWebhookEventTypes {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d event types", maxWebhookEventTypes)
+	}
+	out := []string{}
+	for _, t := range types {
+		if !slices.Contains(webhookEventTypes, t) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown event type %q", t)
+		}
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// newWebhookSecret returns a random signing secret.
+func newWebhookSecret() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:])
+	return "whsec_" + hex.EncodeToString(b[:])
+}
+
+func leaseSeconds(seconds int32) int32 {
+	if seconds <= 0 {
+		return defaultWebhookLease
+	}
+	return seconds
+}
+
+func (s *Server) CreateWebhook(ctx context.Context, req *dbpb.CreateWebhookRequest) (*dbpb.Webhook, error) {
+	const op = "db/internal/handlers|CreateWebhook()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWebhookURL(req.GetUrl()); err != nil {
+		return nil, err
+	}
+	types, err := checkEventTypes(req.GetEventTypes())
+	if err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := s.DB.QueryRow(ctx, `SELECT count(*) FROM webhook WHERE user_id = $1`, uid).Scan(&count); err != nil {
+		return nil, storageError(op, err)
+	}
+	if count >= maxWebhooksPerUser {
+		return nil, status.Errorf(codes.ResourceExhausted, "at most %d webhooks per user", maxWebhooksPerUser)
+	}
+
+	secret := newWebhookSecret()
+	query := `INSERT INTO webhook (user_id, url, secret, event_types, description)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING ` + webhookColumns
+	webhook, err := scanWebhook(s.DB.QueryRow(ctx, query, uid, req.GetUrl(), secret, types, req.GetDescription()))
+	if err != nil {
+		return nil, webhookError(op, err)
+	}
+	webhook.Secret = secret
+	return webhook, nil
+}
+
+func (s *Server) GetWebhook(ctx context.Context, req *dbpb.GetWebhookRequest) (*dbpb.Webhook, error) {
+	const op = "db/internal/handlers|GetWebhook()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + webhookColumns + ` FROM webhook WHERE id = $1 AND user_id = $2`
+	webhook, err := scanWebhook(s.DB.QueryRow(ctx, query, req.GetId(), uid))
+	if err != nil {
+		return nil, webhookError(op, err)
+	}
+	return webhook, nil
+}
+
+func (s *Server) ListWebhooks(ctx context.Context, _ *emptypb.Empty) (*dbpb.ListWebhooksResponse, error) {
+	const op = "db/internal/handlers|ListWebhooks()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + webhookColumns + ` FROM webhook WHERE user_id = $1 ORDER BY id`
+	rows, err := s.DB.Query(ctx, query, uid)
+	if err != nil {
+		return nil, webhookError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListWebhooksResponse{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, webhookError(op, err)
+		}
+		resp.Webhooks = append(resp.Webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, webhookError(op, err)
+	}
+	return resp, nil
+}
+
+// UpdateWebhook changes the fields that are set. With rotate_secret the
+// webhook gets a new secret, returned once in the response.
+func (s *Server) UpdateWebhook(ctx context.Context, req *dbpb.UpdateWebhookRequest) (*dbpb.Webhook, error) {
+	const op = "db/internal/handlers|UpdateWebhook()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Url != nil {
+		if err := checkWebhookURL(req.GetUrl()); err != nil {
+			return nil, err
+		}
+	}
+	var types []string
+	if req.GetEventTypes() != nil {
+		if types, err = checkEventTypes(req.GetEventTypes().GetValues()); err != nil {
+			return nil, err
+		}
+	}
+	var secret *string
+	if req.GetRotateSecret() {
+		rotated := newWebhookSecret()
+		secret = &rotated
+	}
+
+	query := `UPDATE webhook
+			  SET url = COALESCE($3, url),
+			      event_types = COALESCE($4, event_types),
+			      description = COALESCE($5, description),
+			      active = COALESCE($6, active),
+			      secret = COALESCE($7, secret),
+			      updated_at = NOW()
+			  WHERE id = $1 AND user_id = $2
+			  RETURNING ` + webhookColumns
+	webhook, err := scanWebhook(s.DB.QueryRow(ctx, query, req.GetId(), uid, req.Url, types, req.Description, req.Active, secret))
+	if err != nil {
+		return nil, webhookError(op, err)
+	}
+	if secret != nil {
+		webhook.Secret = *secret
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook removes the webhook together with its deliveries.
+func (s *Server) DeleteWebhook(ctx context.Context, req *dbpb.DeleteWebhookRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|DeleteWebhook()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.DB.Exec(ctx, `DELETE FROM webhook WHERE id = $1 AND user_id = $2`, req.GetId(), uid)
+	if err != nil {
+		return nil, webhookError(op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errWebhookNotFound
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ListWebhookDeliveries(ctx context.Context, req *dbpb.ListWebhookDeliveriesRequest) (*dbpb.ListWebhookDeliveriesResponse, error) {
+	const op = "db/internal/handlers|ListWebhookDeliveries()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWebhook(ctx, s.DB, op, uid, req.GetWebhookId()); err != nil {
+		return nil, err
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	var beforeID int64
+	if req.GetAfter() != "" {
+		c, err := decodeCursor(req.GetAfter())
+		if err != nil || c.Sort != deliveryCursorSort {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		beforeID = c.ID
+	}
+
+	// One extra row tells whether another page exists.
+	query := `SELECT ` + deliveryColumns + `
+			  FROM webhook_delivery d
+			  WHERE d.webhook_id = $1 AND ($2 = 0 OR d.id < $2)
+			  ORDER BY d.id DESC
+			  LIMIT $3`
+	rows, err := s.DB.Query(ctx, query, req.GetWebhookId(), beforeID, limit+1)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer rows.Close()
+
+	resp := &dbpb.ListWebhookDeliveriesResponse{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, storageError(op, err)
+		}
+		resp.Deliveries = append(resp.Deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError(op, err)
+	}
+
+	if len(resp.Deliveries) > limit {
+		resp.Deliveries = resp.Deliveries[:limit]
+		resp.NextCursor = encodeCursor(cursor{Sort: deliveryCursorSort, ID: resp.Deliveries[limit-1].GetId()})
+	}
+	if err := loadAttempts(ctx, s.DB, resp.Deliveries); err != nil {
+		return nil, storageError(op, err)
+	}
+	return resp, nil
+}
+
+// loadAttempts fills the log of deliveries, newest attempt first.
+func loadAttempts(ctx context.Context, db dbtx, deliveries []*dbpb.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*dbpb.WebhookDelivery, len(deliveries))
+	ids := make([]int64, 0, len(deliveries))
+	for _, d := range deliveries {
+		byID[d.GetId()] = d
+		ids = append(ids, d.GetId())
+	}
+	query := `SELECT delivery_id, attempt, response_code, error, duration_ms, created_at
+			  FROM webhook_delivery_attempt
+			  WHERE delivery_id = ANY($1)
+			  ORDER BY delivery_id, id DESC`
+	rows, err := db.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var deliveryID int64
+		var createdAt time.Time
+		attempt := &dbpb.WebhookAttempt{}
+		if err := rows.Scan(&deliveryID, &attempt.Attempt, &attempt.ResponseCode, &attempt.Error, &attempt.DurationMs, &createdAt); err != nil {
+			return err
+		}
+		attempt.CreatedAt = timestamppb.New(createdAt)
+		d := byID[deliveryID]
+		d.Log = append(d.Log, attempt)
+	}
+	return rows.Err()
+}
+
+// RedeliverWebhook queues a delivery to be sent again right away, whatever
+// became of it, with a fresh budget of attempts.
+func (s *Server) RedeliverWebhook(ctx context.Context, req *dbpb.RedeliverWebhookRequest) (*dbpb.WebhookDelivery, error) {
+	const op = "db/internal/handlers|RedeliverWebhook()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := `UPDATE webhook_delivery d
+			  SET status = '` + deliveryPending + `', attempts = 0, next_attempt_at = NOW(),
+			      locked_until = NULL, updated_at = NOW()
+			  FROM webhook w
+			  WHERE d.id = $1 AND d.webhook_id = $2 AND w.id = d.webhook_id AND w.user_id = $3
+			  RETURNING ` + deliveryColumns
+	delivery, err := scanDelivery(s.DB.QueryRow(ctx, query, req.GetDeliveryId(), req.GetWebhookId(), uid))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errDeliveryNotFound
+	}
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	if err := loadAttempts(ctx, s.DB, []*dbpb.WebhookDelivery{delivery}); err != nil {
+		return nil, storageError(op, err)
+	}
+	return delivery, nil
+}
+
+// EnqueueWebhookDeliveries creates the deliveries of an event of the caller
+// and leases them to the caller, which sends them right away.
+func (s *Server) EnqueueWebhookDeliveries(ctx context.Context, req *dbpb.EnqueueWebhookDeliveriesRequest) (*dbpb.WebhookJobs, error) {
+	const op = "db/internal/handlers|EnqueueWebhookDeliveries()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(webhookEventTypes, req.GetEventType()) {
+		// Reads and other events that webhooks cannot subscribe to.
+		return &dbpb.WebhookJobs{}, nil
+	}
+	query := `WITH d AS (
+			      INSERT INTO webhook_delivery (webhook_id, event_id, event_type, payload, next_attempt_at, locked_until)
+			      SELECT id, $2, $3, $4, NOW(), NOW() + $5::int * interval '1 second'
+			      FROM webhook
+			      WHERE user_id = $1 AND active AND (cardinality(event_types) = 0 OR $3 = ANY(event_types))
+			      ON CONFLICT (webhook_id, event_id) DO NOTHING
+			      RETURNING *
+			  )
+			  SELECT ` + jobColumns + `
+			  FROM d JOIN webhook w ON w.id = d.webhook_id
+			  ORDER BY d.id`
+	rows, err := s.DB.Query(ctx, query, uid, req.GetEventId(), req.GetEventType(), req.GetPayload(), leaseSeconds(req.GetLeaseSeconds()))
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	jobs, err := collectJobs(rows)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	return jobs, nil
+}
+
+// ClaimWebhookDeliveries leases the pending deliveries that are due, of all
+// users. Replicas of the dispatcher skip each other's rows.
+func (s *Server) ClaimWebhookDeliveries(ctx context.Context, req *dbpb.ClaimWebhookDeliveriesRequest) (*dbpb.WebhookJobs, error) {
+	const op = "db/internal/handlers|ClaimWebhookDeliveries()"
+	limit := int(req.GetLimit())
+	if limit <= 0 || limit > maxWebhookClaim {
+		limit = maxWebhookClaim
+	}
+	query := `WITH due AS (
+			      SELECT d.id
+			      FROM webhook_delivery d JOIN webhook w ON w.id = d.webhook_id
+			      WHERE d.status = '` + deliveryPending + `' AND d.next_attempt_at <= NOW()
+			        AND (d.locked_until IS NULL OR d.locked_until < NOW()) AND w.active
+			      ORDER BY d.next_attempt_at
+			      LIMIT $1
+			      FOR UPDATE OF d SKIP LOCKED
+			  ), d AS (
+			      UPDATE webhook_delivery
+			      SET locked_until = NOW() + $2::int * interval '1 second'
+			      WHERE id IN (SELECT id FROM due)
+			      RETURNING *
+			  )
+			  SELECT ` + jobColumns + `
+			  FROM d JOIN webhook w ON w.id = d.webhook_id
+			  ORDER BY d.next_attempt_at`
+	rows, err := s.DB.Query(ctx, query, limit, leaseSeconds(req.GetLeaseSeconds()))
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	jobs, err := collectJobs(rows)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	return jobs, nil
+}
+
+// RecordWebhookAttempt logs an attempt of the caller's delivery and releases
+// its lease. A failed delivery stays pending until retry_at, or fails for
+// good without one.
+func (s *Server) RecordWebhookAttempt(ctx context.Context, req *dbpb.RecordWebhookAttemptRequest) (*emptypb.Empty, error) {
+	const op = "db/internal/handlers|RecordWebhookAttempt()"
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deliveryStatus := deliveryFailed
+	var retryAt *time.Time
+	switch {
+	case req.GetDelivered():
+		deliveryStatus = deliveryDelivered
+	case req.GetRetryAt() != nil:
+		deliveryStatus = deliveryPending
+		t := req.GetRetryAt().AsTime()
+		retryAt = &t
+	}
+	lastError := req.GetError()
+	if len(lastError) > maxWebhookErrorLength {
+		lastError = lastError[:maxWebhookErrorLength]
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var attempt int32
+	query := `UPDATE webhook_delivery d
+			  SET attempts = d.attempts + 1, status = $3, response_code = $4, last_error = $5,
+			      next_attempt_at = $6, locked_until = NULL, updated_at = NOW()
+			  FROM webhook w
+			  WHERE d.id = $1 AND w.id = d.webhook_id AND w.user_id = $2
+			  RETURNING d.attempts`
+	err = tx.QueryRow(ctx, query, req.GetDeliveryId(), uid, deliveryStatus, req.GetResponseCode(), lastError, retryAt).Scan(&attempt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errDeliveryNotFound
+	}
+	if err != nil {
+		return nil, storageError(op, err)
+	}
+	query = `INSERT INTO webhook_delivery_attempt (delivery_id, attempt, response_code, error, duration_ms)
+			 VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(ctx, query, req.GetDeliveryId(), attempt, req.GetResponseCode(), lastError, req.GetDurationMs()); err != nil {
+		return nil, storageError(op, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, storageError(op, err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,321 @@
+// Package rrule implements the subset of iCalendar recurrence rules (RFC 5545)
+// used by recurring tasks: FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Freq int
+
+const (
+	Daily Freq = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+// cycle is how many periods of each frequency make up 400 years, after which
+// the Gregorian calendar repeats day for day and weekday for weekday. The
+// periods a rule visits repeat as well, so a rule that goes a whole cycle
+// without an occurrence, e.g. BYMONTHDAY=31 with BYDAY=1MO, has none left.
+var cycle = map[Freq]int{
+	Daily:   146097,
+	Weekly:  20871,
+	Monthly: 4800,
+	Yearly:  400,
+}
+
+var freqs = map[string]Freq{
+	"DAILY":   Daily,
+	"WEEKLY":  Weekly,
+	"MONTHLY": Monthly,
+	"YEARLY":  Yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Day is a BYDAY entry. N is the optional ordinal ("2MO", "-1FR") counted
+// within the month or year; 0 means every such weekday.
+type Day struct {
+	N       int
+	Weekday time.Weekday
+}
+
+type Rule struct {
+	Freq       Freq
+	Interval   int
+	ByDay      []Day
+	ByMonthDay []int
+	Count      int
+	Until      time.Time
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH". An optional
+// "RRULE:" prefix is accepted.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("rrule is empty")
+	}
+	r := &Rule{Interval: 1}
+	var hasFreq bool
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("rrule: malformed part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			f, ok := freqs[strings.ToUpper(value)]
+			if !ok {
+				return nil, fmt.Errorf("rrule: unsupported FREQ %q", value)
+			}
+			r.Freq, hasFreq = f, true
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("rrule: invalid INTERVAL %q", value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("rrule: invalid COUNT %q", value)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, err := parseUntil(value)
+			if err != nil {
+				return nil, fmt.Errorf("rrule: invalid UNTIL %q", value)
+			}
+			r.Until = t
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				d, err := parseDay(v)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, d)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("rrule: invalid BYMONTHDAY %q", v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		default:
+			return nil, fmt.Errorf("rrule: unsupported part %q", key)
+		}
+	}
+
+	if !hasFreq {
+		return nil, errors.New("rrule: FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, errors.New("rrule: COUNT and UNTIL are mutually exclusive")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return nil, errors.New("rrule: BYMONTHDAY is not allowed with FREQ=WEEKLY")
+	}
+	if r.Freq == Daily || r.Freq == Weekly {
+		for _, d := range r.ByDay {
+			if d.N != 0 {
+				return nil, errors.New("rrule: BYDAY ordinals need FREQ=MONTHLY or YEARLY")
+			}
+		}
+	}
+	return r, nil
+}
+
+func parseDay(s string) (Day, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return Day{}, fmt.Errorf("rrule: invalid BYDAY %q", s)
+	}
+	wd, ok := weekdays[s[len(s)-2:]]
+	if !ok {
+		return Day{}, fmt.Errorf("rrule: invalid BYDAY %q", s)
+	}
+	d := Day{Weekday: wd}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return Day{}, fmt.Errorf("rrule: invalid BYDAY %q", s)
+		}
+		d.N = n
+	}
+	return d, nil
+}
+
+func parseUntil(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day.
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unknown format")
+}
+
+// Next returns the first occurrence strictly after t of the series that starts
+// at start, or false when the series has ended.
+func (r *Rule) Next(start, t time.Time) (time.Time, bool) {
+	var next time.Time
+	var found bool
+	r.each(start, func(occ time.Time) bool {
+		if occ.After(t) {
+			next, found = occ, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// Between returns up to limit occurrences within [from, to].
+func (r *Rule) Between(start, from, to time.Time, limit int) []time.Time {
+	var out []time.Time
+	r.each(start, func(occ time.Time) bool {
+		if occ.After(to) || len(out) >= limit {
+			return false
+		}
+		if !occ.Before(from) {
+			out = append(out, occ)
+		}
+		return true
+	})
+	return out
+}
+
+// each calls fn for every occurrence in order until fn returns false or the
+// series ends. start is always the first occurrence, as DTSTART is in RFC 5545.
+func (r *Rule) each(start time.Time, fn func(time.Time) bool) {
+	n := 0
+	emit := func(t time.Time) bool {
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		n++
+		if r.Count > 0 && n > r.Count {
+			return false
+		}
+		return fn(t)
+	}
+	if !emit(start) {
+		return
+	}
+
+	// Series end only by COUNT, UNTIL or fn; last stops those that can no
+	// longer match.
+	last := 0
+	for p := 0; p-last <= cycle[r.Freq]; p++ {
+		first, end := r.period(start, p*r.Interval)
+		for d := first; !d.After(end); d = d.AddDate(0, 0, 1) {
+			if !r.matches(start, d, first, end) {
+				continue
+			}
+			t := time.Date(d.Year(), d.Month(), d.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+			if !t.After(start) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+			last = p
+		}
+	}
+}
+
+// period returns the first and last day of the k-th period after the one
+// holding start. Weeks start on Monday.
+func (r *Rule) period(start time.Time, k int) (time.Time, time.Time) {
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	switch r.Freq {
+	case Weekly:
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		first := monday.AddDate(0, 0, 7*k)
+		return first, first.AddDate(0, 0, 6)
+	case Monthly:
+		first := time.Date(day.Year(), day.Month()+time.Month(k), 1, 0, 0, 0, 0, day.Location())
+		return first, first.AddDate(0, 1, -1)
+	case Yearly:
+		first := time.Date(day.Year()+k, time.January, 1, 0, 0, 0, 0, day.Location())
+		return first, first.AddDate(1, 0, -1)
+	default:
+		d := day.AddDate(0, 0, k)
+		return d, d
+	}
+}
+
+func (r *Rule) matches(start, d, first, last time.Time) bool {
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		switch r.Freq {
+		case Weekly:
+			return d.Weekday() == start.Weekday()
+		case Monthly:
+			return d.Day() == start.Day()
+		case Yearly:
+			return d.Month() == start.Month() && d.Day() == start.Day()
+		default:
+			return true
+		}
+	}
+	if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, d) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !matchesDay(r.ByDay, d, first, last) {
+		return false
+	}
+	return true
+}
+
+func matchesMonthDay(days []int, d time.Time) bool {
+	daysInMonth := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+	for _, n := range days {
+		if n > 0 && d.Day() == n || n < 0 && d.Day() == daysInMonth+n+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesDay(days []Day, d, first, last time.Time) bool {
+	for _, day := range days {
+		if day.Weekday != d.Weekday() {
+			continue
+		}
+		switch {
+		case day.N == 0:
+			return true
+		case day.N > 0 && daysBetween(first, d)/7+1 == day.N:
+			return true
+		case day.N < 0 && daysBetween(d, last)/7+1 == -day.N:
+			return true
+		}
+	}
+	return false
+}
+
+func daysBetween(a, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
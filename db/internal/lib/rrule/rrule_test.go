@@ -0,0 +1,165 @@
+package rrule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr bool
+	}{
+		{name: "Weekly", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{name: "Prefix", rule: "RRULE:FREQ=DAILY;COUNT=3"},
+		{name: "Monthly ordinal", rule: "FREQ=MONTHLY;BYDAY=-1FR"},
+		{name: "Until date", rule: "FREQ=DAILY;UNTIL=20250110"},
+		{name: "Empty", rule: "", wantErr: true},
+		{name: "No FREQ", rule: "INTERVAL=2", wantErr: true},
+		{name: "Unknown FREQ", rule: "FREQ=HOURLY", wantErr: true},
+		{name: "Unknown part", rule: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+		{name: "Bad interval", rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "Count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20250110", wantErr: true},
+		{name: "Weekly by month day", rule: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{name: "Weekly ordinal", rule: "FREQ=WEEKLY;BYDAY=2MO", wantErr: true},
+		{name: "Bad weekday", rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.rule, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []time.Time
+	}{
+		{
+			name:  "Daily with count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: date(2025, 1, 1),
+			want:  []time.Time{date(2025, 1, 1), date(2025, 1, 2), date(2025, 1, 3)},
+		},
+		{
+			name:  "Every other week on Monday and Thursday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=4",
+			start: date(2025, 1, 6), // Monday
+			want:  []time.Time{date(2025, 1, 6), date(2025, 1, 9), date(2025, 1, 20), date(2025, 1, 23)},
+		},
+		{
+			name:  "Monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			start: date(2025, 1, 31),
+			want:  []time.Time{date(2025, 1, 31), date(2025, 3, 31), date(2025, 5, 31)},
+		},
+		{
+			name:  "Last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			start: date(2025, 1, 31),
+			want:  []time.Time{date(2025, 1, 31), date(2025, 2, 28), date(2025, 3, 31)},
+		},
+		{
+			name:  "Last Friday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			start: date(2025, 1, 31),
+			want:  []time.Time{date(2025, 1, 31), date(2025, 2, 28), date(2025, 3, 28)},
+		},
+		{
+			name:  "Yearly until",
+			rule:  "FREQ=YEARLY;UNTIL=20270301",
+			start: date(2025, 3, 1),
+			want:  []time.Time{date(2025, 3, 1), date(2026, 3, 1), date(2027, 3, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := r.Between(tt.start, tt.start, tt.start.AddDate(5, 0, 0), 100)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Between() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	r, err := Parse("FREQ=WEEKLY;BYDAY=MO,FR;COUNT=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := date(2025, 1, 6)
+
+	tests := []struct {
+		after  time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{after: start, want: date(2025, 1, 10), wantOK: true},
+		{after: date(2025, 1, 10), want: date(2025, 1, 13), wantOK: true},
+		{after: date(2025, 1, 13), wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := r.Next(start, tt.after)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("Next(%v) = %v, %v, want %v, %v", tt.after, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNextLongSeries(t *testing.T) {
+	// Both series are well past 10000 periods after their start.
+	tests := []struct {
+		rule  string
+		start time.Time
+		after time.Time
+		want  time.Time
+	}{
+		{rule: "FREQ=DAILY", start: date(1990, 1, 1), after: date(2030, 6, 1), want: date(2030, 6, 2)},
+		{rule: "FREQ=WEEKLY;BYDAY=MO", start: date(1800, 1, 6), after: date(2030, 6, 1), want: date(2030, 6, 3)},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := r.Next(tt.start, tt.after)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%v) = %v, %v, want %v", tt.rule, tt.after, got, ok, tt.want)
+		}
+	}
+}
+
+func TestNextNeverMatches(t *testing.T) {
+	for _, rule := range []string{
+		"FREQ=MONTHLY;BYMONTHDAY=31;BYDAY=1MO",
+		"FREQ=YEARLY;BYMONTHDAY=30;BYDAY=1FR",
+		// Every seventh day from a Monday is a Monday.
+		"FREQ=DAILY;INTERVAL=7;BYDAY=TU",
+	} {
+		r, err := Parse(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := r.Next(date(2025, 1, 6), date(2025, 1, 6)); ok {
+			t.Errorf("%s: Next = %v, want none", rule, got)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package tsquery turns a search box query into a Postgres to_tsquery
+// expression. It understands plain words (all must match), "quoted phrases",
+// word* prefixes, -word exclusions and OR between terms. Everything that is not
+// a letter or digit is dropped, so the result is always a valid tsquery.
+package tsquery
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrEmpty = errors.New("query has no searchable words")
+
+type token struct {
+	text   string
+	phrase bool
+	negate bool
+}
+
+func tokenize(q string) []token {
+	var tokens []token
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		var t token
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			t.negate = true
+			i++
+		}
+		if runes[i] == '"' {
+			t.phrase = true
+			i++
+			start := i
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			t.text = string(runes[start:i])
+			i++ // closing quote, if any
+		} else {
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			t.text = string(runes[start:i])
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+func lexemes(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Build returns the to_tsquery expression for q or ErrEmpty when q holds no
+// words.
+func Build(q string) (string, error) {
+	var b strings.Builder
+	or := false
+	for _, t := range tokenize(q) {
+		if !t.phrase && !t.negate && t.text == "OR" {
+			or = b.Len() > 0
+			continue
+		}
+		prefix := !t.phrase && strings.HasSuffix(t.text, "*")
+		words := lexemes(t.text)
+		if len(words) == 0 {
+			continue
+		}
+		if prefix {
+			words[len(words)-1] += ":*"
+		}
+		expr := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			expr = "(" + expr + ")"
+		}
+		if t.negate {
+			expr = "!" + expr
+		}
+
+		if b.Len() > 0 {
+			if or {
+				b.WriteString(" | ")
+			} else {
+				b.WriteString(" & ")
+			}
+		}
+		or = false
+		b.WriteString(expr)
+	}
+	if b.Len() == 0 {
+		return "", ErrEmpty
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,41 @@
+package tsquery
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		q       string
+		want    string
+		wantErr error
+	}{
+		{q: "milk", want: "milk"},
+		{q: "Buy  Milk", want: "buy & milk"},
+		{q: `"weekly report" draft`, want: "(weekly <-> report) & draft"},
+		{q: "rep*", want: "rep:*"},
+		{q: "groceries -milk", want: "groceries & !milk"},
+		{q: `-"old stuff" new`, want: "!(old <-> stuff) & new"},
+		{q: "milk OR bread", want: "milk | bread"},
+		{q: "OR milk", want: "milk"},
+		{q: "e-mail", want: "(e <-> mail)"},
+		{q: "Ёлка пр*", want: "ёлка & пр:*"},
+		{q: `'); DROP TABLE task; --`, want: "drop & table & task"},
+		{q: `"unterminated phrase`, want: "(unterminated <-> phrase)"},
+		{q: "  ", wantErr: ErrEmpty},
+		{q: "* - !", wantErr: ErrEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			got, err := Build(tt.q)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Build(%q) error = %v, want %v", tt.q, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build(%q) = %q, want %q", tt.q, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package userctx
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKey is the gRPC metadata key todo-app puts the caller's user id under.
+const MetadataKey = "x-user-id"
+
+// RequestIDMetadataKey carries the id of the HTTP request that led to the call.
+// It is optional and only used to tie audit records to access logs.
+const RequestIDMetadataKey = "x-request-id"
+
+type ctxKey struct{}
+
+type requestIDKey struct{}
+
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, ctxKey{}, userID)
+}
+
+func UserID(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(ctxKey{}).(int64)
+	return userID, ok
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the caller's request id, or "" when it sent none.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func fromMetadata(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "metadata is missing")
+	}
+	values := md.Get(MetadataKey)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "user id is missing")
+	}
+	userID, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil || userID <= 0 {
+		return nil, status.Error(codes.Unauthenticated, "user id is invalid")
+	}
+	ctx = WithUserID(ctx, userID)
+	if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+		ctx = WithRequestID(ctx, values[0])
+	}
+	return ctx, nil
+}
+
+// UnaryServerInterceptor rejects calls without a user id and stores it in the handler context.
+// The full method names in system are exempt: background jobs call them for all
+// users at once, and they must not rely on a user id.
+func UnaryServerInterceptor(system ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if slices.Contains(system, info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := fromMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := fromMetadata(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
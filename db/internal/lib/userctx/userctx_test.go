@@ -0,0 +1,107 @@
+package userctx
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const method = "/dbpb.Postgres/GetTask"
+
+// call runs the unary interceptor around a handler that reports the context
+// it was given.
+func call(t *testing.T, ctx context.Context, fullMethod string, system ...string) (context.Context, error) {
+	t.Helper()
+	var got context.Context
+	handler := func(ctx context.Context, _ any) (any, error) {
+		got = ctx
+		return nil, nil
+	}
+	_, err := UnaryServerInterceptor(system...)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+	return got, err
+}
+
+func incoming(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		msg  string
+	}{
+		{name: "No metadata", ctx: context.Background(), msg: "metadata is missing"},
+		{name: "No user id", ctx: incoming(RequestIDMetadataKey, "req-1"), msg: "user id is missing"},
+		{name: "Not a number", ctx: incoming(MetadataKey, "abc"), msg: "user id is invalid"},
+		{name: "Zero", ctx: incoming(MetadataKey, "0"), msg: "user id is invalid"},
+		{name: "Negative", ctx: incoming(MetadataKey, "-7"), msg: "user id is invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := call(t, tt.ctx, method)
+			if got != nil {
+				t.Fatal("handler ran without a valid user id")
+			}
+			st, _ := status.FromError(err)
+			if st.Code() != codes.Unauthenticated || st.Message() != tt.msg {
+				t.Fatalf("err = %v, want Unauthenticated %q", err, tt.msg)
+			}
+		})
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		got, err := call(t, incoming(MetadataKey, "42", RequestIDMetadataKey, "req-1"), method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id, ok := UserID(got); !ok || id != 42 {
+			t.Fatalf("UserID = %d, %v; want 42", id, ok)
+		}
+		if id := RequestID(got); id != "req-1" {
+			t.Fatalf("RequestID = %q, want req-1", id)
+		}
+	})
+
+	t.Run("System method", func(t *testing.T) {
+		got, err := call(t, context.Background(), "/dbpb.Postgres/ClaimDueReminders", "/dbpb.Postgres/ClaimDueReminders")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := UserID(got); ok {
+			t.Fatal("system method got a user id")
+		}
+	})
+}
+
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var got context.Context
+	handler := func(_ any, ss grpc.ServerStream) error {
+		got = ss.Context()
+		return nil
+	}
+	interceptor := StreamServerInterceptor()
+
+	err := interceptor(nil, &stream{ctx: incoming(MetadataKey, "x")}, &grpc.StreamServerInfo{}, handler)
+	if status.Code(err) != codes.Unauthenticated || got != nil {
+		t.Fatalf("err = %v, want Unauthenticated before the handler", err)
+	}
+
+	if err := interceptor(nil, &stream{ctx: incoming(MetadataKey, "9")}, &grpc.StreamServerInfo{}, handler); err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := UserID(got); !ok || id != 9 {
+		t.Fatalf("UserID = %d, %v; want 9", id, ok)
+	}
+}
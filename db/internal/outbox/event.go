@@ -0,0 +1,176 @@
+package outbox
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rail52/myprojects/dbpb"
+)
+
+// Version is the version of the envelope. Event and Task mirror
+// todo-app/internal/domain/events, whose schema.json documents them for
+// consumers; change both together.
+const Version = 1
+
+// Event types.
+const (
+	TaskCreated  = "task.created"
+	TaskUpdated  = "task.updated"
+	TaskDone     = "task.done"
+	TaskTrashed  = "task.trashed"
+	TaskRestored = "task.restored"
+	TaskDeleted  = "task.deleted"
+	TaskReverted = "task.reverted"
+
+	CommentAdded   = "comment.added"
+	CommentUpdated = "comment.updated"
+	CommentDeleted = "comment.deleted"
+
+	AttachmentAdded   = "attachment.added"
+	AttachmentDeleted = "attachment.deleted"
+
+	ProjectCreated  = "project.created"
+	ProjectUpdated  = "project.updated"
+	ProjectArchived = "project.archived"
+	ProjectDeleted  = "project.deleted"
+
+	// ReminderDue is not published by the outbox: todo-app's reminder
+	// scheduler sends it to webhooks only.
+	ReminderDue = "reminder.due"
+)
+
+// Types lists every event type the outbox publishes.
+var Types = []string{
+	TaskCreated, TaskUpdated, TaskDone, TaskTrashed, TaskRestored, TaskDeleted, TaskReverted,
+	CommentAdded, CommentUpdated, CommentDeleted,
+	AttachmentAdded, AttachmentDeleted,
+	ProjectCreated, ProjectUpdated, ProjectArchived, ProjectDeleted,
+}
+
+// Event is the envelope of every message on the topic.
+type Event struct {
+	ID         string    `json:"id"`
+	Version    int       `json:"version"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	UserID     int64     `json:"user_id,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	// TaskID is the task the event is about, also the message key.
+	TaskID int64 `json:"task_id,omitempty"`
+	Before *Task `json:"before,omitempty"`
+	After  *Task `json:"after,omitempty"`
+	// Data holds ids that are not tasks, such as comment_id or project_id.
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// Task is the snapshot of a task in an event.
+type Task struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	IsDone      bool       `json:"is_done"`
+	Version     int64      `json:"version"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Description string     `json:"description,omitempty"`
+	ProjectID   int64      `json:"project_id,omitempty"`
+	ParentID    int64      `json:"parent_id,omitempty"`
+	Rrule       string     `json:"rrule,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Snapshot converts a task, or returns nil for nil.
+func Snapshot(t *dbpb.Task) *Task {
+	if t == nil {
+		return nil
+	}
+	snap := &Task{
+		ID:          t.GetId(),
+		Title:       t.GetTitle(),
+		Content:     t.GetContent(),
+		IsDone:      t.GetIsDone(),
+		Version:     t.GetVersion(),
+		Priority:    t.GetPriority(),
+		Tags:        t.GetTags(),
+		Description: t.GetDescription(),
+		ProjectID:   t.GetProjectId(),
+		ParentID:    t.GetParentId(),
+		Rrule:       t.GetRrule(),
+		CreatedAt:   t.GetCreatedAt().AsTime().UTC(),
+		UpdatedAt:   t.GetUpdatedAt().AsTime().UTC(),
+	}
+	if t.GetDueAt() != nil {
+		dueAt := t.GetDueAt().AsTime().UTC()
+		snap.DueAt = &dueAt
+	}
+	if t.GetDeletedAt() != nil {
+		deletedAt := t.GetDeletedAt().AsTime().UTC()
+		snap.DeletedAt = &deletedAt
+	}
+	return snap
+}
+
+// New starts an event of the given type made by userID in the request
+// requestID.
+func New(eventType string, userID int64, requestID string) *Event {
+	return &Event{
+		ID:         newID(),
+		Version:    Version,
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		UserID:     userID,
+		RequestID:  requestID,
+	}
+}
+
+// WithTask sets the task the event is about and its state before and after
+// the change. Either may be nil: before for new tasks, after for deleted ones.
+func (e *Event) WithTask(before, after *dbpb.Task) *Event {
+	e.Before = Snapshot(before)
+	e.After = Snapshot(after)
+	switch {
+	case after != nil:
+		e.TaskID = after.GetId()
+	case before != nil:
+		e.TaskID = before.GetId()
+	}
+	return e
+}
+
+// WithTaskID sets the task for events that carry no snapshot.
+func (e *Event) WithTaskID(id int64) *Event {
+	e.TaskID = id
+	return e
+}
+
+// With adds an entry to Data.
+func (e *Event) With(key, value string) *Event {
+	if e.Data == nil {
+		e.Data = map[string]string{}
+	}
+	e.Data[key] = value
+	return e
+}
+
+// Key is the message key: the task id, so the events of one task stay in
+// order on one partition, or the type for events that are not about a task.
+func (e *Event) Key() string {
+	if e.TaskID != 0 {
+		return strconv.FormatInt(e.TaskID, 10)
+	}
+	return e.Type
+}
+
+// newID returns a random (version 4) UUID.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
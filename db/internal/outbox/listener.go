@@ -0,0 +1,127 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotifyChannel is notified with the id of every user in the audience of an
+// event stored in event_outbox, once the storing transaction commits.
+const NotifyChannel = "event_outbox"
+
+// Listener LISTENs on NotifyChannel over one connection and wakes the
+// watchers of the notified users, so a stream per user costs no connection.
+type Listener struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+
+	mu       sync.Mutex
+	watchers map[int64]map[chan struct{}]struct{}
+}
+
+func NewListener(pool *pgxpool.Pool, log *slog.Logger) *Listener {
+	return &Listener{
+		pool:     pool,
+		log:      log,
+		watchers: make(map[int64]map[chan struct{}]struct{}),
+	}
+}
+
+// Watch returns a channel that receives a value when events of userID may have
+// been committed since the last one was received. Wake-ups merge while the
+// watcher is busy, so it must look for every new event when woken. stop
+// releases the channel.
+func (l *Listener) Watch(userID int64) (wake <-chan struct{}, stop func()) {
+	ch := make(chan struct{}, 1)
+	l.mu.Lock()
+	if l.watchers[userID] == nil {
+		l.watchers[userID] = make(map[chan struct{}]struct{})
+	}
+	l.watchers[userID][ch] = struct{}{}
+	l.mu.Unlock()
+
+	return ch, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.watchers[userID], ch)
+		if len(l.watchers[userID]) == 0 {
+			delete(l.watchers, userID)
+		}
+	}
+}
+
+// Run listens until ctx is done, reconnecting after errors. Every watcher is
+// woken when listening (re)starts, as notifications sent in between are lost.
+func (l *Listener) Run(ctx context.Context) {
+	const op = "db/internal/outbox|Listener.Run()"
+	log := l.log.With(slog.String("op", op))
+
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error("outbox listener stopped", slog.String("error", err.Error()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection keeps listening, so it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+NotifyChannel); err != nil {
+		return err
+	}
+	l.wakeAll()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		userID, err := strconv.ParseInt(n.Payload, 10, 64)
+		if err != nil {
+			continue
+		}
+		l.wake(userID)
+	}
+}
+
+func (l *Listener) wake(userID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ch := range l.watchers[userID] {
+		signal(ch)
+	}
+}
+
+func (l *Listener) wakeAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, chs := range l.watchers {
+		for ch := range chs {
+			signal(ch)
+		}
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,165 @@
+// Package outbox implements the transactional outbox: handlers store events in
+// event_outbox in the transaction of their change, and Relay publishes them to
+// Kafka afterwards. An event is published at least once; a relay that stops
+// between the broker's ack and stamping published_at sends it again, so
+// consumers deduplicate on the event id.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Execer is satisfied by pgx pools, connections and transactions.
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// Enqueue stores event for publishing. Pass the transaction of the change, so
+// the event is kept exactly when the change is.
+func Enqueue(ctx context.Context, db Execer, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	query := `INSERT INTO event_outbox (event_id, event_type, event_version, message_key, payload, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err = db.Exec(ctx, query, event.ID, event.Type, event.Version, event.Key(), payload, event.OccurredAt)
+	return err
+}
+
+// record is an unpublished row of event_outbox.
+type record struct {
+	ID        int64
+	EventID   string
+	Type      string
+	Version   int
+	Key       string
+	Payload   []byte
+	CreatedAt time.Time
+	// Attempts counts the failed sends so far.
+	Attempts int
+}
+
+// store is the part of event_outbox the relay works with.
+type store interface {
+	// lead reports whether this relay holds the outbox lock, taking it if
+	// it is free.
+	lead(ctx context.Context) (bool, error)
+	resign(ctx context.Context)
+	pending(ctx context.Context, limit int) ([]record, error)
+	markPublished(ctx context.Context, ids []int64) error
+	markFailed(ctx context.Context, id int64, reason string) error
+	// markDead records the last failure of an event the relay gives up on;
+	// pending and stats leave it out from then on.
+	markDead(ctx context.Context, id int64, reason string) error
+	// stats returns the number of events waiting to be published and the
+	// creation time of the oldest one.
+	stats(ctx context.Context) (int64, *time.Time, error)
+	prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// lockKey is the advisory lock held by the one relay that publishes.
+const lockKey = 0x6f7574626f78 // "outbox"
+
+type pgStore struct {
+	pool *pgxpool.Pool
+	// conn holds the session advisory lock while this relay leads.
+	conn *pgxpool.Conn
+}
+
+func (s *pgStore) lead(ctx context.Context) (bool, error) {
+	if s.conn != nil {
+		if err := s.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		// The lock went away with the session.
+		s.conn.Release()
+		s.conn = nil
+	}
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	var ok bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, lockKey).Scan(&ok); err != nil {
+		conn.Release()
+		return false, err
+	}
+	if !ok {
+		conn.Release()
+		return false, nil
+	}
+	s.conn = conn
+	return true, nil
+}
+
+func (s *pgStore) resign(ctx context.Context) {
+	if s.conn == nil {
+		return
+	}
+	_, _ = s.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, lockKey)
+	s.conn.Release()
+	s.conn = nil
+}
+
+func (s *pgStore) pending(ctx context.Context, limit int) ([]record, error) {
+	query := `SELECT id, event_id, event_type, event_version, message_key, payload, created_at, attempts
+			  FROM event_outbox
+			  WHERE published_at IS NULL AND dead_at IS NULL
+			  ORDER BY id
+			  LIMIT $1`
+	rows, err := s.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []record
+	for rows.Next() {
+		var r record
+		if err := rows.Scan(&r.ID, &r.EventID, &r.Type, &r.Version, &r.Key, &r.Payload, &r.CreatedAt, &r.Attempts); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+func (s *pgStore) markPublished(ctx context.Context, ids []int64) error {
+	query := `UPDATE event_outbox SET published_at = NOW() WHERE id = ANY($1)`
+	_, err := s.pool.Exec(ctx, query, ids)
+	return err
+}
+
+func (s *pgStore) markFailed(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE event_outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`
+	_, err := s.pool.Exec(ctx, query, id, reason)
+	return err
+}
+
+func (s *pgStore) markDead(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE event_outbox SET attempts = attempts + 1, last_error = $2, dead_at = NOW() WHERE id = $1`
+	_, err := s.pool.Exec(ctx, query, id, reason)
+	return err
+}
+
+func (s *pgStore) stats(ctx context.Context) (int64, *time.Time, error) {
+	var n int64
+	var oldest *time.Time
+	query := `SELECT count(*), min(created_at) FROM event_outbox WHERE published_at IS NULL AND dead_at IS NULL`
+	err := s.pool.QueryRow(ctx, query).Scan(&n, &oldest)
+	return n, oldest, err
+}
+
+func (s *pgStore) prune(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM event_outbox WHERE published_at < $1 OR dead_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,265 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Config of the relay. Without brokers the relay does not run and events stay
+// in the outbox until one does.
+type Config struct {
+	Brokers      []string      `yaml:"brokers" env:"OUTBOX_BROKERS" env-separator:","`
+	Topic        string        `yaml:"topic" env:"OUTBOX_TOPIC" env-default:"api-events"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+	// MaxBackoff caps the exponential wait after failed sends.
+	MaxBackoff time.Duration `yaml:"max_backoff" env:"OUTBOX_MAX_BACKOFF" env-default:"1m"`
+	// MaxAttempts is how many sends of an event may fail, the first one
+	// included, before the relay dead-letters it and moves on; 0 never gives up.
+	MaxAttempts int `yaml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS" env-default:"10"`
+	// Published and dead-lettered events are deleted once they are older
+	// than Retention.
+	Retention time.Duration `yaml:"retention" env:"OUTBOX_RETENTION" env-default:"168h"`
+}
+
+const pruneInterval = time.Hour
+
+// NewProducer connects to the brokers of cfg. The producer is idempotent and
+// keeps one request in flight, so retries neither duplicate nor reorder
+// messages within a partition.
+func NewProducer(cfg Config) (sarama.SyncProducer, error) {
+	config := sarama.NewConfig()
+	config.ClientID = "db-service-outbox"
+	config.Net.DialTimeout = 5 * time.Second
+	config.Net.WriteTimeout = 10 * time.Second
+	config.Net.ReadTimeout = 10 * time.Second
+	config.Net.MaxOpenRequests = 1
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Idempotent = true
+	// The relay retries on its own schedule; keep the producer's short.
+	config.Producer.Retry.Max = 3
+	config.Producer.Retry.Backoff = 250 * time.Millisecond
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(cfg.Brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("create producer: %w", err)
+	}
+	return producer, nil
+}
+
+// Metrics of the relay, labelled by nothing since one relay leads at a time.
+type Metrics struct {
+	Pending   prometheus.Gauge
+	Lag       prometheus.Gauge
+	Leader    prometheus.Gauge
+	Published prometheus.Counter
+	Failures  prometheus.Counter
+	Dead      prometheus.Counter
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	f := promauto.With(reg)
+	return &Metrics{
+		Pending: f.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_pending_events",
+			Help: "Events in the outbox that are not published yet.",
+		}),
+		Lag: f.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_lag_seconds",
+			Help: "Age of the oldest unpublished event, 0 when the outbox is drained.",
+		}),
+		Leader: f.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_relay_leader",
+			Help: "1 while this replica holds the outbox lock and publishes.",
+		}),
+		Published: f.NewCounter(prometheus.CounterOpts{
+			Name: "outbox_published_total",
+			Help: "Events published to Kafka.",
+		}),
+		Failures: f.NewCounter(prometheus.CounterOpts{
+			Name: "outbox_publish_failures_total",
+			Help: "Failed attempts to publish an event.",
+		}),
+		Dead: f.NewCounter(prometheus.CounterOpts{
+			Name: "outbox_dead_lettered_total",
+			Help: "Events given up on after too many failed attempts.",
+		}),
+	}
+}
+
+// Relay publishes the outbox to Kafka. Replicas compete for an advisory lock
+// and only the holder publishes, in id order, so the events of a task reach
+// their partition in the order they were written.
+type Relay struct {
+	store    store
+	producer sarama.SyncProducer
+	cfg      Config
+	metrics  *Metrics
+	log      *slog.Logger
+	now      func() time.Time
+}
+
+func NewRelay(pool *pgxpool.Pool, producer sarama.SyncProducer, cfg Config, metrics *Metrics, log *slog.Logger) *Relay {
+	return &Relay{
+		store:    &pgStore{pool: pool},
+		producer: producer,
+		cfg:      cfg,
+		metrics:  metrics,
+		log:      log,
+		now:      time.Now,
+	}
+}
+
+// Run publishes until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	const op = "db/internal/outbox|Run()"
+	log := r.log.With(slog.String("op", op))
+	defer r.store.resign(context.Background())
+
+	var failures int
+	var lastPrune time.Time
+	for {
+		wait := r.cfg.PollInterval
+		leading, err := r.store.lead(ctx)
+		if err != nil {
+			log.Error("failed to take the outbox lock", slog.String("error", err.Error()))
+		}
+		r.metrics.Leader.Set(boolGauge(leading))
+
+		if leading {
+			n, err := r.publish(ctx)
+			switch {
+			case err != nil:
+				failures++
+				wait = backoff(r.cfg.PollInterval, r.cfg.MaxBackoff, failures)
+				log.Error("failed to publish outbox",
+					slog.String("error", err.Error()),
+					slog.Int("failures", failures),
+					slog.Duration("retry_in", wait))
+			case n == r.cfg.BatchSize:
+				// More are waiting.
+				failures = 0
+				wait = 0
+			default:
+				failures = 0
+			}
+			if err := r.observe(ctx); err != nil {
+				log.Error("failed to read outbox stats", slog.String("error", err.Error()))
+			}
+			if r.now().Sub(lastPrune) >= pruneInterval {
+				lastPrune = r.now()
+				n, err := r.store.prune(ctx, r.now().Add(-r.cfg.Retention))
+				if err != nil {
+					log.Error("failed to prune outbox", slog.String("error", err.Error()))
+				} else if n > 0 {
+					log.Info("pruned published events", slog.Int64("count", n))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// publish sends one batch and returns how many events went out. It stops at
+// the first failure, so later events of the same task cannot overtake it,
+// unless that was the last attempt the event gets: it is dead-lettered then
+// and the batch goes on without it.
+func (r *Relay) publish(ctx context.Context) (int, error) {
+	const op = "db/internal/outbox|publish()"
+	records, err := r.store.pending(ctx, r.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("read outbox: %w", err)
+	}
+	var sent []int64
+	var sendErr error
+	for _, rec := range records {
+		msg := &sarama.ProducerMessage{
+			Topic: r.cfg.Topic,
+			Key:   sarama.StringEncoder(rec.Key),
+			Value: sarama.ByteEncoder(rec.Payload),
+			Headers: []sarama.RecordHeader{
+				{Key: []byte("event_id"), Value: []byte(rec.EventID)},
+				{Key: []byte("event_type"), Value: []byte(rec.Type)},
+				{Key: []byte("event_version"), Value: []byte(strconv.Itoa(rec.Version))},
+			},
+		}
+		if _, _, err := r.producer.SendMessage(msg); err != nil {
+			r.metrics.Failures.Inc()
+			if r.cfg.MaxAttempts > 0 && rec.Attempts+1 >= r.cfg.MaxAttempts {
+				if err := r.store.markDead(ctx, rec.ID, err.Error()); err != nil {
+					sendErr = fmt.Errorf("dead-letter event %s: %w", rec.EventID, err)
+					break
+				}
+				r.metrics.Dead.Inc()
+				r.log.Error("gave up on outbox event",
+					slog.String("op", op),
+					slog.String("event_id", rec.EventID),
+					slog.String("event_type", rec.Type),
+					slog.Int("attempts", rec.Attempts+1),
+					slog.String("error", err.Error()))
+				continue
+			}
+			sendErr = fmt.Errorf("send event %s: %w", rec.EventID, err)
+			if err := r.store.markFailed(ctx, rec.ID, err.Error()); err != nil {
+				sendErr = fmt.Errorf("%w (and record the failure: %v)", sendErr, err)
+			}
+			break
+		}
+		sent = append(sent, rec.ID)
+	}
+	if len(sent) > 0 {
+		// Should this fail, the events are sent again: at least once.
+		if err := r.store.markPublished(ctx, sent); err != nil {
+			return 0, fmt.Errorf("mark published: %w", err)
+		}
+		r.metrics.Published.Add(float64(len(sent)))
+	}
+	return len(sent), sendErr
+}
+
+func (r *Relay) observe(ctx context.Context) error {
+	n, oldest, err := r.store.stats(ctx)
+	if err != nil {
+		return err
+	}
+	r.metrics.Pending.Set(float64(n))
+	lag := 0.0
+	if oldest != nil {
+		lag = r.now().Sub(*oldest).Seconds()
+	}
+	r.metrics.Lag.Set(lag)
+	return nil
+}
+
+// backoff doubles base for every failure in a row, up to max.
+func backoff(base, max time.Duration, failures int) time.Duration {
+	d := base
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
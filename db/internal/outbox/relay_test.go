@@ -0,0 +1,249 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeStore struct {
+	records   []record
+	published []int64
+	failed    map[int64]string
+	dead      map[int64]string
+}
+
+func (s *fakeStore) lead(context.Context) (bool, error) { return true, nil }
+func (s *fakeStore) resign(context.Context)             {}
+
+func (s *fakeStore) pending(_ context.Context, limit int) ([]record, error) {
+	var out []record
+	for _, r := range s.records {
+		if _, dead := s.dead[r.ID]; !dead && !s.isPublished(r.ID) && len(out) < limit {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) isPublished(id int64) bool {
+	for _, p := range s.published {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *fakeStore) markPublished(_ context.Context, ids []int64) error {
+	s.published = append(s.published, ids...)
+	return nil
+}
+
+func (s *fakeStore) markFailed(_ context.Context, id int64, reason string) error {
+	if s.failed == nil {
+		s.failed = map[int64]string{}
+	}
+	s.failed[id] = reason
+	for i := range s.records {
+		if s.records[i].ID == id {
+			s.records[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) markDead(ctx context.Context, id int64, reason string) error {
+	if s.dead == nil {
+		s.dead = map[int64]string{}
+	}
+	s.dead[id] = reason
+	return s.markFailed(ctx, id, reason)
+}
+
+func (s *fakeStore) stats(context.Context) (int64, *time.Time, error) {
+	var n int64
+	var oldest *time.Time
+	for _, r := range s.records {
+		if _, dead := s.dead[r.ID]; dead || s.isPublished(r.ID) {
+			continue
+		}
+		n++
+		if oldest == nil {
+			createdAt := r.CreatedAt
+			oldest = &createdAt
+		}
+	}
+	return n, oldest, nil
+}
+
+func (s *fakeStore) prune(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func newTestRelay(t *testing.T, store *fakeStore, producer sarama.SyncProducer) *Relay {
+	t.Helper()
+	return &Relay{
+		store:    store,
+		producer: producer,
+		cfg:      Config{Topic: "api-events", BatchSize: 10},
+		metrics:  NewMetrics(prometheus.NewRegistry()),
+		log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		now:      time.Now,
+	}
+}
+
+func records(n int) []record {
+	var out []record
+	for i := 1; i <= n; i++ {
+		out = append(out, record{
+			ID:        int64(i),
+			EventID:   newID(),
+			Type:      TaskUpdated,
+			Version:   Version,
+			Key:       "7",
+			Payload:   []byte(`{}`),
+			CreatedAt: time.Now().Add(-time.Minute),
+		})
+	}
+	return out
+}
+
+func TestPublish(t *testing.T) {
+	store := &fakeStore{records: records(3)}
+	producer := mocks.NewSyncProducer(t, nil)
+	for range store.records {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			key, _ := msg.Key.Encode()
+			if msg.Topic != "api-events" || string(key) != "7" {
+				return errors.New("unexpected topic or key")
+			}
+			headers := map[string]string{}
+			for _, h := range msg.Headers {
+				headers[string(h.Key)] = string(h.Value)
+			}
+			if headers["event_id"] == "" || headers["event_type"] != TaskUpdated || headers["event_version"] != "1" {
+				return errors.New("unexpected headers")
+			}
+			return nil
+		})
+	}
+	relay := newTestRelay(t, store, producer)
+
+	n, err := relay.publish(context.Background())
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if n != 3 || len(store.published) != 3 {
+		t.Fatalf("published %d (%v), want 3", n, store.published)
+	}
+	if got := testutil.ToFloat64(relay.metrics.Published); got != 3 {
+		t.Errorf("published_total = %v, want 3", got)
+	}
+	if err := relay.observe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(relay.metrics.Pending); got != 0 {
+		t.Errorf("pending = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(relay.metrics.Lag); got != 0 {
+		t.Errorf("lag = %v, want 0", got)
+	}
+}
+
+func TestPublishStopsAtFirstFailure(t *testing.T) {
+	store := &fakeStore{records: records(3)}
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndSucceed()
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	relay := newTestRelay(t, store, producer)
+
+	n, err := relay.publish(context.Background())
+	if !errors.Is(err, sarama.ErrOutOfBrokers) {
+		t.Fatalf("err = %v, want ErrOutOfBrokers", err)
+	}
+	if n != 1 || len(store.published) != 1 || store.published[0] != 1 {
+		t.Fatalf("published %v, want only the first event", store.published)
+	}
+	if _, ok := store.failed[2]; !ok {
+		t.Errorf("failure of event 2 was not recorded: %v", store.failed)
+	}
+	if err := relay.observe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(relay.metrics.Pending); got != 2 {
+		t.Errorf("pending = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(relay.metrics.Lag); got < 60 {
+		t.Errorf("lag = %v, want at least a minute", got)
+	}
+	if got := testutil.ToFloat64(relay.metrics.Failures); got != 1 {
+		t.Errorf("failures_total = %v, want 1", got)
+	}
+}
+
+func TestPublishDeadLettersPermanentFailure(t *testing.T) {
+	store := &fakeStore{records: records(2)}
+	producer := mocks.NewSyncProducer(t, nil)
+	relay := newTestRelay(t, store, producer)
+	relay.cfg.MaxAttempts = 3
+
+	// Event 1 can never be sent; it holds up event 2 until its last attempt.
+	for attempt := 1; attempt < 3; attempt++ {
+		producer.ExpectSendMessageAndFail(sarama.ErrMessageSizeTooLarge)
+		n, err := relay.publish(context.Background())
+		if !errors.Is(err, sarama.ErrMessageSizeTooLarge) || n != 0 {
+			t.Fatalf("attempt %d: publish = %d, %v; want 0 and the send error", attempt, n, err)
+		}
+	}
+	producer.ExpectSendMessageAndFail(sarama.ErrMessageSizeTooLarge)
+	producer.ExpectSendMessageAndSucceed()
+	n, err := relay.publish(context.Background())
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if n != 1 || len(store.published) != 1 || store.published[0] != 2 {
+		t.Fatalf("published %v, want only event 2", store.published)
+	}
+	if _, ok := store.dead[1]; !ok {
+		t.Fatalf("event 1 was not dead-lettered: %v", store.dead)
+	}
+	if got := testutil.ToFloat64(relay.metrics.Dead); got != 1 {
+		t.Errorf("dead_lettered_total = %v, want 1", got)
+	}
+
+	// Nothing is left to send.
+	n, err = relay.publish(context.Background())
+	if err != nil || n != 0 {
+		t.Fatalf("publish = %d, %v; want nothing", n, err)
+	}
+	if err := relay.observe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(relay.metrics.Pending); got != 0 {
+		t.Errorf("pending = %v, want 0", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{10, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(time.Second, time.Minute, tt.failures); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
@@ -1,16 +1,22 @@
 package server
 
 import (
+	"context"
 	"github.com/rail52/myprojects/dbpb"
 	"google.golang.org/grpc"
 	// "google.golang.org/protobuf/types/known/timestamppb"
 	"db/internal/storage/db/postgres"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	// "time"
 	"db/internal/config"
 	"db/internal/handlers"
+	"db/internal/lib/userctx"
+	"db/internal/outbox"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func Run(log *slog.Logger, cfg *config.Config){
@@ -20,7 +26,10 @@ func Run(log *slog.Logger, cfg *config.Config){
 		log.Error("Ошибка при запуске сервера: ", "error", err)
 		os.Exit(52)
 	}
-	opts := []grpc.ServerOption{}
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(userctx.UnaryServerInterceptor(handlers.SystemMethods...)),
+		grpc.StreamInterceptor(userctx.StreamServerInterceptor()),
+	}
 	s := grpc.NewServer(opts...)
 
 	db, err := postgres.NewStorage(cfg.Postgres)
@@ -28,7 +37,32 @@ func Run(log *slog.Logger, cfg *config.Config){
 		log.Error("Ошибка при запуске сервера: ", "error", err)
 		os.Exit(42)
 	}
-	dbpb.RegisterPostgresServer(s, &handlers.Server{DB: db})
+	events := outbox.NewListener(db, log)
+	go events.Run(context.Background())
+	srv := &handlers.Server{DB: db, Events: events, AttachmentQuota: cfg.AttachmentQuota}
+	dbpb.RegisterPostgresServer(s, srv)
+	go srv.RunTrashPurge(context.Background(), log, cfg.TrashRetention, cfg.TrashPurgeInterval)
+	metrics := outbox.NewMetrics(prometheus.DefaultRegisterer)
+	if len(cfg.Outbox.Brokers) > 0 {
+		producer, err := outbox.NewProducer(cfg.Outbox)
+		if err != nil {
+			log.Error("Ошибка при подключении к Kafka: ", "error", err)
+			os.Exit(42)
+		}
+		defer producer.Close()
+		go outbox.NewRelay(db, producer, cfg.Outbox, metrics, log).Run(context.Background())
+	} else {
+		log.Warn("outbox relay is disabled: no brokers configured")
+	}
+	if cfg.MetricsAddress != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.Handler())
+			if err := http.ListenAndServe(cfg.MetricsAddress, mux); err != nil {
+				log.Error("metrics server stopped", "error", err)
+			}
+		}()
+	}
 	log.Info("Сервер запущен на: " + cfg.Address)
 	if err := s.Serve(lis); err != nil {
 		log.Error("Ошибка сервера:", "error", err)
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS task_user_id_idx;
+ALTER TABLE IF EXISTS task DROP COLUMN IF EXISTS user_id;
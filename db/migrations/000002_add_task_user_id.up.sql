@@ -0,0 +1,8 @@
+-- Tasks created before tasks had owners cannot be attributed to anybody, so
+-- they get user 0, which no account has: they stay in the table, hidden from
+-- every user, until an operator assigns them with
+--   UPDATE task SET user_id = <owner> WHERE user_id = 0;
+-- The default only serves that backfill; new tasks must name their owner.
+ALTER TABLE task ADD COLUMN IF NOT EXISTS user_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE task ALTER COLUMN user_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS task_user_id_idx ON task (user_id);
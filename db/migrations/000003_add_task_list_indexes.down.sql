@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS task_user_created_at_idx;
+DROP INDEX IF EXISTS task_user_updated_at_idx;
+DROP INDEX IF EXISTS task_user_title_idx;
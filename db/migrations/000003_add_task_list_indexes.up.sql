@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS task_user_created_at_idx ON task (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS task_user_updated_at_idx ON task (user_id, updated_at, id);
+CREATE INDEX IF NOT EXISTS task_user_title_idx ON task (user_id, title, id);
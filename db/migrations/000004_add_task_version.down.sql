@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS task DROP COLUMN IF EXISTS version;
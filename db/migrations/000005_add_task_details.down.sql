@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS task_tags_idx;
+DROP INDEX IF EXISTS task_user_due_at_idx;
+ALTER TABLE IF EXISTS task
+    DROP COLUMN IF EXISTS due_at,
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS description;
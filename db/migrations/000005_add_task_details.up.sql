@@ -0,0 +1,8 @@
+ALTER TABLE task
+    ADD COLUMN IF NOT EXISTS due_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'normal'
+        CHECK (priority IN ('low', 'normal', 'high', 'urgent')),
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS task_user_due_at_idx ON task (user_id, due_at);
+CREATE INDEX IF NOT EXISTS task_tags_idx ON task USING GIN (tags);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS task_project_id_idx;
+ALTER TABLE IF EXISTS task DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS project;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS project (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    archived_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS project_user_id_idx ON project (user_id);
+
+ALTER TABLE task ADD COLUMN IF NOT EXISTS project_id INT REFERENCES project (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS task_project_id_idx ON task (project_id);
@@ -0,0 +1,3 @@
+-- Subtasks go away together with their parent.
+ALTER TABLE task ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES task (id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS task_parent_id_idx ON task (parent_id);
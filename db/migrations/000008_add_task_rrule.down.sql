@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS task
+    DROP CONSTRAINT IF EXISTS task_rrule_due_at_check,
+    DROP COLUMN IF EXISTS recur_start,
+    DROP COLUMN IF EXISTS rrule;
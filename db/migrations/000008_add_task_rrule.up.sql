@@ -0,0 +1,6 @@
+-- recur_start anchors the series, so COUNT and BYDAY keep counting from the
+-- first occurrence while each completed task spawns the next one.
+ALTER TABLE task
+    ADD COLUMN IF NOT EXISTS rrule TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS recur_start TIMESTAMP,
+    ADD CONSTRAINT task_rrule_due_at_check CHECK (rrule = '' OR due_at IS NOT NULL);
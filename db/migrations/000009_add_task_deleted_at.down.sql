@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS task_deleted_at_idx;
+ALTER TABLE IF EXISTS task DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,2 @@
+ALTER TABLE task ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS task_deleted_at_idx ON task (deleted_at) WHERE deleted_at IS NOT NULL;
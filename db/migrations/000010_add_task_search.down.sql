@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS task_search_idx;
+ALTER TABLE IF EXISTS task DROP COLUMN IF EXISTS search;
@@ -0,0 +1,8 @@
+-- The simple configuration neither stems nor drops stop words, so it works the
+-- same for every language users write in. Title matches rank above content.
+ALTER TABLE task ADD COLUMN IF NOT EXISTS search tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS task_search_idx ON task USING GIN (search);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_comment_revision;
+DROP TABLE IF EXISTS task_comment;
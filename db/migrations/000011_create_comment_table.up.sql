@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS task_comment (
+    id SERIAL PRIMARY KEY,
+    task_id INT NOT NULL REFERENCES task (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    body TEXT NOT NULL CHECK (body <> ''),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS task_comment_task_id_idx ON task_comment (task_id, id);
+
+-- Previous bodies of edited comments, oldest first.
+CREATE TABLE IF NOT EXISTS task_comment_revision (
+    id SERIAL PRIMARY KEY,
+    comment_id INT NOT NULL REFERENCES task_comment (id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS task_comment_revision_comment_id_idx ON task_comment_revision (comment_id, id);
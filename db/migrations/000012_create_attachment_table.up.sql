@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS task_attachment (
+    id SERIAL PRIMARY KEY,
+    task_id INT NOT NULL REFERENCES task (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    sha256 CHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS task_attachment_task_id_idx ON task_attachment (task_id, id);
+CREATE INDEX IF NOT EXISTS task_attachment_user_id_idx ON task_attachment (user_id);
@@ -0,0 +1,26 @@
+-- One row per task mutation. changes holds {"field": {"before": .., "after": ..}}
+-- for the fields that changed, snapshot the revertible fields after the change.
+CREATE TABLE IF NOT EXISTS task_history (
+    id BIGSERIAL PRIMARY KEY,
+    task_id INT NOT NULL REFERENCES task (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(32) NOT NULL,
+    version BIGINT NOT NULL,
+    changes JSONB NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS task_history_task_id_idx ON task_history (task_id, id);
+
+-- History is append-only; rows only go away together with their task.
+CREATE OR REPLACE FUNCTION task_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'task_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_history_append_only ON task_history;
+CREATE TRIGGER task_history_append_only
+    BEFORE UPDATE ON task_history
+    FOR EACH ROW EXECUTE FUNCTION task_history_append_only();
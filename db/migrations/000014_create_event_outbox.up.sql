@@ -0,0 +1,17 @@
+-- Events written in the transaction of the change they describe. The relay in
+-- db/internal/outbox publishes them to Kafka in id order and stamps
+-- published_at; published rows are pruned after a retention period.
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    event_type VARCHAR(64) NOT NULL,
+    event_version SMALLINT NOT NULL,
+    message_key VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS event_outbox_pending_idx ON event_outbox (id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS event_outbox_published_at_idx ON event_outbox (published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,13 @@
+-- Daily counters of the events each user caused, kept up to date by the
+-- analytics sink of the todo-app event consumer. completion_seconds sums the
+-- created-to-done time of the task.done events, so the average lead time of a
+-- day is completion_seconds / count of its task.done row.
+CREATE TABLE IF NOT EXISTS task_event_stats (
+    user_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    completion_seconds BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, day, event_type)
+);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS event_outbox_notify ON event_outbox;
+DROP FUNCTION IF EXISTS notify_event_outbox();
+DROP INDEX IF EXISTS event_outbox_user_idx;
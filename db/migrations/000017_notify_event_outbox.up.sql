@@ -0,0 +1,18 @@
+-- WatchTasks streams a user's events from event_outbox. The trigger wakes the
+-- streams of the user when the events are committed: NOTIFY is delivered at
+-- commit, with the user id as payload, and repeated ones are merged.
+CREATE INDEX IF NOT EXISTS event_outbox_user_idx ON event_outbox ((payload->>'user_id'), id);
+
+CREATE OR REPLACE FUNCTION notify_event_outbox() RETURNS trigger AS $$
+BEGIN
+    IF NEW.payload ? 'user_id' THEN
+        PERFORM pg_notify('event_outbox', NEW.payload->>'user_id');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS event_outbox_notify ON event_outbox;
+CREATE TRIGGER event_outbox_notify
+    AFTER INSERT ON event_outbox
+    FOR EACH ROW EXECUTE FUNCTION notify_event_outbox();
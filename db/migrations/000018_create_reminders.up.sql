@@ -0,0 +1,43 @@
+-- Reminders fire offset_minutes before the due date of their task. The fire
+-- time is not stored: it follows the task's due_at, and fired_for remembers
+-- the due date a reminder was sent for, so moving the due date arms it again.
+-- The scheduler in todo-app leases due rows through next_attempt_at, and
+-- sent_channels collects the channels that got the reminder for attempt_for,
+-- so a retry only goes to the others. Like task.due_at, fired_for and
+-- attempt_for are UTC without a time zone.
+CREATE TABLE IF NOT EXISTS task_reminder (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES task (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    offset_minutes INT NOT NULL CHECK (offset_minutes >= 0),
+    channels TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    fired_for TIMESTAMP,
+    attempt_for TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    sent_channels TEXT[] NOT NULL DEFAULT '{}',
+    next_attempt_at TIMESTAMPTZ,
+    UNIQUE (task_id, offset_minutes)
+);
+
+-- The in-app inbox. dedup_key keeps a retried reminder from showing twice.
+CREATE TABLE IF NOT EXISTS notification (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    task_id BIGINT REFERENCES task (id) ON DELETE SET NULL,
+    dedup_key VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMPTZ,
+    UNIQUE (user_id, dedup_key)
+);
+CREATE INDEX IF NOT EXISTS notification_user_id_idx ON notification (user_id, id);
+CREATE INDEX IF NOT EXISTS notification_unread_idx ON notification (user_id, id) WHERE read_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id BIGINT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,9 @@
+-- The token of a user's calendar feed, GET /tasks/calendar.ics. A user has at
+-- most one; creating another replaces it, deleting it revokes the feed. Only
+-- the SHA-256 of the token is stored.
+CREATE TABLE IF NOT EXISTS calendar_token (
+    user_id BIGINT PRIMARY KEY,
+    token_hash BYTEA NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ
+);
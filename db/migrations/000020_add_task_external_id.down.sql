@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS task_user_id_external_id_idx;
+ALTER TABLE task DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,5 @@
+-- external_id is the id a task had in the tool it was imported from. Imports
+-- skip rows whose external_id a task of the user already has, so importing
+-- the same file twice creates each task once.
+ALTER TABLE task ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS task_user_id_external_id_idx ON task (user_id, external_id) WHERE external_id IS NOT NULL;
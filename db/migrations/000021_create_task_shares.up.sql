@@ -0,0 +1,18 @@
+-- A task_share grants a user access to a task, with its subtasks, or to a
+-- project, with its tasks. The owner of the task or project has every right;
+-- editors change tasks, comments and attachments; viewers only read.
+CREATE TABLE IF NOT EXISTS task_share (
+    id BIGSERIAL PRIMARY KEY,
+    task_id INT REFERENCES task (id) ON DELETE CASCADE,
+    project_id INT REFERENCES project (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    -- The address the user was invited by, kept for display.
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    role VARCHAR(16) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    created_by BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((task_id IS NULL) <> (project_id IS NULL))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS task_share_task_id_user_id_idx ON task_share (task_id, user_id) WHERE task_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS task_share_project_id_user_id_idx ON task_share (project_id, user_id) WHERE project_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS task_share_user_id_idx ON task_share (user_id);
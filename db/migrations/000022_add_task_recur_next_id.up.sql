@@ -0,0 +1,5 @@
+-- recur_next_id is the occurrence a recurring task spawned when it was first
+-- completed. Completing the task again after undoing it leaves the series
+-- alone instead of spawning a duplicate; if that occurrence is deleted for
+-- good, the next completion spawns a fresh one.
+ALTER TABLE task ADD COLUMN IF NOT EXISTS recur_next_id BIGINT REFERENCES task (id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS task_attachment_tombstone ON task_attachment;
+DROP FUNCTION IF EXISTS record_attachment_tombstone();
+DROP TABLE IF EXISTS attachment_tombstone;
@@ -0,0 +1,26 @@
+-- The bytes of attachments are kept in todo-app's blob store, which does not
+-- see rows go: attachments are deleted one by one, but also with their task
+-- when it is deleted for good or purged from the trash, and with its project.
+-- The trigger leaves a tombstone for every deleted row, whatever deleted it.
+-- todo-app's sweeper leases them with ClaimBlobDeletions, removes the blobs
+-- and drops the tombstones with CompleteBlobDeletions.
+CREATE TABLE IF NOT EXISTS attachment_tombstone (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL,
+    attachment_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- Set while a sweeper holds the tombstone; it is free again afterwards.
+    leased_until TIMESTAMP
+);
+
+CREATE OR REPLACE FUNCTION record_attachment_tombstone() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO attachment_tombstone (task_id, attachment_id) VALUES (OLD.task_id, OLD.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_attachment_tombstone ON task_attachment;
+CREATE TRIGGER task_attachment_tombstone
+    AFTER DELETE ON task_attachment
+    FOR EACH ROW EXECUTE FUNCTION record_attachment_tombstone();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS event_outbox_dead_at_idx;
+DROP INDEX IF EXISTS event_outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS event_outbox_pending_idx ON event_outbox (id) WHERE published_at IS NULL;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS dead_at;
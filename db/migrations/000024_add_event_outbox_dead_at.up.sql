@@ -0,0 +1,7 @@
+-- dead_at marks events the relay gave up on after outbox.max_attempts failed
+-- sends. They are skipped from then on, so they no longer hold up the events
+-- after them, and kept for inspection until the retention period is over.
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+DROP INDEX IF EXISTS event_outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS event_outbox_pending_idx ON event_outbox (id) WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS event_outbox_dead_at_idx ON event_outbox (dead_at) WHERE dead_at IS NOT NULL;
@@ -0,0 +1,15 @@
+CREATE OR REPLACE FUNCTION notify_event_outbox() RETURNS trigger AS $$
+BEGIN
+    IF NEW.payload ? 'user_id' THEN
+        PERFORM pg_notify('event_outbox', NEW.payload->>'user_id');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS event_outbox_audience ON event_outbox;
+DROP FUNCTION IF EXISTS event_outbox_audience();
+CREATE INDEX IF NOT EXISTS event_outbox_user_idx ON event_outbox ((payload->>'user_id'), id);
+DROP INDEX IF EXISTS event_outbox_audience_idx;
+DROP INDEX IF EXISTS event_outbox_tx_id_idx;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS audience;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS tx_id;
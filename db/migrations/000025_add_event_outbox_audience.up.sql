@@ -0,0 +1,51 @@
+-- WatchTasks streams events by the transaction that wrote them, tx_id, and
+-- only once every transaction before it has ended: ids are taken before
+-- commit, so a lower id can become visible after a higher one. audience holds
+-- the users the event is for: the owner and everybody the task, an ancestor
+-- or their project is shared with when the event is stored. Rows stored before
+-- this migration share one tx_id and have only their owner as audience.
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS tx_id xid8 NOT NULL DEFAULT pg_current_xact_id();
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS audience BIGINT[] NOT NULL DEFAULT '{}';
+UPDATE event_outbox SET audience = ARRAY[(payload->>'user_id')::bigint] WHERE payload ? 'user_id';
+CREATE INDEX IF NOT EXISTS event_outbox_tx_id_idx ON event_outbox (tx_id, id);
+CREATE INDEX IF NOT EXISTS event_outbox_audience_idx ON event_outbox USING GIN (audience);
+DROP INDEX IF EXISTS event_outbox_user_idx;
+
+-- Deleted tasks are gone when their event is stored, so their ancestors are
+-- found from the parent_id and project_id of the snapshot before.
+CREATE OR REPLACE FUNCTION event_outbox_audience() RETURNS trigger AS $$
+DECLARE
+    event_task BIGINT := (NEW.payload->>'task_id')::bigint;
+    event_parent BIGINT := (NEW.payload->'before'->>'parent_id')::bigint;
+    event_project BIGINT := COALESCE(NEW.payload->'data'->>'project_id', NEW.payload->'before'->>'project_id')::bigint;
+BEGIN
+    IF NOT NEW.payload ? 'user_id' THEN
+        RETURN NEW;
+    END IF;
+    NEW.audience := ARRAY[(NEW.payload->>'user_id')::bigint] || ARRAY(
+        WITH RECURSIVE chain AS (
+            SELECT t.id, t.parent_id, t.project_id FROM task t WHERE t.id IN (event_task, event_parent)
+            UNION
+            SELECT t.id, t.parent_id, t.project_id FROM task t JOIN chain ON t.id = chain.parent_id
+        )
+        SELECT DISTINCT s.user_id FROM task_share s
+        WHERE s.task_id IN (SELECT chain.id FROM chain)
+           OR s.project_id IN (SELECT chain.project_id FROM chain)
+           OR s.project_id = event_project
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS event_outbox_audience ON event_outbox;
+CREATE TRIGGER event_outbox_audience
+    BEFORE INSERT ON event_outbox
+    FOR EACH ROW EXECUTE FUNCTION event_outbox_audience();
+
+-- Wake the streams of the whole audience, not only the owner's.
+CREATE OR REPLACE FUNCTION notify_event_outbox() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('event_outbox', member::text) FROM unnest(NEW.audience) AS member;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -9,10 +9,24 @@ import (
 	"todo-app/internal/config"
 
 	"errors"
+	"fmt"
 	"todo-app/internal/kafka/producer"
+	"todo-app/internal/kafka/consumer"
+	"todo-app/internal/sinks/analytics"
+	"todo-app/internal/sinks/webhook"
+	mwAuth "todo-app/internal/middleware/auth"
 	"todo-app/internal/routes"
+	"todo-app/internal/handlers/attachments"
+	"todo-app/internal/live"
+	"todo-app/internal/reminders"
+	"todo-app/internal/storage/blob"
+	"todo-app/internal/storage/blob/fs"
+	"todo-app/internal/storage/blob/s3"
+	"todo-app/internal/storage/blob/sweeper"
+	"todo-app/internal/users"
 
 	"os/signal"
+	"sync"
 	"syscall"
 	"github.com/rail52/myprojects/dbpb"
 	"google.golang.org/grpc"
@@ -44,14 +58,43 @@ func main() {
 	defer kafkaProducer.Close()
 	
 	// grpc client
-	conn, err := grpc.NewClient(cfg.DBServiceAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(cfg.DBServiceAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(mwAuth.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(mwAuth.StreamClientInterceptor()),
+	)
 	if err != nil {
 		log.Error("did not connect:", "error", err)
 	}
 	defer conn.Close()
 	client := dbpb.NewPostgresClient(conn)
+	// event consumer
+	if cfg.Consumer.Enabled {
+		stopConsumer, err := startConsumer(cfg, client, log)
+		if err != nil {
+			log.Error("failed to start event consumer", slog.String("error", err.Error()))
+		} else {
+			defer stopConsumer()
+		}
+	}
+	// reminders
+	if cfg.Reminders.Enabled {
+		stopReminders := startReminders(cfg, client, log)
+		defer stopReminders()
+	}
+	// attachments
+	blobs, err := setupBlobStore(cfg.Attachments)
+	if err != nil {
+		log.Error("failed to initialize attachment store", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	limits := attachments.Limits{MaxSize: cfg.Attachments.MaxSize}
+	stopSweeper := startSweeper(cfg, client, blobs, log)
+	defer stopSweeper()
 	// router
-	router := routes.NewRouter(log, client, kafkaProducer)
+	hub := live.New(client, cfg.Live, log)
+	finder := users.New(cfg.AuthServiceAddress, cfg.Timeout)
+	router := routes.NewRouter(log, client, kafkaProducer, blobs, limits, hub, finder)
 	// server
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
@@ -63,6 +106,8 @@ func main() {
 		WriteTimeout: cfg.Timeout,
 		IdleTimeout:  cfg.IdleTimeout,
 	}
+	// Event streams never finish by themselves; end them so Shutdown can.
+	srv.RegisterOnShutdown(hub.Close)
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -86,6 +131,113 @@ func main() {
 	log.Info("server stopped")
 }
 
+// startConsumer runs the event consumer and its sinks until the returned
+// function is called, which waits for them to finish.
+func startConsumer(cfg *config.Config, client dbpb.PostgresClient, log *slog.Logger) (func(), error) {
+	group, err := consumer.NewGroup(cfg.Brokers, cfg.Consumer)
+	if err != nil {
+		return nil, err
+	}
+	producer, err := consumer.NewProducer(cfg.Brokers)
+	if err != nil {
+		group.Close()
+		return nil, err
+	}
+
+	stats := analytics.New(client, cfg.Analytics, log)
+	hooks := webhook.New(client, cfg.Webhooks, log)
+	sinks := []consumer.Sink{stats, hooks}
+	if cfg.Reminders.Enabled {
+		sinks = append(sinks, reminders.NewSink(client, cfg.Reminders.Default))
+	}
+	c := consumer.New(cfg.Topic, cfg.Consumer, producer, log, sinks...)
+
+	consumerCtx, stopConsuming := context.WithCancel(context.Background())
+	statsCtx, stopStats := context.WithCancel(context.Background())
+	var consuming, counting sync.WaitGroup
+	consuming.Add(1)
+	go func() {
+		defer consuming.Done()
+		c.Run(consumerCtx, group)
+	}()
+	consuming.Add(1)
+	go func() {
+		defer consuming.Done()
+		hooks.Run(consumerCtx)
+	}()
+	counting.Add(1)
+	go func() {
+		defer counting.Done()
+		stats.Run(statsCtx)
+	}()
+	log.Info("event consumer started", slog.String("group", cfg.Consumer.Group), slog.Any("topics", c.Topics()))
+
+	return func() {
+		stopConsuming()
+		consuming.Wait()
+		// Flush the counts only once nothing adds to them.
+		stopStats()
+		counting.Wait()
+		group.Close()
+		producer.Close()
+	}, nil
+}
+
+// startReminders runs the reminder scheduler until the returned function is
+// called, which waits for it to finish. Email reminders need an SMTP host.
+func startReminders(cfg *config.Config, client dbpb.PostgresClient, log *slog.Logger) func() {
+	channels := []reminders.Channel{
+		reminders.NewInbox(client),
+		reminders.NewWebhook(webhook.New(client, cfg.Webhooks, log)),
+	}
+	if cfg.Reminders.SMTP.Host != "" {
+		channels = append(channels, reminders.NewEmail(cfg.Reminders.SMTP))
+	}
+	scheduler := reminders.New(client, cfg.Reminders, log, channels...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler.Run(ctx)
+	}()
+	log.Info("reminder scheduler started", slog.Bool("email", cfg.Reminders.SMTP.Host != ""))
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// startSweeper removes the blobs of deleted attachments until the returned
+// function is called, which waits for it to finish.
+func startSweeper(cfg *config.Config, client dbpb.PostgresClient, blobs blob.Store, log *slog.Logger) func() {
+	s := sweeper.New(client, blobs, cfg.Attachments.Sweep, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func setupBlobStore(cfg config.Attachments) (blob.Store, error) {
+	switch cfg.Store {
+	case "local":
+		return fs.New(cfg.Local)
+	case "s3":
+		return s3.New(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown attachment store %q", cfg.Store)
+	}
+}
+
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 
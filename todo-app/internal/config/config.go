@@ -5,6 +5,14 @@ import (
 	"log"
 	"os"
 	"time"
+	"todo-app/internal/kafka/consumer"
+	"todo-app/internal/live"
+	"todo-app/internal/reminders"
+	"todo-app/internal/sinks/analytics"
+	"todo-app/internal/sinks/webhook"
+	"todo-app/internal/storage/blob/fs"
+	"todo-app/internal/storage/blob/s3"
+	"todo-app/internal/storage/blob/sweeper"
 )
 
 type Config struct {
@@ -14,6 +22,18 @@ type Config struct {
 	Timeout          time.Duration `yaml:"timeout"`
 	IdleTimeout      time.Duration `yaml:"idle_timeout"`
 	Kafka            `yaml:"kafka"`
+	Attachments      Attachments `yaml:"attachments"`
+	// Consumer reads kafka_topic back and feeds the sinks configured below.
+	Consumer  consumer.Config  `yaml:"consumer"`
+	Analytics analytics.Config `yaml:"analytics"`
+	Webhooks  webhook.Config   `yaml:"webhooks"`
+	// Live tunes GET /tasks/events.
+	Live      live.Config      `yaml:"live"`
+	Reminders reminders.Config `yaml:"reminders"`
+
+	// AuthServiceAddress is where invitees of shared tasks are looked up by
+	// email.
+	AuthServiceAddress string `yaml:"auth-service_address" env-required:"true"`
 }
 
 type Kafka struct {
@@ -21,6 +41,17 @@ type Kafka struct {
 	Topic   string   `yaml:"kafka_topic"`
 }
 
+// Attachments selects the blob store for attachment bytes ("local" or "s3")
+// and limits their size per file; 0 disables it. The quota per user is set in
+// the db service. Sweep removes the bytes of deleted attachments.
+type Attachments struct {
+	Store   string         `yaml:"store" env:"ATTACHMENTS_STORE" env-default:"local"`
+	MaxSize int64          `yaml:"max_size" env:"ATTACHMENTS_MAX_SIZE" env-default:"26214400"`
+	Local   fs.Config      `yaml:"local"`
+	S3      s3.Config      `yaml:"s3"`
+	Sweep   sweeper.Config `yaml:"sweep"`
+}
+
 func MustLoad() *Config {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	mwAuth "todo-app/internal/middleware/auth"
+	"todo-app/internal/token"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestNew(t *testing.T) {
+	var ctx context.Context
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/tasks/7", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	ctx = context.WithValue(ctx, mwAuth.UserKey, &token.Claims{UserID: 42})
+
+	before := &dbpb.Task{Id: 7, Title: "old", Version: 1}
+	after := &dbpb.Task{Id: 7, Title: "new", Version: 2, DueAt: timestamppb.New(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))}
+	event := New(ctx, TaskUpdated).WithTask(before, after).With("version", "2")
+
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), event.ID)
+	assert.Equal(t, Version, event.Version)
+	assert.Equal(t, TaskUpdated, event.Type)
+	assert.Equal(t, int64(42), event.UserID)
+	assert.Equal(t, "req-1", event.RequestID)
+	assert.Equal(t, int64(7), event.TaskID)
+	assert.Equal(t, "7", event.Key())
+	assert.Equal(t, "old", event.Before.Title)
+	assert.Equal(t, "new", event.After.Title)
+	assert.Equal(t, "2025-01-02T00:00:00Z", event.After.DueAt.Format(time.RFC3339))
+	assert.Equal(t, map[string]string{"version": "2"}, event.Data)
+
+	assert.Equal(t, ProjectCreated, New(context.Background(), ProjectCreated).Key())
+	assert.Nil(t, New(context.Background(), TaskDeleted).WithTask(before, nil).After)
+}
+
+// jsonFields lists the JSON names of the fields of a struct type.
+func jsonFields(typ reflect.Type) []string {
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func keys(m map[string]json.RawMessage) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestSchemaMatchesEvent(t *testing.T) {
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       struct {
+			Task struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"task"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(Schema, &schema))
+
+	assert.Equal(t, jsonFields(reflect.TypeOf(Event{})), keys(schema.Properties))
+	assert.Equal(t, jsonFields(reflect.TypeOf(Task{})), keys(schema.Defs.Task.Properties))
+
+	var typeProp struct {
+		Enum []string `json:"enum"`
+	}
+	require.NoError(t, json.Unmarshal(schema.Properties["type"], &typeProp))
+	assert.ElementsMatch(t, []string{
+		TaskCreated, TaskUpdated, TaskDone, TaskTrashed, TaskRestored, TaskDeleted, TaskReverted, TaskFetched, TasksListed,
+		CommentAdded, CommentUpdated, CommentDeleted,
+		AttachmentAdded, AttachmentDeleted,
+		ProjectCreated, ProjectUpdated, ProjectArchived, ProjectDeleted,
+		ReminderDue,
+	}, typeProp.Enum)
+}
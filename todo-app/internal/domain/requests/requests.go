@@ -1,17 +1,158 @@
 package requests
 
-type ApiRequest struct {
-	Action      string            `json:"method"`  // HTTP method (GET, POST, etc.)
-	RequestParams map[string]string `json:"query_params,omitempty"`      // Optional query parameters
-}
+import (
+	"encoding/json"
+	"time"
+)
+
+// Validator rules for task fields that merge patches check one by one. The
+// struct tags below repeat them, since tags must be string literals.
+const (
+	PriorityTag    = "oneof=low normal high urgent"
+	TagsTag        = "max=20,dive,min=1,max=50"
+	DescriptionTag = "max=10000"
+)
 
 type CreateTaskRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=low normal high urgent"`
+	Tags        []string   `json:"tags,omitempty" validate:"max=20,dive,min=1,max=50"`
+	Description string     `json:"description,omitempty" validate:"max=10000"`
+	ProjectID   int64      `json:"project_id,omitempty" validate:"min=0"`
+	RRule       string     `json:"rrule,omitempty" validate:"max=255"`
 }
 
+// UpdateTaskRequest is the body of PUT /tasks/{id}, which replaces the whole
+// task: title, content and is_done are required, omitted optional fields are
+// reset. Pointers tell a missing field from a zero one.
 type UpdateTaskRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	IsDone  bool   `json:"is_done,omitempty"`
-}
\ No newline at end of file
+	Title       *string    `json:"title" validate:"required,min=1,max=255"`
+	Content     *string    `json:"content" validate:"required,max=255"`
+	IsDone      *bool      `json:"is_done" validate:"required"`
+	DueAt       *time.Time `json:"due_at"`
+	Priority    string     `json:"priority" validate:"omitempty,oneof=low normal high urgent"`
+	Tags        []string   `json:"tags" validate:"max=20,dive,min=1,max=50"`
+	Description string     `json:"description" validate:"max=10000"`
+	ProjectID   int64      `json:"project_id" validate:"min=0"`
+	RRule       string     `json:"rrule" validate:"max=255"`
+}
+
+// Nullable records whether a JSON field was present and whether it was null,
+// which RFC 7396 merge patches need to tell "keep" from "remove".
+type Nullable[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &n.Value)
+}
+
+// PatchTaskRequest is an application/merge-patch+json body of PATCH /tasks/{id}.
+type PatchTaskRequest struct {
+	Title       Nullable[string]    `json:"title"`
+	Content     Nullable[string]    `json:"content"`
+	IsDone      Nullable[bool]      `json:"is_done"`
+	DueAt       Nullable[time.Time] `json:"due_at"`
+	Priority    Nullable[string]    `json:"priority"`
+	Tags        Nullable[[]string]  `json:"tags"`
+	Description Nullable[string]    `json:"description"`
+	ProjectID   Nullable[int64]     `json:"project_id"`
+	RRule       Nullable[string]    `json:"rrule"`
+}
+
+type CreateProjectRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description"`
+}
+
+// UpdateProjectRequest is the body of PATCH /projects/{id}; missing fields are kept.
+type UpdateProjectRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Description *string `json:"description"`
+	Archived    *bool   `json:"archived"`
+}
+// RevertTaskRequest is the body of POST /tasks/{id}/revert; Version is the
+// revision from GET /tasks/{id}/history to go back to.
+type RevertTaskRequest struct {
+	Version int64 `json:"version" validate:"required,min=1"`
+}
+// CommentRequest is the body of POST /tasks/{id}/comments and of
+// PATCH /tasks/{id}/comments/{cid}.
+type CommentRequest struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
+// BatchRequest is the body of POST /tasks:batch. Mode "atomic" (the default)
+// rolls back every operation when one fails, "partial" reports failures per
+// operation and keeps the rest.
+type BatchRequest struct {
+	Mode       string           `json:"mode" validate:"omitempty,oneof=atomic partial"`
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+// BatchOperation is one entry of a batch. Op is create, update, mark_done or
+// delete; Task is the body of a create and Patch the merge patch of an update.
+type BatchOperation struct {
+	Op        string             `json:"op" validate:"oneof=create update mark_done delete"`
+	ID        int64              `json:"id"`
+	Version   *int64             `json:"version"`
+	Task      *CreateTaskRequest `json:"task"`
+	Patch     *PatchTaskRequest  `json:"patch"`
+	Cascade   bool               `json:"cascade"`
+	Permanent bool               `json:"permanent"`
+}
+// CreateWebhookRequest is the body of POST /webhooks. An empty EventTypes
+// subscribes to every event type.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes  []string `json:"event_types" validate:"max=20,dive,min=1,max=64"`
+	Description string   `json:"description" validate:"max=255"`
+}
+
+// UpdateWebhookRequest is the body of PATCH /webhooks/{id}; missing fields are
+// kept. RotateSecret replaces the signing secret and returns the new one.
+type UpdateWebhookRequest struct {
+	URL          *string   `json:"url" validate:"omitempty,url,max=2048"`
+	EventTypes   *[]string `json:"event_types" validate:"omitempty,max=20,dive,min=1,max=64"`
+	Description  *string   `json:"description" validate:"omitempty,max=255"`
+	Active       *bool     `json:"active"`
+	RotateSecret bool      `json:"rotate_secret"`
+}
+
+// CreateReminderRequest is the body of POST /tasks/{id}/reminders: remind
+// OffsetMinutes before the task's due date through Channels, the inbox when
+// empty.
+type CreateReminderRequest struct {
+	OffsetMinutes int32    `json:"offset_minutes" validate:"min=0,max=525600"`
+	Channels      []string `json:"channels" validate:"max=3,dive,oneof=email webhook in_app"`
+}
+
+// MarkNotificationsReadRequest is the body of POST /notifications/read: the
+// notifications to mark as read, or all of them.
+type MarkNotificationsReadRequest struct {
+	IDs []int64 `json:"ids" validate:"max=100"`
+	All bool    `json:"all"`
+}
+
+// NotificationSettingsRequest is the body of PUT /notifications/settings. An
+// empty Email stops email reminders.
+type NotificationSettingsRequest struct {
+	Email string `json:"email" validate:"omitempty,email,max=255"`
+}
+
+// ShareRequest is the body of POST /tasks/{id}/shares and
+// POST /projects/{id}/shares: who to share with, by email or user id, and
+// their role.
+type ShareRequest struct {
+	Email  string `json:"email" validate:"omitempty,email,max=255"`
+	UserID int64  `json:"user_id" validate:"min=0"`
+	Role   string `json:"role" validate:"oneof=editor viewer"`
+}
@@ -0,0 +1,357 @@
+package attachments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/storage/blob"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// multipartOverhead is how much larger than the file an upload body may be,
+// for the part headers and boundaries.
+const multipartOverhead = 1 << 20
+
+const maxFilename = 255
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AttachmentStore
+type AttachmentStore interface {
+	CreateAttachment(ctx context.Context, in *dbpb.CreateAttachmentRequest, opts ...grpc.CallOption) (*dbpb.Attachment, error)
+	GetAttachment(ctx context.Context, in *dbpb.GetAttachmentRequest, opts ...grpc.CallOption) (*dbpb.Attachment, error)
+	ListAttachments(ctx context.Context, in *dbpb.ListAttachmentsRequest, opts ...grpc.CallOption) (*dbpb.ListAttachmentsResponse, error)
+	DeleteAttachment(ctx context.Context, in *dbpb.DeleteAttachmentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+// Limits bound uploads: MaxSize per file, zero for no limit. The quota for all
+// attachments of a user is the db service's.
+type Limits struct {
+	MaxSize int64
+}
+
+func blobKey(a *dbpb.Attachment) string {
+	return blob.AttachmentKey(a.GetTaskId(), a.GetId())
+}
+
+func urlID(w http.ResponseWriter, r *http.Request, log *slog.Logger, param, Err string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id < 1 {
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return 0, false
+	}
+	return id, true
+}
+
+func taskID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return urlID(w, r, log, "id", "Invalid task ID")
+}
+
+func attachmentID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return urlID(w, r, log, "aid", "Invalid attachment ID")
+}
+
+// upload is a file received from a multipart form, spooled to a temporary
+// file so its size and hash are known before it is stored.
+type upload struct {
+	file        *os.File
+	filename    string
+	contentType string
+	size        int64
+	sha256      string
+}
+
+func (u *upload) Close() {
+	u.file.Close()
+	os.Remove(u.file.Name())
+}
+
+var (
+	errNoFile   = errors.New(`form field "file" is required`)
+	errTooLarge = errors.New("file is too large")
+)
+
+// readUpload reads the "file" part of a multipart/form-data body.
+func readUpload(r *http.Request, maxSize int64) (*upload, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, errors.New("request must be multipart/form-data")
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errNoFile
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		defer part.Close()
+
+		u := &upload{filename: cleanFilename(part.FileName())}
+		if u.filename == "" {
+			return nil, errors.New("file needs a filename")
+		}
+		u.file, err = os.CreateTemp("", "attachment-*")
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		src := io.Reader(part)
+		if maxSize > 0 {
+			src = io.LimitReader(part, maxSize+1)
+		}
+		u.size, err = io.Copy(io.MultiWriter(u.file, h), src)
+		if err != nil {
+			u.Close()
+			return nil, err
+		}
+		if maxSize > 0 && u.size > maxSize {
+			u.Close()
+			return nil, errTooLarge
+		}
+		u.sha256 = hex.EncodeToString(h.Sum(nil))
+		if u.contentType, err = contentType(part.Header.Get("Content-Type"), u.file); err != nil {
+			u.Close()
+			return nil, err
+		}
+		if _, err := u.file.Seek(0, io.SeekStart); err != nil {
+			u.Close()
+			return nil, err
+		}
+		return u, nil
+	}
+}
+
+// cleanFilename keeps only the base name of what the client sent.
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" {
+		return ""
+	}
+	if runes := []rune(name); len(runes) > maxFilename {
+		name = string(runes[:maxFilename])
+	}
+	return name
+}
+
+// contentType trusts the declared type unless it is missing or generic, in
+// which case the content is sniffed.
+func contentType(declared string, f *os.File) (string, error) {
+	if declared != "" && declared != "application/octet-stream" {
+		if mediaType, params, err := mime.ParseMediaType(declared); err == nil {
+			return mime.FormatMediaType(mediaType, params), nil
+		}
+	}
+	head := make([]byte, 512)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// UploadAttachment stores the "file" field of a multipart upload on the task
+// in the URL.
+func UploadAttachment(log *slog.Logger, storage AttachmentStore, blobs blob.Store, limits Limits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/attachments.go|UploadAttachment()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		rc := http.NewResponseController(w)
+		// Large files outlive the server's ReadTimeout on slow links, and the
+		// WriteTimeout runs from the end of the headers too.
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+		if limits.MaxSize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, limits.MaxSize+multipartOverhead)
+		}
+
+		u, err := readUpload(r, limits.MaxSize)
+		var maxBytesErr *http.MaxBytesError
+		if errors.Is(err, errTooLarge) || errors.As(err, &maxBytesErr) {
+			Err := fmt.Sprintf("file must be up to %d bytes", limits.MaxSize)
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusRequestEntityTooLarge, Err)
+			return
+		}
+		if err != nil {
+			log.Info("invalid upload", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		defer u.Close()
+
+		attachment, err := storage.CreateAttachment(r.Context(), &dbpb.CreateAttachmentRequest{
+			TaskId:      id,
+			Filename:    u.filename,
+			ContentType: u.contentType,
+			Size:        u.size,
+			Sha256:      u.sha256,
+		})
+		if status.Code(err) == codes.ResourceExhausted {
+			log.Info("attachment quota exceeded", slog.Int64("task_id", id))
+			httperr.Render(w, r, http.StatusRequestEntityTooLarge, status.Convert(err).Message())
+			return
+		}
+		if err != nil {
+			log.Error("Failed to create attachment", slog.Int64("task_id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+
+		if err := blobs.Put(r.Context(), blobKey(attachment), u.file, u.size, u.contentType); err != nil {
+			log.Error("Failed to store attachment", slog.Int64("id", attachment.GetId()), slog.String("err", err.Error()))
+			// Drop the metadata again so the upload can simply be retried.
+			if _, err := storage.DeleteAttachment(context.WithoutCancel(r.Context()), &dbpb.DeleteAttachmentRequest{
+				TaskId: id,
+				Id:     attachment.GetId(),
+			}); err != nil {
+				log.Error("Failed to delete attachment", slog.Int64("id", attachment.GetId()), slog.String("err", err.Error()))
+			}
+			httperr.Render(w, r, http.StatusServiceUnavailable, "attachment storage is unavailable")
+			return
+		}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, attachment)
+	}
+}
+
+func ListAttachments(log *slog.Logger, storage AttachmentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/attachments.go|ListAttachments()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+
+		resp, err := storage.ListAttachments(r.Context(), &dbpb.ListAttachmentsRequest{TaskId: id})
+		if err != nil {
+			log.Error("Failed to list attachments", slog.Int64("task_id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		attachments := resp.GetAttachments()
+		if attachments == nil {
+			attachments = []*dbpb.Attachment{}
+		}
+		render.JSON(w, r, attachments)
+	}
+}
+
+// DownloadAttachment sends the file with its stored name and type. Range and
+// If-Range requests are answered by http.ServeContent; the SHA-256 is the ETag.
+func DownloadAttachment(log *slog.Logger, storage AttachmentStore, blobs blob.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/attachments.go|DownloadAttachment()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		aid, ok := attachmentID(w, r, log)
+		if !ok {
+			return
+		}
+
+		attachment, err := storage.GetAttachment(r.Context(), &dbpb.GetAttachmentRequest{TaskId: id, Id: aid})
+		if err != nil {
+			log.Error("Failed to fetch attachment", slog.Int64("id", aid), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		content, err := blobs.Open(r.Context(), blobKey(attachment))
+		if errors.Is(err, blob.ErrNotFound) {
+			log.Error("Attachment content is missing", slog.Int64("id", aid))
+			httperr.Render(w, r, http.StatusNotFound, "attachment content is missing")
+			return
+		}
+		if err != nil {
+			log.Error("Failed to open attachment", slog.Int64("id", aid), slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusServiceUnavailable, "attachment storage is unavailable")
+			return
+		}
+		defer content.Close()
+
+		rc := http.NewResponseController(w)
+		// Large files outlive the server's WriteTimeout.
+		_ = rc.SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", attachment.GetContentType())
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.GetFilename()}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("ETag", `"`+attachment.GetSha256()+`"`)
+		http.ServeContent(w, r, "", attachment.GetCreatedAt().AsTime(), content)
+	}
+}
+
+func DeleteAttachment(log *slog.Logger, storage AttachmentStore, blobs blob.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/attachments.go|DeleteAttachment()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		aid, ok := attachmentID(w, r, log)
+		if !ok {
+			return
+		}
+
+		_, err := storage.DeleteAttachment(r.Context(), &dbpb.DeleteAttachmentRequest{TaskId: id, Id: aid})
+		if err != nil {
+			log.Error("Failed to delete attachment", slog.Int64("id", aid), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		attachment := &dbpb.Attachment{Id: aid, TaskId: id}
+		if err := blobs.Delete(r.Context(), blobKey(attachment)); err != nil {
+			// The metadata is gone, so the bytes only waste space now.
+			log.Error("Failed to delete attachment content", slog.Int64("id", aid), slog.String("err", err.Error()))
+		}
+		render.JSON(w, r, "attachment deleted")
+	}
+}
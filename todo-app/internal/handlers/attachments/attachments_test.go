@@ -0,0 +1,172 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"todo-app/internal/handlers/attachments/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+	"todo-app/internal/storage/blob/fs"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sha256 of "hello world"
+const helloSHA = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+func multipartBody(t *testing.T, filename, contentType, content string) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("comment", "ignored"))
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	part, err := mw.CreatePart(header)
+	require.NoError(t, err)
+	_, err = io.WriteString(part, content)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	return &buf, mw.FormDataContentType()
+}
+
+func withURLParams(req *http.Request, params ...string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for i := 0; i < len(params); i += 2 {
+		rctx.URLParams.Add(params[i], params[i+1])
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestUploadAttachment(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		filename       string
+		contentType    string
+		content        string
+		limits         Limits
+		mockSetup      func(store *mocks.AttachmentStore)
+		expectedStatus int
+		expectedBlob   string
+	}{
+		{
+			name:     "Success",
+			filename: `C:\logs\app.log`,
+			content:  "hello world",
+			limits:   Limits{MaxSize: 100},
+			mockSetup: func(store *mocks.AttachmentStore) {
+				store.On("CreateAttachment", mock.Anything, &dbpb.CreateAttachmentRequest{
+					TaskId:      3,
+					Filename:    "app.log",
+					ContentType: "text/plain; charset=utf-8",
+					Size:        11,
+					Sha256:      helloSHA,
+				}).Return(&dbpb.Attachment{Id: 9, TaskId: 3, Filename: "app.log", Size: 11}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBlob:   "hello world",
+		},
+		{
+			name:     "Quota exceeded",
+			filename: "shot.png",
+			content:  "hello world",
+			limits:   Limits{MaxSize: 100},
+			mockSetup: func(store *mocks.AttachmentStore) {
+				store.On("CreateAttachment", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.ResourceExhausted, "attachment quota exceeded: 5 of 10 bytes used"))
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "File too large",
+			filename:       "big.bin",
+			content:        "hello world",
+			limits:         Limits{MaxSize: 5},
+			mockSetup:      func(store *mocks.AttachmentStore) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "No filename",
+			filename:       "",
+			content:        "hello world",
+			mockSetup:      func(store *mocks.AttachmentStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewAttachmentStore(t)
+			tt.mockSetup(store)
+			blobs, err := fs.New(fs.Config{Dir: t.TempDir()})
+			require.NoError(t, err)
+
+			body, contentType := multipartBody(t, tt.filename, tt.contentType, tt.content)
+			req, err := http.NewRequest(http.MethodPost, "/tasks/3/attachments", body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", contentType)
+			req = withURLParams(req, "id", "3")
+			rr := httptest.NewRecorder()
+
+			UploadAttachment(log, store, blobs, tt.limits).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBlob != "" {
+				obj, err := blobs.Open(context.Background(), "tasks/3/9")
+				require.NoError(t, err)
+				defer obj.Close()
+				got, err := io.ReadAll(obj)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedBlob, string(got))
+			}
+		})
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+	blobs, err := fs.New(fs.Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, blobs.Put(context.Background(), "tasks/3/9", strings.NewReader("hello world"), 11, ""))
+
+	store := mocks.NewAttachmentStore(t)
+	store.On("GetAttachment", mock.Anything, &dbpb.GetAttachmentRequest{TaskId: 3, Id: 9}).Return(&dbpb.Attachment{
+		Id:          9,
+		TaskId:      3,
+		Filename:    "app.log",
+		ContentType: "text/plain",
+		Size:        11,
+		Sha256:      helloSHA,
+		CreatedAt:   timestamppb.Now(),
+	}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/tasks/3/attachments/9", nil)
+	require.NoError(t, err)
+	req.Header.Set("Range", "bytes=6-")
+	req = withURLParams(req, "id", "3", "aid", "9")
+	rr := httptest.NewRecorder()
+
+	DownloadAttachment(log, store, blobs).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusPartialContent, rr.Code)
+	assert.Equal(t, "world", rr.Body.String())
+	assert.Equal(t, "bytes 6-10/11", rr.Header().Get("Content-Range"))
+	assert.Equal(t, `attachment; filename=app.log`, rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, `"`+helloSHA+`"`, rr.Header().Get("ETag"))
+}
@@ -0,0 +1,149 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/handlers/create"
+	"todo-app/internal/handlers/update"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Batcher
+type Batcher interface {
+	BatchTasks(ctx context.Context, in *dbpb.BatchTasksRequest, opts ...grpc.CallOption) (*dbpb.BatchTasksResponse, error)
+}
+
+// Result reports one operation of a batch with the HTTP status it would have
+// got as a single request.
+type Result struct {
+	Status int        `json:"status"`
+	Error  string     `json:"error,omitempty"`
+	Task   *dbpb.Task `json:"task,omitempty"`
+}
+
+type Response struct {
+	Results []Result `json:"results"`
+}
+
+// toOperation converts one batch entry into its db service form.
+func toOperation(o requests.BatchOperation) (*dbpb.BatchOperation, error) {
+	op := &dbpb.BatchOperation{Action: o.Op}
+	if o.Op != "create" && o.ID < 1 {
+		return nil, errors.New("id must be a positive integer")
+	}
+	switch o.Op {
+	case "create":
+		if o.Task == nil {
+			return nil, errors.New("task is required")
+		}
+		req, err := create.NewTaskRequest(*o.Task, 0)
+		if err != nil {
+			return nil, err
+		}
+		op.Create = req
+	case "update":
+		if o.Patch == nil {
+			return nil, errors.New("patch is required")
+		}
+		req, err := update.PatchToUpdate(o.ID, *o.Patch)
+		if err != nil {
+			return nil, err
+		}
+		req.ExpectedVersion = o.Version
+		op.Update = req
+	case "mark_done":
+		op.MarkDone = &dbpb.MarkAsDoneRequest{
+			Id:              strconv.FormatInt(o.ID, 10),
+			ExpectedVersion: o.Version,
+			Cascade:         o.Cascade,
+		}
+	case "delete":
+		op.Delete = &dbpb.DeleteTaskRequest{
+			Id:              strconv.FormatInt(o.ID, 10),
+			ExpectedVersion: o.Version,
+			Permanent:       o.Permanent,
+		}
+	}
+	return op, nil
+}
+
+// BatchTasks runs several task operations in one db transaction, see
+// requests.BatchRequest for the modes.
+func BatchTasks(log *slog.Logger, storage Batcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/batch.go|BatchTasks()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.BatchRequest
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			Err := "request body is empty"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err != nil {
+			Err := "invalid request body"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err := validate.IsValid(req); err != nil {
+			Err := "mode must be atomic or partial, 1 to 100 operations of create, update, mark_done or delete"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		batchReq := &dbpb.BatchTasksRequest{Partial: req.Mode == "partial"}
+		for i, o := range req.Operations {
+			op, err := toOperation(o)
+			if err != nil {
+				Err := fmt.Sprintf("operation %d: %s", i, err.Error())
+				log.Info(Err)
+				httperr.Render(w, r, http.StatusBadRequest, Err)
+				return
+			}
+			batchReq.Operations = append(batchReq.Operations, op)
+		}
+
+		resp, err := storage.BatchTasks(r.Context(), batchReq)
+		if err != nil {
+			log.Error("batch failed", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+
+		out := Response{Results: make([]Result, 0, len(resp.GetResults()))}
+		for i, res := range resp.GetResults() {
+			if res.GetCode() != int32(codes.OK) {
+				code, msg := httperr.FromGRPC(status.Error(codes.Code(res.GetCode()), res.GetError()))
+				out.Results = append(out.Results, Result{Status: code, Error: msg})
+				continue
+			}
+			code := http.StatusOK
+			if req.Operations[i].Op == "create" {
+				code = http.StatusCreated
+			}
+			out.Results = append(out.Results, Result{Status: code, Task: res.GetTask()})
+		}
+		render.JSON(w, r, out)
+	}
+}
@@ -0,0 +1,101 @@
+package batch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/internal/handlers/batch/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBatchTasks(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(batcher *mocks.Batcher)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Partial results",
+			body: `{"mode":"partial","operations":[
+				{"op":"create","task":{"title":"a","content":"b"}},
+				{"op":"mark_done","id":7,"version":2},
+				{"op":"delete","id":8}]}`,
+			mockSetup: func(batcher *mocks.Batcher) {
+				batcher.On("BatchTasks", mock.Anything, mock.MatchedBy(func(req *dbpb.BatchTasksRequest) bool {
+					ops := req.GetOperations()
+					return req.GetPartial() && len(ops) == 3 &&
+						ops[0].GetCreate().GetTitle() == "a" &&
+						ops[1].GetMarkDone().GetId() == "7" && ops[1].GetMarkDone().GetExpectedVersion() == 2 &&
+						ops[2].GetDelete().GetId() == "8"
+				})).Return(&dbpb.BatchTasksResponse{Results: []*dbpb.BatchResult{
+					{Task: &dbpb.Task{Id: 9, Title: "a"}},
+					{Code: int32(codes.FailedPrecondition), Error: "task version is 3, not 2"},
+					{},
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"results":[{"status":201,"task":{"id":9,"title":"a"}},` +
+				`{"status":412,"error":"task version is 3, not 2"},{"status":200}]}`,
+		},
+		{
+			name: "Atomic failure",
+			body: `{"operations":[{"op":"update","id":7,"patch":{"title":"x"}}]}`,
+			mockSetup: func(batcher *mocks.Batcher) {
+				batcher.On("BatchTasks", mock.Anything, mock.MatchedBy(func(req *dbpb.BatchTasksRequest) bool {
+					return !req.GetPartial() && req.GetOperations()[0].GetUpdate().GetTitle() == "x"
+				})).Return(nil, status.Error(codes.NotFound, "operation 0: task not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":"ERROR","error":"operation 0: task not found"}`,
+		},
+		{
+			name:           "Unknown op",
+			body:           `{"operations":[{"op":"archive","id":1}]}`,
+			mockSetup:      func(batcher *mocks.Batcher) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid operation",
+			body:           `{"operations":[{"op":"create","task":{"title":"a","content":"b"}},{"op":"update","id":0,"patch":{}}]}`,
+			mockSetup:      func(batcher *mocks.Batcher) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"ERROR","error":"operation 1: id must be a positive integer"}`,
+		},
+		{
+			name:           "Empty batch",
+			body:           `{"operations":[]}`,
+			mockSetup:      func(batcher *mocks.Batcher) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batcher := mocks.NewBatcher(t)
+			tt.mockSetup(batcher)
+
+			req, err := http.NewRequest(http.MethodPost, "/tasks:batch", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			BatchTasks(log, batcher).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, strings.TrimSuffix(rr.Body.String(), "\n"))
+			}
+		})
+	}
+}
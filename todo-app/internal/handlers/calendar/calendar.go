@@ -0,0 +1,249 @@
+package calendar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/ical"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// FeedPath is where calendar apps read the feed.
+const FeedPath = "/tasks/calendar.ics"
+
+// maxFeedTasks caps the feed, most recently updated tasks first.
+const maxFeedTasks = 1000
+
+// refreshInterval is how often calendar apps are asked to reload the feed.
+const refreshInterval = "PT1H"
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CalendarStore
+type CalendarStore interface {
+	ResolveCalendarToken(ctx context.Context, in *dbpb.ResolveCalendarTokenRequest, opts ...grpc.CallOption) (*dbpb.ResolveCalendarTokenResponse, error)
+	ListTasks(ctx context.Context, in *dbpb.ListTasksRequest, opts ...grpc.CallOption) (*dbpb.ListTasksResponse, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TokenStore
+type TokenStore interface {
+	CreateCalendarToken(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*dbpb.CalendarToken, error)
+	GetCalendarToken(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*dbpb.CalendarToken, error)
+	RevokeCalendarToken(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+// Token is the response of POST /calendar/token.
+type Token struct {
+	Token string `json:"token"`
+	// URL is the path of the feed with the token, to subscribe to.
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Feed serves the tasks of the owner of the feed token as an iCalendar
+// VCALENDAR with a VTODO per task. Calendar apps cannot send the
+// Authorization header, so the token comes in ?token= or as the password of
+// HTTP Basic authentication.
+func Feed(log *slog.Logger, storage CalendarStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/calendar.go|Feed()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			_, token, _ = r.BasicAuth()
+		}
+		if token == "" {
+			log.Info("calendar token missing")
+			w.Header().Set("WWW-Authenticate", `Basic realm="tasks"`)
+			httperr.Render(w, r, http.StatusUnauthorized, "token required")
+			return
+		}
+		owner, err := storage.ResolveCalendarToken(r.Context(), &dbpb.ResolveCalendarTokenRequest{Token: token})
+		if err != nil {
+			if status.Code(err) == codes.Unauthenticated {
+				log.Info("invalid calendar token")
+				w.Header().Set("WWW-Authenticate", `Basic realm="tasks"`)
+			} else {
+				log.Error("Failed to resolve calendar token", slog.String("err", err.Error()))
+			}
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+
+		ctx := mwAuth.WithUser(r.Context(), owner.GetUserId())
+		var tasks []*dbpb.Task
+		after := ""
+		for len(tasks) < maxFeedTasks {
+			page, err := storage.ListTasks(ctx, &dbpb.ListTasksRequest{Limit: 100, After: after, Sort: "-updated_at"})
+			if err != nil {
+				log.Error("Failed to list tasks", slog.Int64("user_id", owner.GetUserId()), slog.String("err", err.Error()))
+				httperr.RenderGRPC(w, r, err)
+				return
+			}
+			tasks = append(tasks, page.GetTasks()...)
+			if after = page.GetNextCursor(); after == "" {
+				break
+			}
+		}
+		if len(tasks) > maxFeedTasks {
+			tasks = tasks[:maxFeedTasks]
+		}
+
+		body := encode(tasks, time.Now())
+		// DTSTAMP changes on every request, so the tag leaves it out.
+		tag := etag(tasks)
+		w.Header().Set("ETag", tag)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		if r.Header.Get("If-None-Match") == tag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+		_, _ = w.Write(body)
+	}
+}
+
+// encode renders tasks as a VCALENDAR.
+func encode(tasks []*dbpb.Task, now time.Time) []byte {
+	var w ical.Writer
+	w.Begin("VCALENDAR")
+	w.Raw("VERSION", "2.0")
+	w.Raw("PRODID", "-//todo-app//Tasks//EN")
+	w.Raw("CALSCALE", "GREGORIAN")
+	w.Raw("METHOD", "PUBLISH")
+	w.Text("X-WR-CALNAME", "Tasks")
+	w.Raw("REFRESH-INTERVAL;VALUE=DURATION", refreshInterval)
+	w.Raw("X-PUBLISHED-TTL", refreshInterval)
+	for _, task := range tasks {
+		w.Begin("VTODO")
+		w.Raw("UID", "task-"+strconv.FormatInt(task.GetId(), 10)+"@todo-app")
+		w.Time("DTSTAMP", now)
+		w.Text("SUMMARY", task.GetTitle())
+		if task.GetContent() != "" {
+			w.Text("DESCRIPTION", task.GetContent())
+		}
+		if task.GetIsDone() {
+			w.Raw("STATUS", "COMPLETED")
+		} else {
+			w.Raw("STATUS", "NEEDS-ACTION")
+		}
+		if task.GetDueAt() != nil {
+			w.Time("DUE", task.GetDueAt().AsTime())
+		}
+		if task.GetCreatedAt() != nil {
+			w.Time("CREATED", task.GetCreatedAt().AsTime())
+		}
+		if task.GetUpdatedAt() != nil {
+			w.Time("LAST-MODIFIED", task.GetUpdatedAt().AsTime())
+		}
+		if p, ok := priorities[task.GetPriority()]; ok {
+			w.Raw("PRIORITY", p)
+		}
+		if len(task.GetTags()) > 0 {
+			w.TextList("CATEGORIES", task.GetTags())
+		}
+		// Every change bumps the version, so it serves as the sequence.
+		w.Raw("SEQUENCE", strconv.FormatInt(max(task.GetVersion()-1, 0), 10))
+		w.End("VTODO")
+	}
+	w.End("VCALENDAR")
+	return w.Bytes()
+}
+
+// priorities maps task priorities to iCalendar's 1 (highest) to 9 (lowest).
+var priorities = map[string]string{
+	"urgent": "1",
+	"high":   "3",
+	"normal": "5",
+	"low":    "9",
+}
+
+// etag is a strong entity tag of the feed of tasks: it changes when a task
+// is added, removed or changed.
+func etag(tasks []*dbpb.Task) string {
+	h := sha256.New()
+	for _, task := range tasks {
+		h.Write([]byte(strconv.FormatInt(task.GetId(), 10) + ":" + strconv.FormatInt(task.GetVersion(), 10) + ","))
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// CreateToken creates the caller's feed token, revoking the previous one. The
+// token is shown only in this response.
+func CreateToken(log *slog.Logger, storage TokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/calendar.go|CreateToken()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		token, err := storage.CreateCalendarToken(r.Context(), &emptypb.Empty{})
+		if err != nil {
+			log.Error("Failed to create calendar token", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Token{
+			Token:     token.GetToken(),
+			URL:       FeedPath + "?token=" + url.QueryEscape(token.GetToken()),
+			CreatedAt: token.GetCreatedAt().AsTime(),
+		})
+	}
+}
+
+// GetToken tells when the caller's feed token was created and last used.
+func GetToken(log *slog.Logger, storage TokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/calendar.go|GetToken()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		token, err := storage.GetCalendarToken(r.Context(), &emptypb.Empty{})
+		if err != nil {
+			log.Info("Failed to fetch calendar token", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, token)
+	}
+}
+
+// RevokeToken deletes the caller's feed token; the feed stops working until a
+// new one is created.
+func RevokeToken(log *slog.Logger, storage TokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/calendar.go|RevokeToken()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if _, err := storage.RevokeCalendarToken(r.Context(), &emptypb.Empty{}); err != nil {
+			log.Error("Failed to revoke calendar token", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, "calendar token revoked")
+	}
+}
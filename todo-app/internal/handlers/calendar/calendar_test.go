@@ -0,0 +1,160 @@
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/handlers/calendar/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// asUser matches contexts that call the db service as userID.
+func asUser(userID int64) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		claims, ok := mwAuth.ClaimsFromContext(ctx)
+		return ok && claims.UserID == userID
+	})
+}
+
+var tasks = []*dbpb.Task{
+	{
+		Id:        5,
+		Title:     "Pay rent",
+		Content:   "Landlord: IBAN, reference 12",
+		DueAt:     timestamppb.New(time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)),
+		CreatedAt: timestamppb.New(time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC)),
+		UpdatedAt: timestamppb.New(time.Date(2025, 2, 2, 8, 0, 0, 0, time.UTC)),
+		Version:   3,
+		Priority:  "high",
+		Tags:      []string{"home"},
+	},
+	{
+		Id:        6,
+		Title:     "Call Bob",
+		IsDone:    true,
+		CreatedAt: timestamppb.New(time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC)),
+		UpdatedAt: timestamppb.New(time.Date(2025, 2, 3, 8, 0, 0, 0, time.UTC)),
+		Version:   1,
+	},
+}
+
+func TestFeed(t *testing.T) {
+	store := mocks.NewCalendarStore(t)
+	store.On("ResolveCalendarToken", mock.Anything, &dbpb.ResolveCalendarTokenRequest{Token: "cal_1"}).
+		Return(&dbpb.ResolveCalendarTokenResponse{UserId: 7}, nil)
+	store.On("ListTasks", asUser(7), &dbpb.ListTasksRequest{Limit: 100, Sort: "-updated_at"}).
+		Return(&dbpb.ListTasksResponse{Tasks: tasks[:1], NextCursor: "c1"}, nil)
+	store.On("ListTasks", asUser(7), &dbpb.ListTasksRequest{Limit: 100, Sort: "-updated_at", After: "c1"}).
+		Return(&dbpb.ListTasksResponse{Tasks: tasks[1:]}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, FeedPath+"?token=cal_1", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+
+	Feed(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rr.Header().Get("ETag"))
+	body := rr.Body.String()
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+	assert.Contains(t, body, "BEGIN:VTODO\r\nUID:task-5@todo-app\r\nDTSTAMP:")
+	assert.Contains(t, body, "SUMMARY:Pay rent\r\n"+
+		`DESCRIPTION:Landlord: IBAN\, reference 12`+"\r\n"+
+		"STATUS:NEEDS-ACTION\r\n"+
+		"DUE:20250301T090000Z\r\n"+
+		"CREATED:20250201T080000Z\r\n"+
+		"LAST-MODIFIED:20250202T080000Z\r\n"+
+		"PRIORITY:3\r\n"+
+		"CATEGORIES:home\r\n"+
+		"SEQUENCE:2\r\n"+
+		"END:VTODO\r\n")
+	assert.Contains(t, body, "SUMMARY:Call Bob\r\nSTATUS:COMPLETED\r\nCREATED:")
+}
+
+func TestFeedNotModified(t *testing.T) {
+	store := mocks.NewCalendarStore(t)
+	store.On("ResolveCalendarToken", mock.Anything, &dbpb.ResolveCalendarTokenRequest{Token: "cal_1"}).
+		Return(&dbpb.ResolveCalendarTokenResponse{UserId: 7}, nil)
+	store.On("ListTasks", asUser(7), mock.Anything).Return(&dbpb.ListTasksResponse{Tasks: tasks}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, FeedPath, nil)
+	require.NoError(t, err)
+	// Basic authentication, as some calendar apps prefer.
+	req.SetBasicAuth("", "cal_1")
+	req.Header.Set("If-None-Match", etag(tasks))
+	rr := httptest.NewRecorder()
+
+	Feed(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+}
+
+func TestFeedUnauthorized(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		mockSetup func(store *mocks.CalendarStore)
+	}{
+		{
+			name:      "No token",
+			mockSetup: func(store *mocks.CalendarStore) {},
+		},
+		{
+			name:  "Revoked token",
+			query: "?token=cal_old",
+			mockSetup: func(store *mocks.CalendarStore) {
+				store.On("ResolveCalendarToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.Unauthenticated, "invalid calendar token"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewCalendarStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodGet, FeedPath+tt.query, nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			Feed(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			assert.Equal(t, `Basic realm="tasks"`, rr.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestCreateToken(t *testing.T) {
+	store := mocks.NewTokenStore(t)
+	store.On("CreateCalendarToken", mock.Anything, &emptypb.Empty{}).Return(&dbpb.CalendarToken{
+		Token:     "cal_1",
+		CreatedAt: timestamppb.New(time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC)),
+	}, nil)
+
+	req, err := http.NewRequest(http.MethodPost, "/calendar/token", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+
+	CreateToken(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.JSONEq(t, `{"token":"cal_1","url":"/tasks/calendar.ics?token=cal_1","created_at":"2025-02-01T08:00:00Z"}`, rr.Body.String())
+}
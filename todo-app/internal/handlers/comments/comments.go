@@ -0,0 +1,218 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=CommentStore
+type CommentStore interface {
+	CreateComment(ctx context.Context, in *dbpb.CreateCommentRequest, opts ...grpc.CallOption) (*dbpb.Comment, error)
+	ListComments(ctx context.Context, in *dbpb.ListCommentsRequest, opts ...grpc.CallOption) (*dbpb.ListCommentsResponse, error)
+	UpdateComment(ctx context.Context, in *dbpb.UpdateCommentRequest, opts ...grpc.CallOption) (*dbpb.Comment, error)
+	DeleteComment(ctx context.Context, in *dbpb.DeleteCommentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type CommentsPage struct {
+	Items      []*dbpb.Comment `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func urlID(w http.ResponseWriter, r *http.Request, log *slog.Logger, param, Err string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id < 1 {
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return 0, false
+	}
+	return id, true
+}
+
+func taskID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return urlID(w, r, log, "id", "Invalid task ID")
+}
+
+func commentID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return urlID(w, r, log, "cid", "Invalid comment ID")
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, log *slog.Logger) (requests.CommentRequest, bool) {
+	var req requests.CommentRequest
+	err := render.DecodeJSON(r.Body, &req)
+	if errors.Is(err, io.EOF) {
+		Err := "request body is empty"
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return req, false
+	}
+	if err != nil {
+		Err := "invalid request body"
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return req, false
+	}
+	if err := validate.IsValid(req); err != nil {
+		Err := "body is required and must be up to 10000 characters"
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return req, false
+	}
+	return req, true
+}
+
+// parseListQuery reads limit, after and history of GET /tasks/{id}/comments.
+func parseListQuery(q url.Values) (*dbpb.ListCommentsRequest, error) {
+	req := &dbpb.ListCommentsRequest{After: q.Get("after")}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		req.Limit = int32(limit)
+	}
+	if v := q.Get("history"); v != "" {
+		history, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("history must be true or false")
+		}
+		req.IncludeHistory = history
+	}
+	return req, nil
+}
+
+func CreateComment(log *slog.Logger, storage CommentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/comments.go|CreateComment()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		req, ok := decodeBody(w, r, log)
+		if !ok {
+			return
+		}
+
+		comment, err := storage.CreateComment(r.Context(), &dbpb.CreateCommentRequest{TaskId: id, Body: req.Body})
+		if err != nil {
+			log.Error("Failed to create comment", slog.Int64("task_id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, comment)
+	}
+}
+
+// ListComments returns a page of the task's comments, oldest first.
+// ?history=true adds the previous bodies of edited comments.
+func ListComments(log *slog.Logger, storage CommentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/comments.go|ListComments()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		listReq, err := parseListQuery(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		listReq.TaskId = id
+
+		resp, err := storage.ListComments(r.Context(), listReq)
+		if err != nil {
+			log.Error("Failed to list comments", slog.Int64("task_id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		page := CommentsPage{
+			Items:      resp.GetComments(),
+			NextCursor: resp.GetNextCursor(),
+		}
+		if page.Items == nil {
+			page.Items = []*dbpb.Comment{}
+		}
+		render.JSON(w, r, page)
+	}
+}
+
+// UpdateComment replaces the body of a comment; only its author may do so.
+func UpdateComment(log *slog.Logger, storage CommentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/comments.go|UpdateComment()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		cid, ok := commentID(w, r, log)
+		if !ok {
+			return
+		}
+		req, ok := decodeBody(w, r, log)
+		if !ok {
+			return
+		}
+
+		comment, err := storage.UpdateComment(r.Context(), &dbpb.UpdateCommentRequest{TaskId: id, Id: cid, Body: req.Body})
+		if err != nil {
+			log.Error("Failed to update comment", slog.Int64("id", cid), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, comment)
+	}
+}
+
+func DeleteComment(log *slog.Logger, storage CommentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/comments.go|DeleteComment()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		cid, ok := commentID(w, r, log)
+		if !ok {
+			return
+		}
+
+		_, err := storage.DeleteComment(r.Context(), &dbpb.DeleteCommentRequest{TaskId: id, Id: cid})
+		if err != nil {
+			log.Error("Failed to delete comment", slog.Int64("id", cid), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, "comment deleted")
+	}
+}
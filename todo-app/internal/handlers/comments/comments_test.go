@@ -0,0 +1,148 @@
+package comments
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/internal/handlers/comments/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+	mwAuth "todo-app/internal/middleware/auth"
+	"todo-app/internal/token"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateComment(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		id             string
+		body           string
+		mockSetup      func(store *mocks.CommentStore)
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			id:   "3",
+			body: `{"body":"done on my side"}`,
+			mockSetup: func(store *mocks.CommentStore) {
+				store.On("CreateComment", mock.Anything, &dbpb.CreateCommentRequest{TaskId: 3, Body: "done on my side"}).
+					Return(&dbpb.Comment{Id: 5, TaskId: 3, AuthorId: 42, Body: "done on my side"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Task not found",
+			id:   "3",
+			body: `{"body":"hello"}`,
+			mockSetup: func(store *mocks.CommentStore) {
+				store.On("CreateComment", mock.Anything, mock.Anything).Return(nil, status.Error(codes.NotFound, "task not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Empty body",
+			id:             "3",
+			body:           `{"body":""}`,
+			mockSetup:      func(store *mocks.CommentStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid task ID",
+			id:             "abc",
+			body:           `{"body":"hello"}`,
+			mockSetup:      func(store *mocks.CommentStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewCommentStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodPost, "/tasks/"+tt.id+"/comments", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, mwAuth.UserKey, &token.Claims{UserID: 42})
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			CreateComment(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		cid            string
+		mockSetup      func(store *mocks.CommentStore)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			cid:  "5",
+			mockSetup: func(store *mocks.CommentStore) {
+				store.On("UpdateComment", mock.Anything, &dbpb.UpdateCommentRequest{TaskId: 3, Id: 5, Body: "fixed"}).
+					Return(&dbpb.Comment{Id: 5, TaskId: 3, Body: "fixed", EditCount: 1,
+						History: []*dbpb.CommentRevision{{Body: "fxied"}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":5,"task_id":3,"body":"fixed","edit_count":1,"history":[{"body":"fxied"}]}`,
+		},
+		{
+			name: "Not the author",
+			cid:  "5",
+			mockSetup: func(store *mocks.CommentStore) {
+				store.On("UpdateComment", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.PermissionDenied, "only the author can change a comment"))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":"ERROR","error":"only the author can change a comment"}`,
+		},
+		{
+			name:           "Invalid comment ID",
+			cid:            "0",
+			mockSetup:      func(store *mocks.CommentStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"ERROR","error":"Invalid comment ID"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewCommentStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodPatch, "/tasks/3/comments/"+tt.cid, strings.NewReader(`{"body":"fixed"}`))
+			require.NoError(t, err)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "3")
+			rctx.URLParams.Add("cid", tt.cid)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			UpdateComment(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, strings.TrimSuffix(rr.Body.String(), "\n"))
+		})
+	}
+}
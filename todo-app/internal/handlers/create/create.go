@@ -6,63 +6,107 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
 	"google.golang.org/grpc"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
-//go:generate go run github.com/vektra/mockery/v2@latest --name=KafkaProducer
-type KafkaProducer interface {
-	SendApiEvent(apiRequest *requests.ApiRequest) error
-}
-
 //go:generate go run github.com/vektra/mockery/v2@latest --name=Creator
 type Creator interface {
 	CreateTask(ctx context.Context, in *dbpb.CreateTaskRequest, opts ...grpc.CallOption) (*dbpb.Task, error)
 }
 
-func CreateTask(log *slog.Logger, client Creator, kafkaProducer KafkaProducer) http.HandlerFunc {
+func CreateTask(log *slog.Logger, client Creator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		fn := "internal/http-server/handlers/handlers.go|CreateTask()"
-		log = log.With(
+		log := log.With(
 			slog.String("fn", fn),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
+		createTask(w, r, log, client, 0)
+	}
+}
 
-		var req requests.CreateTaskRequest
-		err := render.DecodeJSON(r.Body, &req)
-		if errors.Is(err, io.EOF) {
-			Err := "request body is empty"
-			log.Info(Err)			
-			http.Error(w, Err, http.StatusBadRequest)
-			return
-		}
-		if req.Title == "" || req.Content == "" {
-			Err := "Title or Content in request Body is empty or invalid"
-			log.Info(Err)
-			http.Error(w, Err, http.StatusBadRequest)
-			return
-		}
-		
-		task, err := client.CreateTask(r.Context(),
-		&dbpb.CreateTaskRequest{
-			Title:   req.Title,
-			Content: req.Content},
+// CreateSubtask creates a task under the parent task in the URL.
+func CreateSubtask(log *slog.Logger, client Creator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/handlers.go|CreateSubtask()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
-		if err != nil {
-			log.Error("Failed to take tasks: ", slog.String("err", err.Error()))
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		parentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || parentID < 1 {
+			Err := "Invalid task ID"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
 			return
 		}
-		
-		render.JSON(w, r, &task)
-		event := &requests.ApiRequest{
-			Action: "created",
-		}
-		if err := kafkaProducer.SendApiEvent(event); err != nil {
-			log.Error("failed to send kafka even", (slog.String("error", err.Error())))
-		}
+		createTask(w, r, log, client, parentID)
+	}
+}
+
+func createTask(w http.ResponseWriter, r *http.Request, log *slog.Logger, client Creator, parentID int64) {
+	var req requests.CreateTaskRequest
+	err := render.DecodeJSON(r.Body, &req)
+	if errors.Is(err, io.EOF) {
+		Err := "request body is empty"
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return
+	}
+	if err != nil {
+		Err := "invalid request body"
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return
+	}
+	createReq, err := NewTaskRequest(req, parentID)
+	if err != nil {
+		log.Info(err.Error())
+		httperr.Render(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task, err := client.CreateTask(r.Context(), createReq)
+	if err != nil {
+		log.Error("Failed to take tasks: ", slog.String("err", err.Error()))
+		httperr.RenderGRPC(w, r, err)
+		return
+	}
+	
+	render.JSON(w, r, &task)
+}
+
+// NewTaskRequest validates a create body and converts it into a CreateTask
+// request. parentID is 0 for top-level tasks.
+func NewTaskRequest(req requests.CreateTaskRequest, parentID int64) (*dbpb.CreateTaskRequest, error) {
+	if req.Title == "" || req.Content == "" {
+		return nil, errors.New("Title or Content in request Body is empty or invalid")
+	}
+	if err := validate.IsValid(req); err != nil {
+		return nil, errors.New("priority must be low, normal, high or urgent, up to 20 tags of up to 50 characters, description up to 10000 characters")
+	}
+	var dueAt *timestamppb.Timestamp
+	if req.DueAt != nil {
+		dueAt = timestamppb.New(*req.DueAt)
 	}
+	return &dbpb.CreateTaskRequest{
+		Title:       req.Title,
+		Content:     req.Content,
+		DueAt:       dueAt,
+		Priority:    req.Priority,
+		Tags:        req.Tags,
+		Description: req.Description,
+		ProjectId:   req.ProjectID,
+		ParentId:    parentID,
+		Rrule:       req.RRule,
+	}, nil
 }
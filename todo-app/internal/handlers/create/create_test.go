@@ -2,6 +2,7 @@ package create
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -10,18 +11,18 @@ import (
 	"todo-app/internal/handlers/create/mocks"
 	"todo-app/internal/lib/logger/slogdiscard"
 
-	"todo-app/internal/domain/requests"
-
+	"github.com/go-chi/chi/v5"
 	"github.com/rail52/myprojects/dbpb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestCreateTask(t *testing.T) {
 	// Создаем моки и логгер один раз для всех тестов
 	mockCreator := mocks.NewCreator(t)
-	mockProducer := mocks.NewKafkaProducer(t)
 	log := slogdiscard.NewDiscardLogger()
 
 	tests := []struct {
@@ -38,7 +39,7 @@ func TestCreateTask(t *testing.T) {
 				// Никаких вызовов не ожидаем
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedJSON:  "request body is empty",
+			expectedJSON:  `{"status":"ERROR","error":"request body is empty"}`,
 		},
 		{
 			name:        "Missing title",
@@ -47,7 +48,16 @@ func TestCreateTask(t *testing.T) {
 				// Никаких вызовов не ожидаем
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedJSON:  "Title or Content in request Body is empty or invalid",
+			expectedJSON:  `{"status":"ERROR","error":"Title or Content in request Body is empty or invalid"}`,
+		},
+		{
+			name:        "Too long description",
+			requestBody: `{"title": "test", "content": "content", "description": "` + strings.Repeat("a", 10001) + `"}`,
+			mockSetup: func() {
+				// Никаких вызовов не ожидаем
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedJSON:  `{"status":"ERROR","error":"priority must be low, normal, high or urgent, up to 20 tags of up to 50 characters, description up to 10000 characters"}`,
 		},
 		{
 			name:        "Valid request",
@@ -61,8 +71,6 @@ func TestCreateTask(t *testing.T) {
 					Title:   "test",
 					Content: "content",
 				}, nil)
-
-				mockProducer.On("SendApiEvent", &requests.ApiRequest{Action: "created"}).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedJSON:  `{"id":123,"title":"test","content":"content"}`,
@@ -74,8 +82,28 @@ func TestCreateTask(t *testing.T) {
 				mockCreator.On("CreateTask", mock.Anything, mock.Anything, mock.Anything).
 					Return(nil, errors.New("grpc error"))
 			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedJSON:  `{"status":"ERROR","error":"internal error"}`,
+		},
+		{
+			name:        "GRPC invalid argument",
+			requestBody: `{"title": "test", "content": "content"}`,
+			mockSetup: func() {
+				mockCreator.On("CreateTask", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.InvalidArgument, "value too long for type character varying(255)"))
+			},
 			expectedStatus: http.StatusBadRequest,
-			expectedJSON:  "grpc error",
+			expectedJSON:  `{"status":"ERROR","error":"value too long for type character varying(255)"}`,
+		},
+		{
+			name:        "GRPC unavailable",
+			requestBody: `{"title": "test", "content": "content"}`,
+			mockSetup: func() {
+				mockCreator.On("CreateTask", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.Unavailable, "database is unavailable"))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedJSON:  `{"status":"ERROR","error":"service unavailable"}`,
 		},
 	}
 
@@ -83,7 +111,6 @@ func TestCreateTask(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Сбрасываем моки перед каждым тестом
 			mockCreator.ExpectedCalls = nil
-			mockProducer.ExpectedCalls = nil
 
 			// Настраиваем моки
 			if tt.mockSetup != nil {
@@ -98,7 +125,7 @@ func TestCreateTask(t *testing.T) {
 			rr := httptest.NewRecorder()
 
 			// Вызываем обработчик
-			handler := CreateTask(log, mockCreator, mockProducer)
+			handler := CreateTask(log, mockCreator)
 			handler.ServeHTTP(rr, req)
 
 			// Проверяем статус код
@@ -111,7 +138,43 @@ func TestCreateTask(t *testing.T) {
 
 			// Проверяем вызовы моков
 			mockCreator.AssertExpectations(t)
-			mockProducer.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestCreateSubtask(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	t.Run("Parent comes from the URL", func(t *testing.T) {
+		mockCreator := mocks.NewCreator(t)
+		mockCreator.On("CreateTask", mock.Anything, &dbpb.CreateTaskRequest{
+			Title:    "step",
+			Content:  "content",
+			ParentId: 5,
+		}).Return(&dbpb.Task{Id: 6, Title: "step", Content: "content", ParentId: 5}, nil)
+
+		req, err := http.NewRequest("POST", "/tasks/5/subtasks", bytes.NewBufferString(`{"title": "step", "content": "content"}`))
+		require.NoError(t, err)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "5")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+
+		CreateSubtask(log, mockCreator).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"parent_id":5`)
+	})
+
+	t.Run("Invalid parent ID", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/tasks/x/subtasks", bytes.NewBufferString(`{"title": "step", "content": "content"}`))
+		require.NoError(t, err)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "x")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+
+		CreateSubtask(log, mocks.NewCreator(t)).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -3,7 +3,9 @@ package delete
 import (
 	"log/slog"
 	"net/http"
-	"todo-app/internal/domain/requests"
+	"strconv"
+	"todo-app/internal/lib/etag"
+	"todo-app/internal/lib/httperr"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -11,11 +13,8 @@ import (
 	"github.com/rail52/myprojects/dbpb"
 )
 
-type KafkaProducer interface {
-	SendApiEvent(apiRequest *requests.ApiRequest) error
-}
-
-func DeleteTask(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaProducer) http.HandlerFunc {
+// DeleteTask moves the task to the trash; ?permanent=true removes it for good.
+func DeleteTask(log *slog.Logger, storage dbpb.PostgresClient) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		fn := "internal/http-server/handlers/handlers.go|DeleteTask()"
 		log = log.With(
@@ -23,26 +22,40 @@ func DeleteTask(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer Kaf
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 		idStr := chi.URLParam(r, "id")
-		_, err := storage.DeleteTask(
+		expected, err := etag.ExpectedVersion(r)
+		if err != nil {
+			log.Info(err.Error())
+			httperr.Render(w, r, http.StatusPreconditionFailed, err.Error())
+			return
+		}
+		var permanent bool
+		if v := r.URL.Query().Get("permanent"); v != "" {
+			permanent, err = strconv.ParseBool(v)
+			if err != nil {
+				Err := "permanent must be true or false"
+				log.Info(Err)
+				httperr.Render(w, r, http.StatusBadRequest, Err)
+				return
+			}
+		}
+		_, err = storage.DeleteTask(
 			r.Context(),
 			&dbpb.DeleteTaskRequest{
-				Id: idStr,
+				Id:              idStr,
+				ExpectedVersion: expected,
+				Permanent:       permanent,
 			},
 		)
 		if err != nil {
 			Err := "Failed to Delete task with ID: " + idStr
-			log.Error(Err)
-			http.Error(w, Err, http.StatusBadRequest)
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		render.JSON(w, r, "task deleted")
-		event := &requests.ApiRequest{
-			Action: "deleted",
-		}
-
-		if err := kafkaProducer.SendApiEvent(event); err != nil {
-			log.Error("failed to send kafka even", (slog.String("error", err.Error())))
+		msg := "task moved to trash"
+		if permanent {
+			msg = "task deleted"
 		}
+		render.JSON(w, r, msg)
 	}
 }
@@ -11,9 +11,8 @@ import (
 
 func TestDeleteTask(t *testing.T) {
 	type args struct {
-		log           *slog.Logger
-		storage       dbpb.PostgresClient
-		kafkaProducer KafkaProducer
+		log     *slog.Logger
+		storage dbpb.PostgresClient
 	}
 	tests := []struct {
 		name string
@@ -24,7 +23,7 @@ func TestDeleteTask(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := DeleteTask(tt.args.log, tt.args.storage, tt.args.kafkaProducer); !reflect.DeepEqual(got, tt.want) {
+			if got := DeleteTask(tt.args.log, tt.args.storage); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("DeleteTask() = %v, want %v", got, tt.want)
 			}
 		})
@@ -0,0 +1,43 @@
+package delete
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"todo-app/internal/lib/etag"
+	"todo-app/internal/lib/httperr"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+)
+
+// RestoreTask brings a task and the subtasks trashed with it back from the trash.
+func RestoreTask(log *slog.Logger, storage dbpb.PostgresClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/handlers.go|RestoreTask()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id < 1 {
+			Err := "Invalid task ID"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		task, err := storage.RestoreTask(r.Context(), &dbpb.RestoreTaskRequest{Id: id})
+		if err != nil {
+			Err := "Failed to restore task with ID: " + idStr
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		etag.Set(w, task.GetVersion())
+		render.JSON(w, r, &task)
+	}
+}
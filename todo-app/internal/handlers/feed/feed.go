@@ -0,0 +1,203 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/live"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rail52/myprojects/dbpb"
+	"golang.org/x/net/websocket"
+)
+
+// heartbeat is how often an idle stream sends something, so that proxies
+// and load balancers do not close it.
+var heartbeat = 15 * time.Second
+
+// retryMillis is the reconnection delay suggested to EventSource clients.
+const retryMillis = 3000
+
+type Hub interface {
+	Subscribe(userID, after int64) *live.Subscription
+}
+
+// Message is a WebSocket message: an event and its id to resume after.
+type Message struct {
+	ID    int64           `json:"id"`
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// TaskEvents streams the caller's events as they happen, as server-sent
+// events or, when the request is a WebSocket upgrade, as one JSON Message
+// per event.
+//
+// Each server-sent event has the event type as its name, the envelope
+// published to Kafka as its data and an id; a client that reconnects with
+// it in Last-Event-ID, or in ?last_event_id= on WebSocket, gets the events
+// it missed. ?types= takes a comma-separated list of event types to send.
+// Like every other route, it needs the Authorization header; browsers have
+// to use a fetch-based EventSource for that.
+func TaskEvents(log *slog.Logger, hub Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/feed.go|TaskEvents()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := mwAuth.ClaimsFromContext(r.Context())
+		if !ok {
+			httperr.Render(w, r, http.StatusUnauthorized, "token required")
+			return
+		}
+		after, err := lastEventID(r)
+		if err != nil {
+			log.Info("invalid last event id", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		var types []string
+		if v := r.URL.Query().Get("types"); v != "" {
+			types = strings.Split(v, ",")
+		}
+
+		// The streams outlive the server's read and write timeouts.
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		sub := hub.Subscribe(claims.UserID, after)
+		defer sub.Close()
+		wanted := func(event *dbpb.TaskEvent) bool {
+			return types == nil || slices.Contains(types, event.GetEventType())
+		}
+
+		if isWebSocket(r) {
+			websocket.Server{Handler: func(ws *websocket.Conn) {
+				serveWebSocket(ws, sub, wanted)
+			}}.ServeHTTP(w, r)
+		} else {
+			serveSSE(w, r, rc, sub, wanted)
+		}
+		if err := sub.Err(); err != nil {
+			log.Info("event stream closed", slog.String("reason", err.Error()))
+		}
+	}
+}
+
+// lastEventID reads the id to resume after from Last-Event-ID or
+// ?last_event_id=; 0 if neither is set.
+func lastEventID(r *http.Request) (int64, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid last event id %q", v)
+	}
+	return id, nil
+}
+
+func isWebSocket(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+func serveSSE(w http.ResponseWriter, r *http.Request, rc *http.ResponseController, sub *live.Subscription, wanted func(*dbpb.TaskEvent) bool) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keeps nginx from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+	if rc.Flush() != nil {
+		return
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			_, _ = w.Write([]byte(": ping\n\n"))
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if !wanted(event) {
+				continue
+			}
+			writeSSE(w, event)
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, event *dbpb.TaskEvent) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "id: %d\nevent: %s\n", event.GetId(), event.GetEventType())
+	for _, line := range bytes.Split(event.GetPayload(), []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	_, _ = w.Write(buf.Bytes())
+}
+
+func serveWebSocket(ws *websocket.Conn, sub *live.Subscription, wanted func(*dbpb.TaskEvent) bool) {
+	// Nothing is expected from the client; reading notices when it leaves.
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		var discard []byte
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+	}()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ws.PayloadType = websocket.PingFrame
+			_, err := ws.Write(nil)
+			ws.PayloadType = websocket.TextFrame
+			if err != nil {
+				return
+			}
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if !wanted(event) {
+				continue
+			}
+			msg := Message{ID: event.GetId(), Type: event.GetEventType(), Event: event.GetPayload()}
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				return
+			}
+		}
+	}
+}
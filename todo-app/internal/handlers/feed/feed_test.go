@@ -0,0 +1,131 @@
+package feed
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/lib/logger/slogdiscard"
+	"todo-app/internal/live"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// stream sends the events after its request's AfterId, then waits.
+type stream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	after  int64
+	events []*dbpb.TaskEvent
+}
+
+func (s *stream) Header() (metadata.MD, error) {
+	return metadata.Pairs(live.StartMetadataKey, strconv.FormatInt(s.after, 10)), nil
+}
+
+func (s *stream) Recv() (*dbpb.TaskEvent, error) {
+	for len(s.events) > 0 {
+		event := s.events[0]
+		s.events = s.events[1:]
+		if event.GetId() > s.after {
+			return event, nil
+		}
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+type watcher struct {
+	events []*dbpb.TaskEvent
+	after  chan int64
+}
+
+func (w *watcher) WatchTasks(ctx context.Context, in *dbpb.WatchTasksRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[dbpb.TaskEvent], error) {
+	w.after <- in.GetAfterId()
+	return &stream{ctx: ctx, after: in.GetAfterId(), events: w.events}, nil
+}
+
+func newServer(t *testing.T) (*httptest.Server, *watcher) {
+	w := &watcher{
+		events: []*dbpb.TaskEvent{
+			{Id: 5, EventType: "task.created", Payload: []byte(`{"id":"a"}`)},
+			{Id: 6, EventType: "task.updated", Payload: []byte(`{"id":"b"}`)},
+			{Id: 7, EventType: "task.done", Payload: []byte(`{"id":"c"}`)},
+		},
+		after: make(chan int64, 1),
+	}
+	hub := live.New(w, live.Config{Replay: 10, Buffer: 10}, slogdiscard.NewDiscardLogger())
+	handler := TaskEvents(slogdiscard.NewDiscardLogger(), hub)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		handler(rw, r.WithContext(mwAuth.WithUser(r.Context(), 7)))
+	}))
+	t.Cleanup(func() {
+		hub.Close()
+		srv.Close()
+	})
+	return srv, w
+}
+
+func TestServerSentEvents(t *testing.T) {
+	srv, w := newServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"?types=task.created,task.done", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "5")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.EqualValues(t, 5, <-w.after)
+
+	var got []string
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() && len(got) < 5 {
+		got = append(got, lines.Text())
+	}
+	assert.Equal(t, []string{
+		"retry: 3000",
+		"",
+		"id: 7",
+		"event: task.done",
+		`data: {"id":"c"}`,
+	}, got)
+}
+
+func TestInvalidLastEventID(t *testing.T) {
+	srv, _ := newServer(t)
+
+	resp, err := http.Get(srv.URL + "?last_event_id=x")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWebSocket(t *testing.T) {
+	srv, w := newServer(t)
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"?last_event_id=6", "", srv.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+
+	var msg Message
+	require.NoError(t, websocket.JSON.Receive(ws, &msg))
+	assert.EqualValues(t, 6, <-w.after)
+	assert.EqualValues(t, 7, msg.ID)
+	assert.Equal(t, "task.done", msg.Type)
+	assert.JSONEq(t, `{"id":"c"}`, string(msg.Event))
+}
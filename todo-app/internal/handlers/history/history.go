@@ -0,0 +1,200 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/etag"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=HistoryStore
+type HistoryStore interface {
+	ListTaskHistory(ctx context.Context, in *dbpb.ListTaskHistoryRequest, opts ...grpc.CallOption) (*dbpb.ListTaskHistoryResponse, error)
+	RevertTask(ctx context.Context, in *dbpb.RevertTaskRequest, opts ...grpc.CallOption) (*dbpb.Task, error)
+}
+
+// Change shows one field of a history entry with its values as plain JSON.
+type Change struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+type Entry struct {
+	ID        int64     `json:"id"`
+	TaskID    int64     `json:"task_id"`
+	ActorID   int64     `json:"actor_id"`
+	Action    string    `json:"action"`
+	Version   int64     `json:"version"`
+	RequestID string    `json:"request_id,omitempty"`
+	Changes   []Change  `json:"changes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Page struct {
+	Items      []Entry `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// rawJSON passes on a value the db service encoded; anything that is not
+// valid JSON is sent as a string rather than breaking the response.
+func rawJSON(s string) json.RawMessage {
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	quoted, _ := json.Marshal(s)
+	return quoted
+}
+
+func toEntry(e *dbpb.TaskHistoryEntry) Entry {
+	entry := Entry{
+		ID:        e.GetId(),
+		TaskID:    e.GetTaskId(),
+		ActorID:   e.GetActorId(),
+		Action:    e.GetAction(),
+		Version:   e.GetVersion(),
+		RequestID: e.GetRequestId(),
+		Changes:   make([]Change, 0, len(e.GetChanges())),
+		CreatedAt: e.GetCreatedAt().AsTime(),
+	}
+	for _, c := range e.GetChanges() {
+		entry.Changes = append(entry.Changes, Change{
+			Field:  c.GetField(),
+			Before: rawJSON(c.GetBefore()),
+			After:  rawJSON(c.GetAfter()),
+		})
+	}
+	return entry
+}
+
+func taskID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id < 1 {
+		Err := "Invalid task ID"
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return 0, false
+	}
+	return id, true
+}
+
+// parseListQuery reads limit and after of GET /tasks/{id}/history.
+func parseListQuery(q url.Values) (*dbpb.ListTaskHistoryRequest, error) {
+	req := &dbpb.ListTaskHistoryRequest{After: q.Get("after")}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		req.Limit = int32(limit)
+	}
+	return req, nil
+}
+
+// GetTaskHistory lists the recorded changes of a task, newest first.
+func GetTaskHistory(log *slog.Logger, storage HistoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/history.go|GetTaskHistory()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		listReq, err := parseListQuery(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		listReq.TaskId = id
+
+		resp, err := storage.ListTaskHistory(r.Context(), listReq)
+		if err != nil {
+			log.Error("Failed to list task history", slog.Int64("task_id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		page := Page{
+			Items:      make([]Entry, 0, len(resp.GetEntries())),
+			NextCursor: resp.GetNextCursor(),
+		}
+		for _, e := range resp.GetEntries() {
+			page.Items = append(page.Items, toEntry(e))
+		}
+		render.JSON(w, r, page)
+	}
+}
+
+// RevertTask sets the task back to an earlier revision. If-Match guards it
+// like any other write.
+func RevertTask(log *slog.Logger, storage HistoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/history.go|RevertTask()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req requests.RevertTaskRequest
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			Err := "request body is empty"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err != nil {
+			Err := "invalid request body"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err := validate.IsValid(req); err != nil {
+			Err := "version must be a positive integer"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		expected, err := etag.ExpectedVersion(r)
+		if err != nil {
+			log.Info(err.Error())
+			httperr.Render(w, r, http.StatusPreconditionFailed, err.Error())
+			return
+		}
+
+		task, err := storage.RevertTask(r.Context(), &dbpb.RevertTaskRequest{
+			Id:              id,
+			Version:         req.Version,
+			ExpectedVersion: expected,
+		})
+		if err != nil {
+			log.Error("Failed to revert task", slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		etag.Set(w, task.GetVersion())
+		render.JSON(w, r, task)
+	}
+}
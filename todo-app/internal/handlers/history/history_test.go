@@ -0,0 +1,121 @@
+package history
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/handlers/history/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func withTaskID(req *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestGetTaskHistory(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+	store := mocks.NewHistoryStore(t)
+	store.On("ListTaskHistory", mock.Anything, &dbpb.ListTaskHistoryRequest{TaskId: 4, Limit: 1}).Return(&dbpb.ListTaskHistoryResponse{
+		Entries: []*dbpb.TaskHistoryEntry{{
+			Id:        12,
+			TaskId:    4,
+			ActorId:   42,
+			Action:    "updated",
+			Version:   3,
+			RequestId: "host/abc-000001",
+			Changes: []*dbpb.FieldChange{
+				{Field: "title", Before: `"old"`, After: `"new"`},
+				{Field: "due_at", Before: "null", After: `"2025-01-02T00:00:00Z"`},
+			},
+			CreatedAt: timestamppb.New(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)),
+		}},
+		NextCursor: "next",
+	}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/tasks/4/history?limit=1", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+
+	GetTaskHistory(log, store).ServeHTTP(rr, withTaskID(req, "4"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"items":[{"id":12,"task_id":4,"actor_id":42,"action":"updated","version":3,
+		"request_id":"host/abc-000001","created_at":"2025-01-01T12:00:00Z","changes":[
+		{"field":"title","before":"old","after":"new"},
+		{"field":"due_at","before":null,"after":"2025-01-02T00:00:00Z"}]}],
+		"next_cursor":"next"}`, rr.Body.String())
+}
+
+func TestRevertTask(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		ifMatch        string
+		mockSetup      func(store *mocks.HistoryStore)
+		expectedStatus int
+		expectedETag   string
+	}{
+		{
+			name:    "Success",
+			body:    `{"version":2}`,
+			ifMatch: `"5"`,
+			mockSetup: func(store *mocks.HistoryStore) {
+				expected := int64(5)
+				store.On("RevertTask", mock.Anything, &dbpb.RevertTaskRequest{Id: 4, Version: 2, ExpectedVersion: &expected}).
+					Return(&dbpb.Task{Id: 4, Version: 6}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"6"`,
+		},
+		{
+			name: "Unknown revision",
+			body: `{"version":9}`,
+			mockSetup: func(store *mocks.HistoryStore) {
+				store.On("RevertTask", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.NotFound, "task has no recorded revision 9"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Missing version",
+			body:           `{}`,
+			mockSetup:      func(store *mocks.HistoryStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewHistoryStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodPost, "/tasks/4/revert", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			RevertTask(log, store).ServeHTTP(rr, withTaskID(req, "4"))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedETag, rr.Header().Get("ETag"))
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=NotificationStore
+type NotificationStore interface {
+	ListNotifications(ctx context.Context, in *dbpb.ListNotificationsRequest, opts ...grpc.CallOption) (*dbpb.ListNotificationsResponse, error)
+	MarkNotificationsRead(ctx context.Context, in *dbpb.MarkNotificationsReadRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetNotificationSettings(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*dbpb.NotificationSettings, error)
+	UpdateNotificationSettings(ctx context.Context, in *dbpb.NotificationSettings, opts ...grpc.CallOption) (*dbpb.NotificationSettings, error)
+}
+
+type NotificationsPage struct {
+	Items       []*dbpb.Notification `json:"items"`
+	UnreadCount int64                `json:"unread_count"`
+	NextCursor  string               `json:"next_cursor,omitempty"`
+}
+
+// decodeBody reads a JSON body into v and validates it; Err describes a
+// valid one.
+func decodeBody(w http.ResponseWriter, r *http.Request, log *slog.Logger, v any, Err string) bool {
+	err := render.DecodeJSON(r.Body, v)
+	if errors.Is(err, io.EOF) {
+		Err := "request body is empty"
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	if err != nil {
+		Err := "invalid request body"
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	if err := validate.IsValid(v); err != nil {
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	return true
+}
+
+// parseListQuery reads unread, limit and after of GET /notifications.
+func parseListQuery(q url.Values) (*dbpb.ListNotificationsRequest, error) {
+	req := &dbpb.ListNotificationsRequest{After: q.Get("after")}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		req.Limit = int32(limit)
+	}
+	if v := q.Get("unread"); v != "" {
+		unread, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("unread must be true or false")
+		}
+		req.UnreadOnly = unread
+	}
+	return req, nil
+}
+
+// ListNotifications returns a page of the caller's inbox, newest first, with
+// the number of unread notifications. ?unread=true leaves out the read ones.
+func ListNotifications(log *slog.Logger, storage NotificationStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/notifications.go|ListNotifications()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		req, err := parseListQuery(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		resp, err := storage.ListNotifications(r.Context(), req)
+		if err != nil {
+			log.Error("Failed to list notifications", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		items := resp.GetNotifications()
+		if items == nil {
+			items = []*dbpb.Notification{}
+		}
+		render.JSON(w, r, NotificationsPage{
+			Items:       items,
+			UnreadCount: resp.GetUnreadCount(),
+			NextCursor:  resp.GetNextCursor(),
+		})
+	}
+}
+
+// MarkRead marks the notifications in ids, or all of them, as read.
+func MarkRead(log *slog.Logger, storage NotificationStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/notifications.go|MarkRead()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		var req requests.MarkNotificationsReadRequest
+		if !decodeBody(w, r, log, &req, "ids must be up to 100 notification ids") {
+			return
+		}
+		if len(req.IDs) == 0 && !req.All {
+			Err := "ids or all is required"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		_, err := storage.MarkNotificationsRead(r.Context(), &dbpb.MarkNotificationsReadRequest{Ids: req.IDs, All: req.All})
+		if err != nil {
+			log.Error("Failed to mark notifications read", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, "notifications marked read")
+	}
+}
+
+func GetSettings(log *slog.Logger, storage NotificationStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/notifications.go|GetSettings()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		settings, err := storage.GetNotificationSettings(r.Context(), &emptypb.Empty{})
+		if err != nil {
+			log.Error("Failed to fetch notification settings", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, settings)
+	}
+}
+
+// UpdateSettings sets the address email reminders go to.
+func UpdateSettings(log *slog.Logger, storage NotificationStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/notifications.go|UpdateSettings()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		var req requests.NotificationSettingsRequest
+		if !decodeBody(w, r, log, &req, "email must be an email address of up to 255 characters") {
+			return
+		}
+
+		settings, err := storage.UpdateNotificationSettings(r.Context(), &dbpb.NotificationSettings{Email: req.Email})
+		if err != nil {
+			log.Error("Failed to update notification settings", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, settings)
+	}
+}
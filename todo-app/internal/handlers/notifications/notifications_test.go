@@ -0,0 +1,174 @@
+package notifications
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/internal/handlers/notifications/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestListNotifications(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(store *mocks.NotificationStore)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Unread page",
+			query: "?unread=true&limit=1",
+			mockSetup: func(store *mocks.NotificationStore) {
+				store.On("ListNotifications", mock.Anything, &dbpb.ListNotificationsRequest{UnreadOnly: true, Limit: 1}).
+					Return(&dbpb.ListNotificationsResponse{
+						Notifications: []*dbpb.Notification{{Id: 9, Kind: "reminder", Title: "Reminder: File taxes", TaskId: 5}},
+						NextCursor:    "c1",
+						UnreadCount:   4,
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[{"id":9,"kind":"reminder","title":"Reminder: File taxes","task_id":5}],"unread_count":4,"next_cursor":"c1"}`,
+		},
+		{
+			name:  "Empty inbox",
+			query: "",
+			mockSetup: func(store *mocks.NotificationStore) {
+				store.On("ListNotifications", mock.Anything, &dbpb.ListNotificationsRequest{}).
+					Return(&dbpb.ListNotificationsResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[],"unread_count":0}`,
+		},
+		{
+			name:           "Invalid unread",
+			query:          "?unread=maybe",
+			mockSetup:      func(store *mocks.NotificationStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewNotificationStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodGet, "/notifications"+tt.query, nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			ListNotifications(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, strings.TrimSuffix(rr.Body.String(), "\n"))
+			}
+		})
+	}
+}
+
+func TestMarkRead(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		expected       *dbpb.MarkNotificationsReadRequest
+		expectedStatus int
+	}{
+		{
+			name:           "Some",
+			body:           `{"ids":[1,2]}`,
+			expected:       &dbpb.MarkNotificationsReadRequest{Ids: []int64{1, 2}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "All",
+			body:           `{"all":true}`,
+			expected:       &dbpb.MarkNotificationsReadRequest{All: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Neither",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewNotificationStore(t)
+			if tt.expected != nil {
+				store.On("MarkNotificationsRead", mock.Anything, tt.expected).Return(&emptypb.Empty{}, nil)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/notifications/read", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			MarkRead(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestUpdateSettings(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(store *mocks.NotificationStore)
+		expectedStatus int
+	}{
+		{
+			name: "Set email",
+			body: `{"email":"ann@example.com"}`,
+			mockSetup: func(store *mocks.NotificationStore) {
+				store.On("UpdateNotificationSettings", mock.Anything, &dbpb.NotificationSettings{Email: "ann@example.com"}).
+					Return(&dbpb.NotificationSettings{Email: "ann@example.com"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Clear email",
+			body: `{"email":""}`,
+			mockSetup: func(store *mocks.NotificationStore) {
+				store.On("UpdateNotificationSettings", mock.Anything, &dbpb.NotificationSettings{}).
+					Return(&dbpb.NotificationSettings{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid email",
+			body:           `{"email":"not an address"}`,
+			mockSetup:      func(store *mocks.NotificationStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewNotificationStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodPut, "/notifications/settings", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			UpdateSettings(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ProjectStore
+type ProjectStore interface {
+	CreateProject(ctx context.Context, in *dbpb.CreateProjectRequest, opts ...grpc.CallOption) (*dbpb.Project, error)
+	GetProject(ctx context.Context, in *dbpb.GetProjectRequest, opts ...grpc.CallOption) (*dbpb.Project, error)
+	ListProjects(ctx context.Context, in *dbpb.ListProjectsRequest, opts ...grpc.CallOption) (*dbpb.ListProjectsResponse, error)
+	UpdateProject(ctx context.Context, in *dbpb.UpdateProjectRequest, opts ...grpc.CallOption) (*dbpb.Project, error)
+	DeleteProject(ctx context.Context, in *dbpb.DeleteProjectRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+func projectID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id < 1 {
+		Err := "Invalid project ID"
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return 0, false
+	}
+	return id, true
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, log *slog.Logger, v any) bool {
+	err := render.DecodeJSON(r.Body, v)
+	if errors.Is(err, io.EOF) {
+		Err := "request body is empty"
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	if err != nil {
+		Err := "invalid request body"
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	if err := validate.IsValid(v); err != nil {
+		Err := "name is required and must be up to 255 characters"
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	return true
+}
+
+func CreateProject(log *slog.Logger, storage ProjectStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/projects.go|CreateProject()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.CreateProjectRequest
+		if !decodeBody(w, r, log, &req) {
+			return
+		}
+
+		project, err := storage.CreateProject(r.Context(), &dbpb.CreateProjectRequest{
+			Name:        req.Name,
+			Description: req.Description,
+		})
+		if err != nil {
+			log.Error("Failed to create project", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, project)
+	}
+}
+
+func GetProject(log *slog.Logger, storage ProjectStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/projects.go|GetProject()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := projectID(w, r, log)
+		if !ok {
+			return
+		}
+
+		project, err := storage.GetProject(r.Context(), &dbpb.GetProjectRequest{Id: id})
+		if err != nil {
+			log.Error("Failed to fetch project", slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, project)
+	}
+}
+
+// ListProjects returns the caller's active projects; ?archived=true adds archived ones.
+func ListProjects(log *slog.Logger, storage ProjectStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/projects.go|ListProjects()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		var includeArchived bool
+		if v := r.URL.Query().Get("archived"); v != "" {
+			var err error
+			includeArchived, err = strconv.ParseBool(v)
+			if err != nil {
+				Err := "archived must be true or false"
+				log.Info(Err)
+				httperr.Render(w, r, http.StatusBadRequest, Err)
+				return
+			}
+		}
+
+		resp, err := storage.ListProjects(r.Context(), &dbpb.ListProjectsRequest{IncludeArchived: includeArchived})
+		if err != nil {
+			log.Error("Failed to list projects", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		projects := resp.GetProjects()
+		if projects == nil {
+			projects = []*dbpb.Project{}
+		}
+		render.JSON(w, r, projects)
+	}
+}
+
+func UpdateProject(log *slog.Logger, storage ProjectStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/projects.go|UpdateProject()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := projectID(w, r, log)
+		if !ok {
+			return
+		}
+		var req requests.UpdateProjectRequest
+		if !decodeBody(w, r, log, &req) {
+			return
+		}
+
+		project, err := storage.UpdateProject(r.Context(), &dbpb.UpdateProjectRequest{
+			Id:          id,
+			Name:        req.Name,
+			Description: req.Description,
+			Archived:    req.Archived,
+		})
+		if err != nil {
+			log.Error("Failed to update project", slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, project)
+	}
+}
+
+// DeleteProject archives the project, or with ?mode=cascade deletes it
+// together with its tasks.
+func DeleteProject(log *slog.Logger, storage ProjectStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/projects.go|DeleteProject()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := projectID(w, r, log)
+		if !ok {
+			return
+		}
+		mode := r.URL.Query().Get("mode")
+		if mode != "" && mode != "archive" && mode != "cascade" {
+			Err := "mode must be archive or cascade"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		_, err := storage.DeleteProject(r.Context(), &dbpb.DeleteProjectRequest{Id: id, Mode: mode})
+		if err != nil {
+			log.Error("Failed to delete project", slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		if mode == "cascade" {
+			render.JSON(w, r, "project deleted")
+			return
+		}
+		render.JSON(w, r, "project archived")
+	}
+}
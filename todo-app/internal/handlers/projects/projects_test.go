@@ -0,0 +1,144 @@
+package projects
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/internal/handlers/projects/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestDeleteProject(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		id             string
+		query          string
+		mockSetup      func(store *mocks.ProjectStore)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Archive by default",
+			id:   "1",
+			mockSetup: func(store *mocks.ProjectStore) {
+				store.On("DeleteProject", mock.Anything, &dbpb.DeleteProjectRequest{Id: 1}).Return(&emptypb.Empty{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"project archived"`,
+		},
+		{
+			name:  "Cascade",
+			id:    "1",
+			query: "?mode=cascade",
+			mockSetup: func(store *mocks.ProjectStore) {
+				store.On("DeleteProject", mock.Anything, &dbpb.DeleteProjectRequest{Id: 1, Mode: "cascade"}).Return(&emptypb.Empty{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"project deleted"`,
+		},
+		{
+			name:           "Unknown mode",
+			id:             "1",
+			query:          "?mode=purge",
+			mockSetup:      func(store *mocks.ProjectStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"ERROR","error":"mode must be archive or cascade"}`,
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			mockSetup:      func(store *mocks.ProjectStore) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"ERROR","error":"Invalid project ID"}`,
+		},
+		{
+			name: "Not found",
+			id:   "7",
+			mockSetup: func(store *mocks.ProjectStore) {
+				store.On("DeleteProject", mock.Anything, mock.Anything).Return(nil, status.Error(codes.NotFound, "project not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":"ERROR","error":"project not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewProjectStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodDelete, "/projects/"+tt.id+tt.query, nil)
+			require.NoError(t, err)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			DeleteProject(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, strings.TrimSuffix(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func TestCreateProject(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(store *mocks.ProjectStore)
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			body: `{"name":"Home","description":"chores"}`,
+			mockSetup: func(store *mocks.ProjectStore) {
+				store.On("CreateProject", mock.Anything, &dbpb.CreateProjectRequest{Name: "Home", Description: "chores"}).
+					Return(&dbpb.Project{Id: 1, Name: "Home", Description: "chores"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Missing name",
+			body:           `{"description":"chores"}`,
+			mockSetup:      func(store *mocks.ProjectStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Empty body",
+			body:           "",
+			mockSetup:      func(store *mocks.ProjectStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewProjectStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodPost, "/projects", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			CreateProject(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -3,7 +3,7 @@
 package mocks
 
 import (
-	requests "todo-app/internal/domain/requests"
+	events "todo-app/internal/domain/events"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -13,17 +13,17 @@ type KafkaProducer struct {
 	mock.Mock
 }
 
-// SendApiEvent provides a mock function with given fields: apiRequest
-func (_m *KafkaProducer) SendApiEvent(apiRequest *requests.ApiRequest) error {
-	ret := _m.Called(apiRequest)
+// SendEvent provides a mock function with given fields: event
+func (_m *KafkaProducer) SendEvent(event *events.Event) error {
+	ret := _m.Called(event)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SendApiEvent")
+		panic("no return value specified for SendEvent")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*requests.ApiRequest) error); ok {
-		r0 = rf(apiRequest)
+	if rf, ok := ret.Get(0).(func(*events.Event) error); ok {
+		r0 = rf(event)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,65 @@
+package read
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rail52/myprojects/dbpb"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// errStreamBroken means the response was already started when listing failed,
+// so the status code can no longer be changed.
+var errStreamBroken = errors.New("task stream interrupted")
+
+func wantsNDJSON(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// streamTasks writes every task matching listReq as one JSON object per line,
+// walking the pages with the returned cursor and flushing after each page.
+func streamTasks(w http.ResponseWriter, r *http.Request, storage TaskLister, listReq *dbpb.ListTasksRequest) error {
+	if listReq.Limit == 0 {
+		listReq.Limit = 100
+	}
+	resp, err := storage.ListTasks(r.Context(), listReq)
+	if err != nil {
+		return err
+	}
+
+	rc := http.NewResponseController(w)
+	// Large exports outlive the server's WriteTimeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	for {
+		for _, task := range resp.GetTasks() {
+			if err := enc.Encode(task); err != nil {
+				return errors.Join(errStreamBroken, err)
+			}
+		}
+		_ = rc.Flush()
+
+		if resp.GetNextCursor() == "" {
+			return nil
+		}
+		listReq.After = resp.GetNextCursor()
+		resp, err = storage.ListTasks(r.Context(), listReq)
+		if err != nil {
+			return errors.Join(errStreamBroken, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package read
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"todo-app/internal/lib/httperr"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Occurrences struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// parseOccurrencesQuery reads from, to and limit of GET /tasks/{id}/occurrences.
+// Missing bounds are left to the db service (from now, one year ahead).
+func parseOccurrencesQuery(id int64, q url.Values) (*dbpb.ListOccurrencesRequest, error) {
+	req := &dbpb.ListOccurrencesRequest{TaskId: id}
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.New("from must be an RFC 3339 timestamp")
+		}
+		req.From = timestamppb.New(t)
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.New("to must be an RFC 3339 timestamp")
+		}
+		req.To = timestamppb.New(t)
+	}
+	if req.From != nil && req.To != nil && req.To.AsTime().Before(req.From.AsTime()) {
+		return nil, errors.New("to must not be before from")
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		req.Limit = int32(limit)
+	}
+	return req, nil
+}
+
+// GetOccurrences expands the upcoming occurrences of a recurring task. Nothing
+// is stored; the next task only appears when the current one is marked done.
+func GetOccurrences(log *slog.Logger, storage dbpb.PostgresClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/handlers.go|GetOccurrences()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id < 1 {
+			Err := "Invalid task ID"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		req, err := parseOccurrencesQuery(id, r.URL.Query())
+		if err != nil {
+			log.Info(err.Error())
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		resp, err := storage.ListOccurrences(r.Context(), req)
+		if err != nil {
+			Err := "Failed to list occurrences of task with ID: " + idStr
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		out := Occurrences{Occurrences: make([]time.Time, 0, len(resp.GetOccurrences()))}
+		for _, ts := range resp.GetOccurrences() {
+			out.Occurrences = append(out.Occurrences, ts.AsTime())
+		}
+		render.JSON(w, r, out)
+	}
+}
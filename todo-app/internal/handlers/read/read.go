@@ -1,21 +1,35 @@
 package read
 
 import (
-	"io"
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
+	"todo-app/internal/domain/events"
 	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/etag"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/rail52/myprojects/dbpb"
-	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+//go:generate go run github.com/vektra/mockery/v2@latest --name=KafkaProducer
 type KafkaProducer interface {
-	SendApiEvent(apiRequest *requests.ApiRequest) error
+	SendEvent(event *events.Event) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TaskLister
+type TaskLister interface {
+	ListTasks(ctx context.Context, in *dbpb.ListTasksRequest, opts ...grpc.CallOption) (*dbpb.ListTasksResponse, error)
 }
 
 func GetTask(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaProducer) http.HandlerFunc {
@@ -30,7 +44,7 @@ func GetTask(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaP
 		if err != nil {
 			Err := "Invalid task ID"
 			log.Info(Err)
-			http.Error(w, Err, http.StatusBadRequest)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
 			return
 		}
 
@@ -39,58 +53,252 @@ func GetTask(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaP
 		})
 		if err != nil {
 			Err := "Failed to fetch task with ID: " + idStr
-			log.Error(Err)
-			http.Error(w, Err, http.StatusBadRequest)
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		etag.Set(w, task.GetVersion())
+		if etag.NoneMatch(r, task.GetVersion()) {
+			w.WriteHeader(http.StatusNotModified)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
 		render.JSON(w, r, &task)
 
-		event := &requests.ApiRequest{
-			Action: "fetched",
-		}
+		event := events.New(r.Context(), events.TaskFetched).WithTaskID(task.GetId())
 
-		if err := kafkaProducer.SendApiEvent(event); err != nil {
+		if err := kafkaProducer.SendEvent(event); err != nil {
 			log.Error("failed to send kafka even", (slog.String("error", err.Error())))
 		}
 	}
 }
-func GetTasks(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaProducer) http.HandlerFunc {
+
+type TasksPage struct {
+	Items      []*dbpb.Task `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+var listSorts = map[string]bool{
+	"created_at": true, "-created_at": true,
+	"updated_at": true, "-updated_at": true,
+	"title": true, "-title": true,
+}
+
+// parseListQuery turns GET /tasks query parameters into a ListTasks request.
+func parseListQuery(q url.Values) (*dbpb.ListTasksRequest, error) {
+	req := &dbpb.ListTasksRequest{
+		After: q.Get("after"),
+		Sort:  q.Get("sort"),
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		req.Limit = int32(limit)
+	}
+	if v := q.Get("is_done"); v != "" {
+		isDone, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("is_done must be true or false")
+		}
+		req.IsDone = &isDone
+	}
+	if v := q.Get("created_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.New("created_after must be an RFC 3339 timestamp")
+		}
+		req.CreatedAfter = timestamppb.New(t)
+	}
+	if v := q.Get("created_before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.New("created_before must be an RFC 3339 timestamp")
+		}
+		req.CreatedBefore = timestamppb.New(t)
+	}
+	req.Tags = q["tag"]
+	if v := q.Get("project_id"); v != "" {
+		projectID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || projectID < 1 {
+			return nil, errors.New("project_id must be a positive integer")
+		}
+		req.ProjectId = projectID
+	}
+	if v := q.Get("trashed"); v != "" {
+		trashed, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("trashed must be true or false")
+		}
+		req.Trashed = trashed
+	}
+	if v := q.Get("parent_id"); v != "" {
+		parentID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parentID < 1 {
+			return nil, errors.New("parent_id must be a positive integer")
+		}
+		req.ParentId = parentID
+	}
+	switch v := q.Get("scope"); v {
+	case "", "own":
+	case "shared":
+		req.Scope = v
+	default:
+		return nil, errors.New("scope must be own or shared")
+	}
+	if v := q.Get("priority"); v != "" {
+		if validate.Var(v, requests.PriorityTag) != nil {
+			return nil, errors.New("priority must be one of low, normal, high, urgent")
+		}
+		req.Priority = v
+	}
+	if v := q.Get("due_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.New("due_after must be an RFC 3339 timestamp")
+		}
+		req.DueAfter = timestamppb.New(t)
+	}
+	if v := q.Get("due_before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.New("due_before must be an RFC 3339 timestamp")
+		}
+		req.DueBefore = timestamppb.New(t)
+	}
+	if req.Sort != "" && !listSorts[req.Sort] {
+		return nil, errors.New("sort must be one of created_at, updated_at, title (prefix with - for descending)")
+	}
+	return req, nil
+}
+
+func GetTasks(log *slog.Logger, storage TaskLister, kafkaProducer KafkaProducer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		fn := "internal/http-server/handlers/handlers.go|GetTasks()"
 		log = log.With(
 			slog.String("fn", fn),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
-		stream, err := storage.GetTasks(r.Context(), &emptypb.Empty{})
+		listReq, err := parseListQuery(r.URL.Query())
 		if err != nil {
-			Err := "GetTasks failed:"
-			log.Error(Err, slog.String("err", err.Error()))
-			http.Error(w, Err, http.StatusBadRequest)
+			log.Info("invalid query", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		for {
-			task, err := stream.Recv()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				Err := "receiving task Error:"
+		if wantsNDJSON(r) {
+			if err := streamTasks(w, r, storage, listReq); err != nil {
+				Err := "GetTasks failed:"
 				log.Error(Err, slog.String("err", err.Error()))
-				http.Error(w, Err, http.StatusInternalServerError)
+				if !errors.Is(err, errStreamBroken) {
+					httperr.RenderGRPC(w, r, err)
+				}
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			render.JSON(w, r, &task)
+			sendAllFetched(r, log, kafkaProducer)
+			return
 		}
 
-		event := &requests.ApiRequest{
-			Action: "allfetched",
+		resp, err := storage.ListTasks(r.Context(), listReq)
+		if err != nil {
+			Err := "GetTasks failed:"
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
 		}
 
-		if err := kafkaProducer.SendApiEvent(event); err != nil {
-			log.Error("failed to send kafka even", (slog.String("error", err.Error())))
+		page := TasksPage{
+			Items:      resp.GetTasks(),
+			NextCursor: resp.GetNextCursor(),
+		}
+		if page.Items == nil {
+			page.Items = []*dbpb.Task{}
+		}
+		render.JSON(w, r, page)
+		sendAllFetched(r, log, kafkaProducer)
+	}
+}
+
+func sendAllFetched(r *http.Request, log *slog.Logger, kafkaProducer KafkaProducer) {
+	event := events.New(r.Context(), events.TasksListed)
+
+	if err := kafkaProducer.SendEvent(event); err != nil {
+		log.Error("failed to send kafka even", (slog.String("error", err.Error())))
+	}
+}
+
+// GetProjectTasks lists the tasks of the project in the URL and accepts the
+// same query parameters as GET /tasks.
+func GetProjectTasks(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaProducer) http.HandlerFunc {
+	getTasks := GetTasks(log, storage, kafkaProducer)
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/handlers.go|GetProjectTasks()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id < 1 {
+			Err := "Invalid project ID"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if _, err := storage.GetProject(r.Context(), &dbpb.GetProjectRequest{Id: id}); err != nil {
+			Err := "Failed to fetch project with ID: " + idStr
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
 		}
+
+		getTasks(w, withQuery(r, "project_id", idStr))
 	}
 }
+
+// GetSubtasks lists the direct subtasks of the task in the URL and accepts the
+// same query parameters as GET /tasks.
+func GetSubtasks(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaProducer) http.HandlerFunc {
+	getTasks := GetTasks(log, storage, kafkaProducer)
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/handlers.go|GetSubtasks()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id < 1 {
+			Err := "Invalid task ID"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if _, err := storage.GetTask(r.Context(), &dbpb.GetTaskRequest{Id: id}); err != nil {
+			Err := "Failed to fetch task with ID: " + idStr
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+
+		getTasks(w, withQuery(r, "parent_id", idStr))
+	}
+}
+
+// GetTrash lists trashed tasks and accepts the same query parameters as
+// GET /tasks.
+func GetTrash(log *slog.Logger, storage TaskLister, kafkaProducer KafkaProducer) http.HandlerFunc {
+	getTasks := GetTasks(log, storage, kafkaProducer)
+	return func(w http.ResponseWriter, r *http.Request) {
+		getTasks(w, withQuery(r, "trashed", "true"))
+	}
+}
+
+// withQuery returns a copy of r whose query has key set to value.
+func withQuery(r *http.Request, key, value string) *http.Request {
+	q := r.URL.Query()
+	q.Set(key, value)
+	r = r.Clone(r.Context())
+	r.URL.RawQuery = q.Encode()
+	return r
+}
@@ -0,0 +1,281 @@
+package read
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/handlers/read/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/rail52/myprojects/dbpb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{
+			name:  "Defaults",
+			query: "",
+		},
+		{
+			name:  "All parameters",
+			query: "limit=20&after=abc&is_done=true&created_after=2025-01-01T00:00:00Z&created_before=2025-02-01T00:00:00Z&sort=-title",
+		},
+		{name: "Limit too big", query: "limit=1000", wantErr: true},
+		{name: "Limit not a number", query: "limit=ten", wantErr: true},
+		{name: "Bad is_done", query: "is_done=maybe", wantErr: true},
+		{name: "Bad created_after", query: "created_after=yesterday", wantErr: true},
+		{name: "Unknown sort", query: "sort=content", wantErr: true},
+		{name: "Unknown priority", query: "priority=asap", wantErr: true},
+		{name: "Bad due_before", query: "due_before=soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			req, err := parseListQuery(q)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, q.Get("after"), req.GetAfter())
+			assert.Equal(t, q.Get("sort"), req.GetSort())
+		})
+	}
+
+	t.Run("Values are converted", func(t *testing.T) {
+		q, _ := url.ParseQuery("limit=20&is_done=false&created_after=2025-01-01T00:00:00Z")
+		req, err := parseListQuery(q)
+		require.NoError(t, err)
+		assert.Equal(t, int32(20), req.GetLimit())
+		require.NotNil(t, req.IsDone)
+		assert.False(t, req.GetIsDone())
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), req.GetCreatedAfter().AsTime())
+		assert.Nil(t, req.GetCreatedBefore())
+	})
+
+	t.Run("Task details filters", func(t *testing.T) {
+		q, _ := url.ParseQuery("tag=backend&tag=api&priority=high&due_before=2025-03-01T00:00:00Z")
+		req, err := parseListQuery(q)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"backend", "api"}, req.GetTags())
+		assert.Equal(t, "high", req.GetPriority())
+		assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), req.GetDueBefore().AsTime())
+		assert.Nil(t, req.GetDueAfter())
+	})
+
+	t.Run("Parent filter", func(t *testing.T) {
+		q, _ := url.ParseQuery("parent_id=7")
+		req, err := parseListQuery(q)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), req.GetParentId())
+
+		q, _ = url.ParseQuery("parent_id=0")
+		_, err = parseListQuery(q)
+		assert.Error(t, err)
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		q, _ := url.ParseQuery("trashed=true")
+		req, err := parseListQuery(q)
+		require.NoError(t, err)
+		assert.True(t, req.GetTrashed())
+
+		q, _ = url.ParseQuery("trashed=yes")
+		_, err = parseListQuery(q)
+		assert.Error(t, err)
+	})
+
+	t.Run("Scope", func(t *testing.T) {
+		q, _ := url.ParseQuery("scope=shared")
+		req, err := parseListQuery(q)
+		require.NoError(t, err)
+		assert.Equal(t, "shared", req.GetScope())
+
+		q, _ = url.ParseQuery("scope=own")
+		req, err = parseListQuery(q)
+		require.NoError(t, err)
+		assert.Empty(t, req.GetScope())
+
+		q, _ = url.ParseQuery("scope=all")
+		_, err = parseListQuery(q)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetTasks(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		accept         string
+		mockSetup      func(lister *mocks.TaskLister, producer *mocks.KafkaProducer)
+		expectedStatus int
+		expectedBody   string
+		expectedType   string
+	}{
+		{
+			name: "JSON page",
+			mockSetup: func(lister *mocks.TaskLister, producer *mocks.KafkaProducer) {
+				lister.On("ListTasks", mock.Anything, mock.Anything).Return(&dbpb.ListTasksResponse{
+					Tasks:      []*dbpb.Task{{Id: 1, Title: "a"}, {Id: 2, Title: "b"}},
+					NextCursor: "next",
+				}, nil)
+				producer.On("SendEvent", mock.MatchedBy(func(e *events.Event) bool { return e.Type == events.TasksListed })).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[{"id":1,"title":"a"},{"id":2,"title":"b"}],"next_cursor":"next"}`,
+			expectedType:   "application/json",
+		},
+		{
+			name: "Empty JSON page",
+			mockSetup: func(lister *mocks.TaskLister, producer *mocks.KafkaProducer) {
+				lister.On("ListTasks", mock.Anything, mock.Anything).Return(&dbpb.ListTasksResponse{}, nil)
+				producer.On("SendEvent", mock.Anything).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[]}`,
+			expectedType:   "application/json",
+		},
+		{
+			name:   "NDJSON walks every page",
+			accept: "application/x-ndjson",
+			mockSetup: func(lister *mocks.TaskLister, producer *mocks.KafkaProducer) {
+				lister.On("ListTasks", mock.Anything, mock.MatchedBy(func(req *dbpb.ListTasksRequest) bool {
+					return req.GetAfter() == ""
+				})).Return(&dbpb.ListTasksResponse{
+					Tasks:      []*dbpb.Task{{Id: 1, Title: "a"}},
+					NextCursor: "c1",
+				}, nil).Once()
+				lister.On("ListTasks", mock.Anything, mock.MatchedBy(func(req *dbpb.ListTasksRequest) bool {
+					return req.GetAfter() == "c1"
+				})).Return(&dbpb.ListTasksResponse{
+					Tasks: []*dbpb.Task{{Id: 2, Title: "b"}},
+				}, nil).Once()
+				producer.On("SendEvent", mock.Anything).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{\"id\":1,\"title\":\"a\"}\n{\"id\":2,\"title\":\"b\"}",
+			expectedType:   "application/x-ndjson",
+		},
+		{
+			name: "GRPC error",
+			mockSetup: func(lister *mocks.TaskLister, producer *mocks.KafkaProducer) {
+				lister.On("ListTasks", mock.Anything, mock.Anything).Return(nil, errors.New("grpc error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"status":"ERROR","error":"internal error"}`,
+		},
+		{
+			name: "Invalid cursor",
+			mockSetup: func(lister *mocks.TaskLister, producer *mocks.KafkaProducer) {
+				lister.On("ListTasks", mock.Anything, mock.Anything).Return(nil, status.Error(codes.InvalidArgument, "invalid cursor"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"ERROR","error":"invalid cursor"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister := mocks.NewTaskLister(t)
+			producer := mocks.NewKafkaProducer(t)
+			tt.mockSetup(lister, producer)
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+
+			GetTasks(log, lister, producer).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, strings.TrimSuffix(rr.Body.String(), "\n"))
+			}
+			if tt.expectedType != "" {
+				assert.Contains(t, rr.Header().Get("Content-Type"), tt.expectedType)
+			}
+		})
+	}
+}
+
+func TestParseOccurrencesQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "Defaults", query: ""},
+		{name: "Window", query: "from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z&limit=10"},
+		{name: "Bad from", query: "from=today", wantErr: true},
+		{name: "To before from", query: "from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z", wantErr: true},
+		{name: "Limit too big", query: "limit=500", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			req, err := parseOccurrencesQuery(3, q)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), req.GetTaskId())
+		})
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "Words", query: "q=weekly+report"},
+		{name: "Paging", query: "q=milk&limit=10&offset=20"},
+		{name: "Missing q", query: "limit=10", wantErr: true},
+		{name: "Blank q", query: "q=+++", wantErr: true},
+		{name: "Too long", query: "q=" + strings.Repeat("a", 201), wantErr: true},
+		{name: "Multibyte", query: "q=" + url.QueryEscape(strings.Repeat("я", 200))},
+		{name: "Multibyte too long", query: "q=" + url.QueryEscape(strings.Repeat("я", 201)), wantErr: true},
+		{name: "Negative offset", query: "q=milk&offset=-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			req, err := parseSearchQuery(q)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(q.Get("q")), req.GetQuery())
+		})
+	}
+}
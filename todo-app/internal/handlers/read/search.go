@@ -0,0 +1,78 @@
+package read
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"todo-app/internal/lib/httperr"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+)
+
+const maxSearchQuery = 200
+
+type SearchPage struct {
+	Items []*dbpb.SearchHit `json:"items"`
+}
+
+// parseSearchQuery reads q, limit and offset of GET /tasks/search.
+func parseSearchQuery(q url.Values) (*dbpb.SearchTasksRequest, error) {
+	req := &dbpb.SearchTasksRequest{Query: strings.TrimSpace(q.Get("q"))}
+	if req.Query == "" {
+		return nil, errors.New("q is required")
+	}
+	if utf8.RuneCountInString(req.Query) > maxSearchQuery {
+		return nil, errors.New("q must be up to 200 characters")
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		req.Limit = int32(limit)
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 || offset > 10000 {
+			return nil, errors.New("offset must be between 0 and 10000")
+		}
+		req.Offset = int32(offset)
+	}
+	return req, nil
+}
+
+// SearchTasks runs a full-text search over the title and content of the
+// caller's tasks. q accepts words, "phrases", prefix*, -exclusions and OR.
+func SearchTasks(log *slog.Logger, storage dbpb.PostgresClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/handlers.go|SearchTasks()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		req, err := parseSearchQuery(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		resp, err := storage.SearchTasks(r.Context(), req)
+		if err != nil {
+			log.Error("SearchTasks failed", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		page := SearchPage{Items: resp.GetHits()}
+		if page.Items == nil {
+			page.Items = []*dbpb.SearchHit{}
+		}
+		render.JSON(w, r, page)
+	}
+}
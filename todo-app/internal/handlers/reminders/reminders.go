@@ -0,0 +1,152 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ReminderStore
+type ReminderStore interface {
+	CreateReminder(ctx context.Context, in *dbpb.CreateReminderRequest, opts ...grpc.CallOption) (*dbpb.Reminder, error)
+	ListReminders(ctx context.Context, in *dbpb.ListRemindersRequest, opts ...grpc.CallOption) (*dbpb.ListRemindersResponse, error)
+	DeleteReminder(ctx context.Context, in *dbpb.DeleteReminderRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+func urlID(w http.ResponseWriter, r *http.Request, log *slog.Logger, param, Err string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id < 1 {
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return 0, false
+	}
+	return id, true
+}
+
+func taskID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return urlID(w, r, log, "id", "Invalid task ID")
+}
+
+func reminderID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return urlID(w, r, log, "rid", "Invalid reminder ID")
+}
+
+// CreateReminder adds a reminder to a task. It fires offset_minutes before the
+// task's due date, and again whenever the due date moves, until the task is
+// done.
+func CreateReminder(log *slog.Logger, storage ReminderStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/reminders.go|CreateReminder()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req requests.CreateReminderRequest
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			Err := "request body is empty"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err != nil {
+			Err := "invalid request body"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err := validate.IsValid(req); err != nil {
+			Err := "offset_minutes must be between 0 and 525600, channels any of email, webhook and in_app"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if len(req.Channels) == 0 {
+			req.Channels = []string{"in_app"}
+		}
+
+		reminder, err := storage.CreateReminder(r.Context(), &dbpb.CreateReminderRequest{
+			TaskId:        id,
+			OffsetMinutes: req.OffsetMinutes,
+			Channels:      req.Channels,
+		})
+		if err != nil {
+			log.Error("Failed to create reminder", slog.Int64("task_id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, reminder)
+	}
+}
+
+// ListReminders returns the task's reminders, the first to fire first.
+func ListReminders(log *slog.Logger, storage ReminderStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/reminders.go|ListReminders()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+
+		resp, err := storage.ListReminders(r.Context(), &dbpb.ListRemindersRequest{TaskId: id})
+		if err != nil {
+			log.Error("Failed to list reminders", slog.Int64("task_id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		reminders := resp.GetReminders()
+		if reminders == nil {
+			reminders = []*dbpb.Reminder{}
+		}
+		render.JSON(w, r, reminders)
+	}
+}
+
+func DeleteReminder(log *slog.Logger, storage ReminderStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/reminders.go|DeleteReminder()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := taskID(w, r, log)
+		if !ok {
+			return
+		}
+		rid, ok := reminderID(w, r, log)
+		if !ok {
+			return
+		}
+
+		_, err := storage.DeleteReminder(r.Context(), &dbpb.DeleteReminderRequest{TaskId: id, Id: rid})
+		if err != nil {
+			log.Error("Failed to delete reminder", slog.Int64("id", rid), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, "reminder deleted")
+	}
+}
@@ -0,0 +1,180 @@
+package reminders
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/internal/handlers/reminders/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func withParams(req *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestCreateReminder(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(store *mocks.ReminderStore)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			body: `{"offset_minutes":60,"channels":["email","webhook"]}`,
+			mockSetup: func(store *mocks.ReminderStore) {
+				store.On("CreateReminder", mock.Anything, &dbpb.CreateReminderRequest{
+					TaskId:        5,
+					OffsetMinutes: 60,
+					Channels:      []string{"email", "webhook"},
+				}).Return(&dbpb.Reminder{Id: 1, TaskId: 5, OffsetMinutes: 60, Channels: []string{"email", "webhook"}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1,"task_id":5,"offset_minutes":60,"channels":["email","webhook"]}`,
+		},
+		{
+			name: "Inbox by default",
+			body: `{"offset_minutes":0}`,
+			mockSetup: func(store *mocks.ReminderStore) {
+				store.On("CreateReminder", mock.Anything, &dbpb.CreateReminderRequest{
+					TaskId:   5,
+					Channels: []string{"in_app"},
+				}).Return(&dbpb.Reminder{Id: 2, TaskId: 5, Channels: []string{"in_app"}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Unknown channel",
+			body:           `{"offset_minutes":10,"channels":["sms"]}`,
+			mockSetup:      func(store *mocks.ReminderStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Negative offset",
+			body:           `{"offset_minutes":-5}`,
+			mockSetup:      func(store *mocks.ReminderStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Too many reminders",
+			body: `{"offset_minutes":10}`,
+			mockSetup: func(store *mocks.ReminderStore) {
+				store.On("CreateReminder", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.FailedPrecondition, "a task can have at most 10 reminders"))
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name: "Duplicate offset",
+			body: `{"offset_minutes":10}`,
+			mockSetup: func(store *mocks.ReminderStore) {
+				store.On("CreateReminder", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.AlreadyExists, "reminder exists"))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewReminderStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodPost, "/tasks/5/reminders", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req = withParams(req, map[string]string{"id": "5"})
+			rr := httptest.NewRecorder()
+
+			CreateReminder(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, strings.TrimSuffix(rr.Body.String(), "\n"))
+			}
+		})
+	}
+}
+
+func TestListReminders(t *testing.T) {
+	store := mocks.NewReminderStore(t)
+	store.On("ListReminders", mock.Anything, &dbpb.ListRemindersRequest{TaskId: 5}).
+		Return(&dbpb.ListRemindersResponse{}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/tasks/5/reminders", nil)
+	require.NoError(t, err)
+	req = withParams(req, map[string]string{"id": "5"})
+	rr := httptest.NewRecorder()
+
+	ListReminders(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "[]", strings.TrimSuffix(rr.Body.String(), "\n"))
+}
+
+func TestDeleteReminder(t *testing.T) {
+	tests := []struct {
+		name           string
+		rid            string
+		mockSetup      func(store *mocks.ReminderStore)
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			rid:  "3",
+			mockSetup: func(store *mocks.ReminderStore) {
+				store.On("DeleteReminder", mock.Anything, &dbpb.DeleteReminderRequest{TaskId: 5, Id: 3}).
+					Return(&emptypb.Empty{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Not found",
+			rid:  "4",
+			mockSetup: func(store *mocks.ReminderStore) {
+				store.On("DeleteReminder", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.NotFound, "reminder not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			rid:            "x",
+			mockSetup:      func(store *mocks.ReminderStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewReminderStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodDelete, "/tasks/5/reminders/"+tt.rid, nil)
+			require.NoError(t, err)
+			req = withParams(req, map[string]string{"id": "5", "rid": tt.rid})
+			rr := httptest.NewRecorder()
+
+			DeleteReminder(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package shares
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+	"todo-app/internal/users"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ShareStore
+type ShareStore interface {
+	ShareTask(ctx context.Context, in *dbpb.ShareRequest, opts ...grpc.CallOption) (*dbpb.Share, error)
+	ListTaskShares(ctx context.Context, in *dbpb.ListSharesRequest, opts ...grpc.CallOption) (*dbpb.ListSharesResponse, error)
+	UnshareTask(ctx context.Context, in *dbpb.UnshareRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ShareProject(ctx context.Context, in *dbpb.ShareRequest, opts ...grpc.CallOption) (*dbpb.Share, error)
+	ListProjectShares(ctx context.Context, in *dbpb.ListSharesRequest, opts ...grpc.CallOption) (*dbpb.ListSharesResponse, error)
+	UnshareProject(ctx context.Context, in *dbpb.UnshareRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserFinder
+type UserFinder interface {
+	Lookup(ctx context.Context, authorization, email string) (users.User, error)
+}
+
+// SharesResponse is the owner of a task or project and who it is shared with.
+type SharesResponse struct {
+	OwnerID int64         `json:"owner_id"`
+	Shares  []*dbpb.Share `json:"shares"`
+}
+
+// target holds the calls for tasks or for projects, which are shared alike.
+type target struct {
+	// name is "task" or "project", for messages.
+	name    string
+	share   func(ctx context.Context, in *dbpb.ShareRequest, opts ...grpc.CallOption) (*dbpb.Share, error)
+	list    func(ctx context.Context, in *dbpb.ListSharesRequest, opts ...grpc.CallOption) (*dbpb.ListSharesResponse, error)
+	unshare func(ctx context.Context, in *dbpb.UnshareRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+func taskTarget(storage ShareStore) target {
+	return target{"task", storage.ShareTask, storage.ListTaskShares, storage.UnshareTask}
+}
+
+func projectTarget(storage ShareStore) target {
+	return target{"project", storage.ShareProject, storage.ListProjectShares, storage.UnshareProject}
+}
+
+func urlID(w http.ResponseWriter, r *http.Request, log *slog.Logger, param, Err string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id < 1 {
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateTaskShare shares a task, with its subtasks, with a user given by email
+// or user_id. Editors can change the task, its comments and attachments;
+// viewers can only read them. Sharing with the same user again changes their
+// role. Only the owner shares.
+func CreateTaskShare(log *slog.Logger, storage ShareStore, finder UserFinder) http.HandlerFunc {
+	return createShare(log, "internal/http-server/handlers/shares.go|CreateTaskShare()", taskTarget(storage), finder)
+}
+
+// ListTaskShares returns the owner of a task and who it is shared with. Every
+// collaborator may see them.
+func ListTaskShares(log *slog.Logger, storage ShareStore) http.HandlerFunc {
+	return listShares(log, "internal/http-server/handlers/shares.go|ListTaskShares()", taskTarget(storage))
+}
+
+// DeleteTaskShare stops sharing a task with a user. The owner removes anybody;
+// collaborators can remove themselves.
+func DeleteTaskShare(log *slog.Logger, storage ShareStore) http.HandlerFunc {
+	return deleteShare(log, "internal/http-server/handlers/shares.go|DeleteTaskShare()", taskTarget(storage))
+}
+
+// CreateProjectShare is CreateTaskShare for projects: the user gets the role
+// on every task of the project.
+func CreateProjectShare(log *slog.Logger, storage ShareStore, finder UserFinder) http.HandlerFunc {
+	return createShare(log, "internal/http-server/handlers/shares.go|CreateProjectShare()", projectTarget(storage), finder)
+}
+
+func ListProjectShares(log *slog.Logger, storage ShareStore) http.HandlerFunc {
+	return listShares(log, "internal/http-server/handlers/shares.go|ListProjectShares()", projectTarget(storage))
+}
+
+func DeleteProjectShare(log *slog.Logger, storage ShareStore) http.HandlerFunc {
+	return deleteShare(log, "internal/http-server/handlers/shares.go|DeleteProjectShare()", projectTarget(storage))
+}
+
+func createShare(log *slog.Logger, fn string, t target, finder UserFinder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := urlID(w, r, log, "id", "Invalid "+t.name+" ID")
+		if !ok {
+			return
+		}
+
+		var req requests.ShareRequest
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			Err := "request body is empty"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err != nil {
+			Err := "invalid request body"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err := validate.IsValid(req); err != nil {
+			Err := "role must be editor or viewer, email a valid address"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if (req.Email == "") == (req.UserID == 0) {
+			Err := "give either email or user_id"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		if req.Email != "" {
+			user, err := finder.Lookup(r.Context(), r.Header.Get("Authorization"), req.Email)
+			if errors.Is(err, users.ErrNotFound) {
+				Err := "no user has this email"
+				log.Info(Err)
+				httperr.Render(w, r, http.StatusNotFound, Err)
+				return
+			}
+			if err != nil {
+				log.Error("Failed to look up user", slog.String("err", err.Error()))
+				httperr.Render(w, r, http.StatusBadGateway, "failed to look up user")
+				return
+			}
+			req.UserID = user.ID
+			req.Email = user.Email
+		}
+
+		share, err := t.share(r.Context(), &dbpb.ShareRequest{
+			Id:     id,
+			UserId: req.UserID,
+			Role:   req.Role,
+			Email:  req.Email,
+		})
+		if err != nil {
+			log.Error("Failed to share "+t.name, slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		log.Info(t.name+" shared", slog.Int64("id", id), slog.Int64("user_id", req.UserID), slog.String("role", req.Role))
+		render.JSON(w, r, share)
+	}
+}
+
+func listShares(log *slog.Logger, fn string, t target) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := urlID(w, r, log, "id", "Invalid "+t.name+" ID")
+		if !ok {
+			return
+		}
+
+		resp, err := t.list(r.Context(), &dbpb.ListSharesRequest{Id: id})
+		if err != nil {
+			log.Error("Failed to list shares", slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		shares := resp.GetShares()
+		if shares == nil {
+			shares = []*dbpb.Share{}
+		}
+		render.JSON(w, r, SharesResponse{OwnerID: resp.GetOwnerId(), Shares: shares})
+	}
+}
+
+func deleteShare(log *slog.Logger, fn string, t target) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := urlID(w, r, log, "id", "Invalid "+t.name+" ID")
+		if !ok {
+			return
+		}
+		userID, ok := urlID(w, r, log, "userId", "Invalid user ID")
+		if !ok {
+			return
+		}
+
+		_, err := t.unshare(r.Context(), &dbpb.UnshareRequest{Id: id, UserId: userID})
+		if err != nil {
+			log.Error("Failed to delete share", slog.Int64("id", id), slog.Int64("user_id", userID), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, "share deleted")
+	}
+}
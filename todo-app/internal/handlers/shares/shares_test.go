@@ -0,0 +1,197 @@
+package shares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/internal/handlers/shares/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+	"todo-app/internal/users"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func withParams(req *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestCreateTaskShare(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(store *mocks.ShareStore, finder *mocks.UserFinder)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "By email",
+			body: `{"email":"Ann@mail.com","role":"editor"}`,
+			mockSetup: func(store *mocks.ShareStore, finder *mocks.UserFinder) {
+				finder.On("Lookup", mock.Anything, "Bearer token", "Ann@mail.com").
+					Return(users.User{ID: 7, Email: "ann@mail.com"}, nil)
+				store.On("ShareTask", mock.Anything, &dbpb.ShareRequest{Id: 5, UserId: 7, Role: "editor", Email: "ann@mail.com"}).
+					Return(&dbpb.Share{UserId: 7, Email: "ann@mail.com", Role: "editor", CreatedBy: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"user_id":7,"email":"ann@mail.com","role":"editor","created_by":1}`,
+		},
+		{
+			name: "By user id",
+			body: `{"user_id":7,"role":"viewer"}`,
+			mockSetup: func(store *mocks.ShareStore, finder *mocks.UserFinder) {
+				store.On("ShareTask", mock.Anything, &dbpb.ShareRequest{Id: 5, UserId: 7, Role: "viewer"}).
+					Return(&dbpb.Share{UserId: 7, Role: "viewer", CreatedBy: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown role",
+			body:           `{"user_id":7,"role":"admin"}`,
+			mockSetup:      func(store *mocks.ShareStore, finder *mocks.UserFinder) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Email and user id",
+			body:           `{"user_id":7,"email":"ann@mail.com","role":"viewer"}`,
+			mockSetup:      func(store *mocks.ShareStore, finder *mocks.UserFinder) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "No user",
+			body:           `{"role":"viewer"}`,
+			mockSetup:      func(store *mocks.ShareStore, finder *mocks.UserFinder) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown email",
+			body: `{"email":"nobody@mail.com","role":"viewer"}`,
+			mockSetup: func(store *mocks.ShareStore, finder *mocks.UserFinder) {
+				finder.On("Lookup", mock.Anything, "Bearer token", "nobody@mail.com").
+					Return(users.User{}, users.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":"ERROR","error":"no user has this email"}`,
+		},
+		{
+			name: "Auth service down",
+			body: `{"email":"ann@mail.com","role":"viewer"}`,
+			mockSetup: func(store *mocks.ShareStore, finder *mocks.UserFinder) {
+				finder.On("Lookup", mock.Anything, "Bearer token", "ann@mail.com").
+					Return(users.User{}, errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			name: "Not the owner",
+			body: `{"user_id":7,"role":"viewer"}`,
+			mockSetup: func(store *mocks.ShareStore, finder *mocks.UserFinder) {
+				store.On("ShareTask", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.PermissionDenied, "owner role required"))
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewShareStore(t)
+			finder := mocks.NewUserFinder(t)
+			tt.mockSetup(store, finder)
+
+			req, err := http.NewRequest(http.MethodPost, "/tasks/5/shares", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req = withParams(req, map[string]string{"id": "5"})
+			rr := httptest.NewRecorder()
+
+			CreateTaskShare(log, store, finder).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, strings.TrimSuffix(rr.Body.String(), "\n"))
+			}
+		})
+	}
+}
+
+func TestListProjectShares(t *testing.T) {
+	store := mocks.NewShareStore(t)
+	store.On("ListProjectShares", mock.Anything, &dbpb.ListSharesRequest{Id: 3}).
+		Return(&dbpb.ListSharesResponse{OwnerId: 1}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/projects/3/shares", nil)
+	require.NoError(t, err)
+	req = withParams(req, map[string]string{"id": "3"})
+	rr := httptest.NewRecorder()
+
+	ListProjectShares(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"owner_id":1,"shares":[]}`, strings.TrimSuffix(rr.Body.String(), "\n"))
+}
+
+func TestDeleteTaskShare(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         string
+		mockSetup      func(store *mocks.ShareStore)
+		expectedStatus int
+	}{
+		{
+			name:   "Success",
+			userID: "7",
+			mockSetup: func(store *mocks.ShareStore) {
+				store.On("UnshareTask", mock.Anything, &dbpb.UnshareRequest{Id: 5, UserId: 7}).
+					Return(&emptypb.Empty{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Not shared",
+			userID: "8",
+			mockSetup: func(store *mocks.ShareStore) {
+				store.On("UnshareTask", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.NotFound, "share not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid user ID",
+			userID:         "x",
+			mockSetup:      func(store *mocks.ShareStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewShareStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodDelete, "/tasks/5/shares/"+tt.userID, nil)
+			require.NoError(t, err)
+			req = withParams(req, map[string]string{"id": "5", "userId": tt.userID})
+			rr := httptest.NewRecorder()
+
+			DeleteTaskShare(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
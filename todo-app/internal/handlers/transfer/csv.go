@@ -0,0 +1,160 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvHeader names the columns of exports. Imports find the columns by name,
+// in any order; only title is required.
+var csvHeader = []string{
+	"id", "external_id", "title", "content", "description", "is_done",
+	"priority", "tags", "due_at", "project_id", "rrule", "created_at",
+}
+
+// tagSeparator joins the tags of a task in its tags column.
+const tagSeparator = ";"
+
+// formulaPrefixes start the cells spreadsheets run as formulas. Exports put a
+// ' before them, which spreadsheets hide, and before a leading ' so that
+// imports can take it off again.
+const formulaPrefixes = "=+-@\t\r'"
+
+// escapeCell keeps spreadsheets from running v as a formula.
+func escapeCell(v string) string {
+	if v != "" && strings.ContainsRune(formulaPrefixes, rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// unescapeCell undoes escapeCell.
+func unescapeCell(v string) string {
+	if len(v) > 1 && v[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(v[1])) {
+		return v[1:]
+	}
+	return v
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	e := &csvEncoder{w: csv.NewWriter(w)}
+	e.w.Write(csvHeader)
+	return e
+}
+
+func (e *csvEncoder) write(rec Record) error {
+	return e.w.Write([]string{
+		formatID(rec.ID),
+		escapeCell(rec.ExternalID),
+		escapeCell(rec.Title),
+		escapeCell(rec.Content),
+		escapeCell(rec.Description),
+		strconv.FormatBool(rec.IsDone),
+		escapeCell(rec.Priority),
+		escapeCell(strings.Join(rec.Tags, tagSeparator)),
+		formatTime(rec.DueAt),
+		formatID(rec.ProjectID),
+		escapeCell(rec.RRule),
+		formatTime(rec.CreatedAt),
+	})
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) close() error { return e.flush() }
+
+// parseCSV reads records from a CSV file with a header line. Values that do
+// not parse are reported in the row of their line; a file that is not CSV
+// at all fails as a whole.
+func parseCSV(r io.Reader) ([]row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheets like to start UTF-8 files with a byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("CSV header has no title column")
+	}
+
+	var rows []row
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(fields) {
+				return unescapeCell(strings.TrimSpace(fields[i]))
+			}
+			return ""
+		}
+		rw := row{line: line}
+		rw.rec, rw.err = csvRecord(get)
+		rows = append(rows, rw)
+	}
+}
+
+func csvRecord(get func(name string) string) (Record, error) {
+	rec := Record{
+		ExternalID:  get("external_id"),
+		Title:       get("title"),
+		Content:     get("content"),
+		Description: get("description"),
+		Priority:    strings.ToLower(get("priority")),
+		RRule:       get("rrule"),
+	}
+	for _, tag := range strings.Split(get("tags"), tagSeparator) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			rec.Tags = append(rec.Tags, tag)
+		}
+	}
+	if v := get("is_done"); v != "" {
+		done, err := strconv.ParseBool(v)
+		if err != nil {
+			return Record{}, errors.New("is_done must be true or false")
+		}
+		rec.IsDone = done
+	}
+	if v := get("due_at"); v != "" {
+		dueAt, err := parseTime(v)
+		if err != nil {
+			return Record{}, errors.New("due_at must be an RFC 3339 time or a YYYY-MM-DD date")
+		}
+		rec.DueAt = &dueAt
+	}
+	if v := get("project_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			return Record{}, errors.New("project_id must be a positive integer")
+		}
+		rec.ProjectID = id
+	}
+	return rec, nil
+}
@@ -0,0 +1,100 @@
+package transfer
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"todo-app/internal/lib/httperr"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rail52/myprojects/dbpb"
+)
+
+// encoder writes records in one format.
+type encoder interface {
+	write(rec Record) error
+	// flush sends what was written so far to the client.
+	flush() error
+	// close ends the file.
+	close() error
+}
+
+func newEncoder(format string, w io.Writer) encoder {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w)
+	case FormatTodoTxt:
+		return newTodoTxtEncoder(w)
+	default:
+		return newJSONEncoder(w)
+	}
+}
+
+// Export streams every live task of the user as a file in ?format=json
+// (the default), csv or todotxt, oldest first. It walks the pages of
+// ListTasks and flushes after each, so exports of any size start at once.
+func Export(log *slog.Logger, storage TaskLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/transfer.go|Export()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = FormatJSON
+		}
+		if contentTypes[format] == "" {
+			Err := "format must be json, csv or todotxt"
+			log.Info(Err, slog.String("format", format))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		listReq := &dbpb.ListTasksRequest{Limit: 100, Sort: "created_at"}
+		resp, err := storage.ListTasks(r.Context(), listReq)
+		if err != nil {
+			log.Error("Failed to list tasks", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		// Large exports outlive the server's WriteTimeout.
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", contentTypes[format]+"; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+fileNames[format]+`"`)
+		w.WriteHeader(http.StatusOK)
+		enc := newEncoder(format, w)
+		for {
+			for _, task := range resp.GetTasks() {
+				if err := enc.write(toRecord(task)); err != nil {
+					log.Error("export interrupted", slog.String("err", err.Error()))
+					return
+				}
+			}
+			if err := enc.flush(); err != nil {
+				log.Error("export interrupted", slog.String("err", err.Error()))
+				return
+			}
+			_ = rc.Flush()
+
+			if resp.GetNextCursor() == "" {
+				break
+			}
+			listReq.After = resp.GetNextCursor()
+			resp, err = storage.ListTasks(r.Context(), listReq)
+			if err != nil {
+				// The status is sent already; the file ends unfinished.
+				log.Error("export interrupted", slog.String("err", err.Error()))
+				return
+			}
+		}
+		if err := enc.close(); err != nil {
+			log.Error("export interrupted", slog.String("err", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"todo-app/internal/lib/httperr"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 10000
+	// importChunk is how many tasks go to the db service in one call, which
+	// is the most ImportTasks takes.
+	importChunk = 500
+)
+
+// Row statuses. In dry runs, created means the task would be created.
+const (
+	StatusCreated = "created"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// RowResult reports what happened to one task of an import. Row is the line
+// of the file for CSV and Todo.txt, and the position in the array for JSON.
+type RowResult struct {
+	Row        int    `json:"row"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
+	// TaskID is the created task, or for skipped rows the one that was
+	// imported before.
+	TaskID int64 `json:"task_id,omitempty"`
+}
+
+type ImportResponse struct {
+	DryRun  bool        `json:"dry_run"`
+	Created int         `json:"created"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Rows    []RowResult `json:"rows"`
+}
+
+// importFormat takes the format from ?format=, or else from the Content-Type.
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	for format, contentType := range contentTypes {
+		if mediaType == contentType {
+			return format
+		}
+	}
+	return ""
+}
+
+func parse(format string, r io.Reader) ([]row, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatTodoTxt:
+		return parseTodoTxt(r)
+	default:
+		return parseJSON(r)
+	}
+}
+
+// Import creates the tasks of a file in the format of GET /tasks/export.
+// With ?dry_run=true it only reports what would happen. Rows that fail do
+// not stop the others; importing the same file again skips the rows that
+// were imported before, so a partly failed import can simply be repeated.
+func Import(log *slog.Logger, storage Importer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/transfer.go|Import()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format := importFormat(r)
+		if contentTypes[format] == "" {
+			Err := "format must be json, csv or todotxt"
+			log.Info(Err, slog.String("format", format))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		dryRun := false
+		if v := r.URL.Query().Get("dry_run"); v != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				Err := "dry_run must be true or false"
+				log.Info(Err)
+				httperr.Render(w, r, http.StatusBadRequest, Err)
+				return
+			}
+		}
+
+		rows, err := parse(format, http.MaxBytesReader(w, r.Body, maxImportBytes))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			Err := fmt.Sprintf("file is larger than %d MiB", maxImportBytes>>20)
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusRequestEntityTooLarge, Err)
+			return
+		}
+		if err != nil {
+			log.Info("invalid import file", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(rows) == 0 || len(rows) > maxImportRows {
+			Err := fmt.Sprintf("file must hold 1 to %d tasks", maxImportRows)
+			log.Info(Err, slog.Int("rows", len(rows)))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		resp := ImportResponse{DryRun: dryRun, Rows: make([]RowResult, len(rows))}
+		var tasks []*dbpb.ImportTask
+		// positions maps tasks to their row.
+		var positions []int
+		for i, rw := range rows {
+			resp.Rows[i].Row = rw.line
+			err := rw.err
+			if err == nil {
+				err = checkRecord(rw.rec)
+			}
+			if err != nil {
+				resp.Rows[i].Status = StatusFailed
+				resp.Rows[i].Error = err.Error()
+				continue
+			}
+			task := toImportTask(rw.rec)
+			resp.Rows[i].ExternalID = task.GetExternalId()
+			tasks = append(tasks, task)
+			positions = append(positions, i)
+		}
+
+		for start := 0; start < len(tasks); start += importChunk {
+			end := min(start+importChunk, len(tasks))
+			out, err := storage.ImportTasks(r.Context(), &dbpb.ImportTasksRequest{Tasks: tasks[start:end], DryRun: dryRun})
+			if err != nil {
+				log.Error("Failed to import tasks", slog.Int("imported", start), slog.String("err", err.Error()))
+				if start == 0 {
+					httperr.RenderGRPC(w, r, err)
+					return
+				}
+				// The chunks before are committed. Report the rest as failed;
+				// importing the file again picks up from here.
+				_, msg := httperr.FromGRPC(err)
+				for _, i := range positions[start:] {
+					resp.Rows[i].Status = StatusFailed
+					resp.Rows[i].Error = msg
+				}
+				break
+			}
+			for j, res := range out.GetResults() {
+				result := &resp.Rows[positions[start+j]]
+				switch {
+				case res.GetCode() != int32(codes.OK):
+					result.Status = StatusFailed
+					result.Error = res.GetError()
+				case res.GetSkipped():
+					result.Status = StatusSkipped
+					result.TaskID = res.GetTask().GetId()
+				default:
+					result.Status = StatusCreated
+					result.TaskID = res.GetTask().GetId()
+				}
+			}
+		}
+
+		for _, result := range resp.Rows {
+			switch result.Status {
+			case StatusCreated:
+				resp.Created++
+			case StatusSkipped:
+				resp.Skipped++
+			default:
+				resp.Failed++
+			}
+		}
+		log.Info("tasks imported", slog.Bool("dry_run", dryRun), slog.Int("created", resp.Created), slog.Int("skipped", resp.Skipped), slog.Int("failed", resp.Failed))
+		render.JSON(w, r, resp)
+	}
+}
@@ -0,0 +1,59 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonEncoder writes a JSON array of records, one per line.
+type jsonEncoder struct {
+	w *bufio.Writer
+	n int
+}
+
+func newJSONEncoder(w io.Writer) *jsonEncoder {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[")
+	return &jsonEncoder{w: bw}
+}
+
+func (e *jsonEncoder) write(rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if e.n > 0 {
+		e.w.WriteString(",")
+	}
+	e.n++
+	e.w.WriteString("\n")
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonEncoder) flush() error { return e.w.Flush() }
+
+func (e *jsonEncoder) close() error {
+	e.w.WriteString("\n]\n")
+	return e.w.Flush()
+}
+
+// parseJSON reads an array of records. Entries that are not a record are
+// reported in their row rather than failing the whole file.
+func parseJSON(r io.Reader) ([]row, error) {
+	var raws []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raws); err != nil {
+		return nil, fmt.Errorf("file must be a JSON array of tasks: %w", err)
+	}
+	rows := make([]row, 0, len(raws))
+	for i, raw := range raws {
+		rw := row{line: i + 1}
+		if err := json.Unmarshal(raw, &rw.rec); err != nil {
+			rw.err = fmt.Errorf("invalid task: %w", err)
+		}
+		rows = append(rows, rw)
+	}
+	return rows, nil
+}
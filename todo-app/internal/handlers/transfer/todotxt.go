@@ -0,0 +1,181 @@
+package transfer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// dateLayout is the date format of Todo.txt.
+const dateLayout = "2006-01-02"
+
+// Todo.txt priorities are letters. Tasks of normal priority are written
+// without one, and any letter after C reads as low.
+var todoTxtLetters = map[string]string{
+	"urgent": "A",
+	"high":   "B",
+	"normal": "C",
+	"low":    "D",
+}
+
+func todoTxtPriority(letter string) string {
+	switch letter {
+	case "A":
+		return "urgent"
+	case "B":
+		return "high"
+	case "C":
+		return "normal"
+	}
+	return "low"
+}
+
+// todoTxtEncoder writes one task per line in the format of
+// https://github.com/todotxt/todo.txt. Tags become +projects, and the due
+// date, recurrence and external id key:value pairs. Content, description
+// and project have no place in a line and are left out.
+type todoTxtEncoder struct {
+	w *bufio.Writer
+}
+
+func newTodoTxtEncoder(w io.Writer) *todoTxtEncoder {
+	return &todoTxtEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *todoTxtEncoder) write(rec Record) error {
+	_, err := e.w.WriteString(formatTodoTxt(rec) + "\n")
+	return err
+}
+
+func (e *todoTxtEncoder) flush() error { return e.w.Flush() }
+
+func (e *todoTxtEncoder) close() error { return e.w.Flush() }
+
+func formatTodoTxt(rec Record) string {
+	var parts []string
+	letter := todoTxtLetters[rec.Priority]
+	if rec.IsDone {
+		parts = append(parts, "x")
+	} else {
+		if letter != "" && letter != "C" {
+			parts = append(parts, "("+letter+")")
+		}
+		if rec.CreatedAt != nil {
+			parts = append(parts, rec.CreatedAt.UTC().Format(dateLayout))
+		}
+	}
+	parts = append(parts, strings.Fields(rec.Title)...)
+	for _, tag := range rec.Tags {
+		parts = append(parts, "+"+strings.Join(strings.Fields(tag), "_"))
+	}
+	if rec.DueAt != nil {
+		parts = append(parts, "due:"+formatTime(rec.DueAt))
+	}
+	if rec.RRule != "" {
+		parts = append(parts, "rrule:"+rec.RRule)
+	}
+	// Done tasks keep their priority as a pri: pair, as the spec advises.
+	if rec.IsDone && letter != "" && letter != "C" {
+		parts = append(parts, "pri:"+letter)
+	}
+	if rec.ExternalID != "" && !strings.ContainsFunc(rec.ExternalID, unicode.IsSpace) {
+		parts = append(parts, "ext:"+rec.ExternalID)
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseTodoTxt reads a Todo.txt file, one task per non-blank line.
+func parseTodoTxt(r io.Reader) ([]row, error) {
+	sc := bufio.NewScanner(r)
+	var rows []row
+	line := 0
+	for sc.Scan() {
+		line++
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		rw := row{line: line}
+		rw.rec, rw.err = parseTodoTxtLine(sc.Text())
+		rows = append(rows, rw)
+	}
+	if err := sc.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("line %d is too long", line+1)
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseTodoTxtLine reads one task. +project and @context words become tags,
+// and due:, rrule:, pri: and ext: pairs the fields of the same meaning; the
+// rest of the line is the title. Completion and creation dates are dropped.
+func parseTodoTxtLine(line string) (Record, error) {
+	fields := strings.Fields(strings.TrimPrefix(line, "\ufeff"))
+	rec := Record{}
+	if len(fields) > 0 && fields[0] == "x" {
+		rec.IsDone = true
+		fields = fields[1:]
+		for i := 0; i < 2 && len(fields) > 0 && isDate(fields[0]); i++ {
+			fields = fields[1:]
+		}
+	} else {
+		if len(fields) > 0 && isPriority(fields[0]) {
+			rec.Priority = todoTxtPriority(fields[0][1:2])
+			fields = fields[1:]
+		}
+		if len(fields) > 0 && isDate(fields[0]) {
+			fields = fields[1:]
+		}
+	}
+
+	var words []string
+	for _, f := range fields {
+		if len(f) > 1 && (f[0] == '+' || f[0] == '@') {
+			if tag := f[1:]; !slices.Contains(rec.Tags, tag) {
+				rec.Tags = append(rec.Tags, tag)
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(f, ":")
+		// URLs are not key:value pairs.
+		if !ok || value == "" || strings.HasPrefix(value, "//") {
+			words = append(words, f)
+			continue
+		}
+		switch key {
+		case "due":
+			dueAt, err := parseTime(value)
+			if err != nil {
+				return Record{}, errors.New("due: must be an RFC 3339 time or a YYYY-MM-DD date")
+			}
+			rec.DueAt = &dueAt
+		case "rrule":
+			rec.RRule = value
+		case "pri":
+			if len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z' {
+				rec.Priority = todoTxtPriority(value)
+			}
+		case "ext":
+			rec.ExternalID = value
+		default:
+			words = append(words, f)
+		}
+	}
+	rec.Title = strings.Join(words, " ")
+	return rec, nil
+}
+
+func isPriority(s string) bool {
+	return len(s) == 3 && s[0] == '(' && s[1] >= 'A' && s[1] <= 'Z' && s[2] == ')'
+}
+
+func isDate(s string) bool {
+	_, err := time.Parse(dateLayout, s)
+	return err == nil
+}
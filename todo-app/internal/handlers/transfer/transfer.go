@@ -0,0 +1,208 @@
+// Package transfer moves tasks in and out of todo-app as JSON, CSV or
+// Todo.txt files, for migrating from and to other tools.
+//
+// Every format is read into and written from Record. Imports are keyed by
+// external id: a task whose external id the user already has is skipped, so
+// the same file can be imported again without creating duplicates. Records
+// without an external id get one derived from their content.
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Formats of GET /tasks/export and POST /tasks/import.
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatTodoTxt = "todotxt"
+)
+
+// Limits of POST /tasks, which imported tasks have to keep to as well.
+const (
+	maxText      = 255
+	maxTags      = 20
+	maxTagLength = 50
+)
+
+var contentTypes = map[string]string{
+	FormatJSON:    "application/json",
+	FormatCSV:     "text/csv",
+	FormatTodoTxt: "text/plain",
+}
+
+var fileNames = map[string]string{
+	FormatJSON:    "tasks.json",
+	FormatCSV:     "tasks.csv",
+	FormatTodoTxt: "todo.txt",
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TaskLister
+type TaskLister interface {
+	ListTasks(ctx context.Context, in *dbpb.ListTasksRequest, opts ...grpc.CallOption) (*dbpb.ListTasksResponse, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Importer
+type Importer interface {
+	ImportTasks(ctx context.Context, in *dbpb.ImportTasksRequest, opts ...grpc.CallOption) (*dbpb.ImportTasksResponse, error)
+}
+
+// Record is a task as it is exported and imported. ID and CreatedAt are
+// exported for reference only; imports ignore them.
+type Record struct {
+	ID          int64      `json:"id,omitempty"`
+	ExternalID  string     `json:"external_id,omitempty"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content,omitempty"`
+	Description string     `json:"description,omitempty"`
+	IsDone      bool       `json:"is_done"`
+	Priority    string     `json:"priority,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	ProjectID   int64      `json:"project_id,omitempty"`
+	RRule       string     `json:"rrule,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+func toRecord(task *dbpb.Task) Record {
+	rec := Record{
+		ID:          task.GetId(),
+		ExternalID:  task.GetExternalId(),
+		Title:       task.GetTitle(),
+		Content:     task.GetContent(),
+		Description: task.GetDescription(),
+		IsDone:      task.GetIsDone(),
+		Priority:    task.GetPriority(),
+		Tags:        task.GetTags(),
+		ProjectID:   task.GetProjectId(),
+		RRule:       task.GetRrule(),
+	}
+	if task.GetDueAt() != nil {
+		dueAt := task.GetDueAt().AsTime()
+		rec.DueAt = &dueAt
+	}
+	if task.GetCreatedAt() != nil {
+		createdAt := task.GetCreatedAt().AsTime()
+		rec.CreatedAt = &createdAt
+	}
+	return rec
+}
+
+// toImportTask converts a checked record into its db service form.
+func toImportTask(rec Record) *dbpb.ImportTask {
+	var dueAt *timestamppb.Timestamp
+	if rec.DueAt != nil {
+		dueAt = timestamppb.New(*rec.DueAt)
+	}
+	externalID := rec.ExternalID
+	if externalID == "" {
+		externalID = contentID(rec)
+	}
+	return &dbpb.ImportTask{
+		ExternalId: externalID,
+		IsDone:     rec.IsDone,
+		Task: &dbpb.CreateTaskRequest{
+			Title:       rec.Title,
+			Content:     rec.Content,
+			DueAt:       dueAt,
+			Priority:    rec.Priority,
+			Tags:        rec.Tags,
+			Description: rec.Description,
+			ProjectId:   rec.ProjectID,
+			Rrule:       rec.RRule,
+		},
+	}
+}
+
+// contentID derives the external id of a record that has none from the
+// fields that are imported, so importing the same file twice still skips
+// the tasks of the first import.
+func contentID(rec Record) string {
+	rec.ID = 0
+	rec.CreatedAt = nil
+	if rec.DueAt != nil {
+		dueAt := rec.DueAt.UTC()
+		rec.DueAt = &dueAt
+	}
+	data, _ := json.Marshal(rec)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// row is a record read from an import, or why it could not be read. line is
+// the line of the file for CSV and Todo.txt, and the position in the array
+// for JSON, counting from 1.
+type row struct {
+	line int
+	rec  Record
+	err  error
+}
+
+// checkRecord applies the rules of POST /tasks to an imported record, except
+// that content may be empty: few tools have a field for it.
+func checkRecord(rec Record) error {
+	switch {
+	case strings.TrimSpace(rec.Title) == "":
+		return errors.New("title is required")
+	case utf8.RuneCountInString(rec.Title) > maxText:
+		return fmt.Errorf("title must be at most %d characters", maxText)
+	case utf8.RuneCountInString(rec.Content) > maxText:
+		return fmt.Errorf("content must be at most %d characters", maxText)
+	case rec.Priority != "" && todoTxtLetters[rec.Priority] == "":
+		return errors.New("priority must be low, normal, high or urgent")
+	case len(rec.Tags) > maxTags:
+		return fmt.Errorf("at most %d tags are allowed", maxTags)
+	case utf8.RuneCountInString(rec.RRule) > maxText:
+		return fmt.Errorf("rrule must be at most %d characters", maxText)
+	case utf8.RuneCountInString(rec.ExternalID) > maxText:
+		return fmt.Errorf("external_id must be at most %d characters", maxText)
+	case rec.ProjectID < 0:
+		return errors.New("project_id must be a positive integer")
+	}
+	for _, tag := range rec.Tags {
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return fmt.Errorf("tags must be 1 to %d characters", maxTagLength)
+		}
+	}
+	return nil
+}
+
+func formatID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+// formatTime writes dates without a time of day as dates.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	u := t.UTC()
+	if u.Equal(u.Truncate(24 * time.Hour)) {
+		return u.Format(dateLayout)
+	}
+	return u.Format(time.RFC3339)
+}
+
+// parseTime reads an RFC 3339 time, or a date as midnight UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
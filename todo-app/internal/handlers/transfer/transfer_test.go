@@ -0,0 +1,379 @@
+package transfer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/handlers/transfer/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var tasks = []*dbpb.Task{
+	{
+		Id:         1,
+		ExternalId: "todoist-7",
+		Title:      "File taxes",
+		Content:    "by April",
+		Priority:   "high",
+		Tags:       []string{"home", "money"},
+		DueAt:      timestamppb.New(time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)),
+		CreatedAt:  timestamppb.New(time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)),
+	},
+	{
+		Id:        2,
+		Title:     "Call Ann",
+		IsDone:    true,
+		Priority:  "urgent",
+		CreatedAt: timestamppb.New(time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)),
+	},
+}
+
+func TestExport(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedType string
+		expectedBody string
+	}{
+		{
+			name:         "JSON",
+			query:        "",
+			expectedType: "application/json; charset=utf-8",
+			expectedBody: "[\n" +
+				`{"id":1,"external_id":"todoist-7","title":"File taxes","content":"by April","is_done":false,"priority":"high","tags":["home","money"],"due_at":"2025-04-15T00:00:00Z","created_at":"2025-03-01T09:00:00Z"},` + "\n" +
+				`{"id":2,"title":"Call Ann","is_done":true,"priority":"urgent","created_at":"2025-03-02T09:00:00Z"}` +
+				"\n]\n",
+		},
+		{
+			name:         "CSV",
+			query:        "?format=csv",
+			expectedType: "text/csv; charset=utf-8",
+			expectedBody: "id,external_id,title,content,description,is_done,priority,tags,due_at,project_id,rrule,created_at\n" +
+				"1,todoist-7,File taxes,by April,,false,high,home;money,2025-04-15,,,2025-03-01T09:00:00Z\n" +
+				"2,,Call Ann,,,true,urgent,,,,,2025-03-02T09:00:00Z\n",
+		},
+		{
+			name:         "Todo.txt",
+			query:        "?format=todotxt",
+			expectedType: "text/plain; charset=utf-8",
+			expectedBody: "(B) 2025-03-01 File taxes +home +money due:2025-04-15 ext:todoist-7\n" +
+				"x Call Ann pri:A\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister := mocks.NewTaskLister(t)
+			lister.On("ListTasks", mock.Anything, &dbpb.ListTasksRequest{Limit: 100, Sort: "created_at"}).
+				Return(&dbpb.ListTasksResponse{Tasks: tasks[:1], NextCursor: "c1"}, nil)
+			lister.On("ListTasks", mock.Anything, &dbpb.ListTasksRequest{Limit: 100, Sort: "created_at", After: "c1"}).
+				Return(&dbpb.ListTasksResponse{Tasks: tasks[1:]}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/tasks/export"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			Export(log, lister).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.expectedType, rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	t.Run("Unknown format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/export?format=xml", nil)
+		rr := httptest.NewRecorder()
+		Export(log, mocks.NewTaskLister(t)).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Storage unavailable", func(t *testing.T) {
+		lister := mocks.NewTaskLister(t)
+		lister.On("ListTasks", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unavailable, "db down"))
+
+		req := httptest.NewRequest(http.MethodGet, "/tasks/export", nil)
+		rr := httptest.NewRecorder()
+		Export(log, lister).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+}
+
+func TestImport(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		url            string
+		contentType    string
+		body           string
+		mockSetup      func(importer *mocks.Importer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "JSON",
+			url:  "/tasks/import?format=json",
+			body: `[
+				{"external_id":"todoist-7","title":"File taxes","priority":"high","due_at":"2025-04-15T00:00:00Z"},
+				{"external_id":"todoist-8","title":"Call Ann","is_done":true},
+				{"external_id":"todoist-9","title":"Pay rent","project_id":40},
+				{"external_id":"todoist-10","title":""},
+				{"title":7}
+			]`,
+			mockSetup: func(importer *mocks.Importer) {
+				importer.On("ImportTasks", mock.Anything, &dbpb.ImportTasksRequest{Tasks: []*dbpb.ImportTask{
+					{ExternalId: "todoist-7", Task: &dbpb.CreateTaskRequest{
+						Title:    "File taxes",
+						Priority: "high",
+						DueAt:    timestamppb.New(time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)),
+					}},
+					{ExternalId: "todoist-8", IsDone: true, Task: &dbpb.CreateTaskRequest{Title: "Call Ann"}},
+					{ExternalId: "todoist-9", Task: &dbpb.CreateTaskRequest{Title: "Pay rent", ProjectId: 40}},
+				}}).Return(&dbpb.ImportTasksResponse{Results: []*dbpb.ImportResult{
+					{Task: &dbpb.Task{Id: 11}},
+					{Task: &dbpb.Task{Id: 4}, Skipped: true},
+					{Code: int32(codes.NotFound), Error: "project not found"},
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dry_run":false,"created":1,"skipped":1,"failed":3,"rows":[` +
+				`{"row":1,"status":"created","external_id":"todoist-7","task_id":11},` +
+				`{"row":2,"status":"skipped","external_id":"todoist-8","task_id":4},` +
+				`{"row":3,"status":"failed","error":"project not found","external_id":"todoist-9"},` +
+				`{"row":4,"status":"failed","error":"title is required"},` +
+				`{"row":5,"status":"failed","error":"invalid task: json: cannot unmarshal number into Go struct field Record.title of type string"}]}`,
+		},
+		{
+			name:        "CSV dry run",
+			url:         "/tasks/import?dry_run=true",
+			contentType: "text/csv",
+			body: "\ufeffTitle,External_ID,Tags,Due_At\n" +
+				"Buy milk,a1,shop; food,2025-03-01\n" +
+				"Water plants,a2,,next week\n",
+			mockSetup: func(importer *mocks.Importer) {
+				importer.On("ImportTasks", mock.Anything, &dbpb.ImportTasksRequest{DryRun: true, Tasks: []*dbpb.ImportTask{
+					{ExternalId: "a1", Task: &dbpb.CreateTaskRequest{
+						Title: "Buy milk",
+						Tags:  []string{"shop", "food"},
+						DueAt: timestamppb.New(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)),
+					}},
+				}}).Return(&dbpb.ImportTasksResponse{Results: []*dbpb.ImportResult{{}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dry_run":true,"created":1,"skipped":0,"failed":1,"rows":[` +
+				`{"row":2,"status":"created","external_id":"a1"},` +
+				`{"row":3,"status":"failed","error":"due_at must be an RFC 3339 time or a YYYY-MM-DD date"}]}`,
+		},
+		{
+			name:        "Todo.txt",
+			url:         "/tasks/import",
+			contentType: "text/plain; charset=utf-8",
+			body:        "(A) Call Mom +family @phone due:2025-03-01 ext:t1\n\nx 2025-03-02 2025-02-20 Pay rent ext:t2\n",
+			mockSetup: func(importer *mocks.Importer) {
+				importer.On("ImportTasks", mock.Anything, &dbpb.ImportTasksRequest{Tasks: []*dbpb.ImportTask{
+					{ExternalId: "t1", Task: &dbpb.CreateTaskRequest{
+						Title:    "Call Mom",
+						Priority: "urgent",
+						Tags:     []string{"family", "phone"},
+						DueAt:    timestamppb.New(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)),
+					}},
+					{ExternalId: "t2", IsDone: true, Task: &dbpb.CreateTaskRequest{Title: "Pay rent"}},
+				}}).Return(&dbpb.ImportTasksResponse{Results: []*dbpb.ImportResult{
+					{Task: &dbpb.Task{Id: 1}},
+					{Task: &dbpb.Task{Id: 2}},
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dry_run":false,"created":2,"skipped":0,"failed":0,"rows":[` +
+				`{"row":1,"status":"created","external_id":"t1","task_id":1},` +
+				`{"row":3,"status":"created","external_id":"t2","task_id":2}]}`,
+		},
+		{
+			name:           "Unknown format",
+			url:            "/tasks/import",
+			contentType:    "application/xml",
+			body:           "<tasks/>",
+			mockSetup:      func(importer *mocks.Importer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"ERROR","error":"format must be json, csv or todotxt"}`,
+		},
+		{
+			name:           "Not a JSON array",
+			url:            "/tasks/import?format=json",
+			body:           `{"title":"a"}`,
+			mockSetup:      func(importer *mocks.Importer) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CSV without title",
+			url:            "/tasks/import?format=csv",
+			body:           "name\nBuy milk\n",
+			mockSetup:      func(importer *mocks.Importer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"ERROR","error":"CSV header has no title column"}`,
+		},
+		{
+			name:           "Empty file",
+			url:            "/tasks/import?format=todotxt",
+			body:           "\n\n",
+			mockSetup:      func(importer *mocks.Importer) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Storage unavailable",
+			url:  "/tasks/import?format=todotxt",
+			body: "Call Mom\n",
+			mockSetup: func(importer *mocks.Importer) {
+				importer.On("ImportTasks", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unavailable, "db down"))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer := mocks.NewImporter(t)
+			tt.mockSetup(importer)
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			Import(log, importer).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportChunks(t *testing.T) {
+	var body strings.Builder
+	for i := range importChunk + 1 {
+		fmt.Fprintf(&body, "Task %d ext:t%d\n", i+1, i+1)
+	}
+	importer := mocks.NewImporter(t)
+	importer.On("ImportTasks", mock.Anything, mock.MatchedBy(func(req *dbpb.ImportTasksRequest) bool {
+		return len(req.GetTasks()) == importChunk
+	})).Return(&dbpb.ImportTasksResponse{Results: make([]*dbpb.ImportResult, importChunk)}, nil)
+	importer.On("ImportTasks", mock.Anything, mock.MatchedBy(func(req *dbpb.ImportTasksRequest) bool {
+		return len(req.GetTasks()) == 1
+	})).Return(nil, status.Error(codes.Unavailable, "db down"))
+
+	req := httptest.NewRequest(http.MethodPost, "/tasks/import?format=todotxt", strings.NewReader(body.String()))
+	rr := httptest.NewRecorder()
+	Import(slogdiscard.NewDiscardLogger(), importer).ServeHTTP(rr, req)
+
+	// The first chunk is committed, so the response reports it.
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"created":500,"skipped":0,"failed":1`)
+	assert.Contains(t, rr.Body.String(), `{"row":501,"status":"failed","error":"service unavailable","external_id":"t`)
+}
+
+func TestParseTodoTxtLine(t *testing.T) {
+	due := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2025, 3, 1, 17, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		line string
+		want Record
+	}{
+		{
+			line: "(A) 2025-02-20 Call Mom +family @phone due:2025-03-01",
+			want: Record{Title: "Call Mom", Priority: "urgent", Tags: []string{"family", "phone"}, DueAt: &due},
+		},
+		{
+			line: "x 2025-03-02 2025-02-20 Pay rent pri:B",
+			want: Record{Title: "Pay rent", IsDone: true, Priority: "high"},
+		},
+		{
+			line: "(F) Read https://example.com/a:b note:later +read +read",
+			want: Record{Title: "Read https://example.com/a:b note:later", Priority: "low", Tags: []string{"read"}},
+		},
+		{
+			line: "Water plants due:2025-03-01T17:30:00Z rrule:FREQ=WEEKLY ext:abc",
+			want: Record{Title: "Water plants", DueAt: &dueAt, RRule: "FREQ=WEEKLY", ExternalID: "abc"},
+		},
+		{
+			line: "xylophone lesson",
+			want: Record{Title: "xylophone lesson"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			rec, err := parseTodoTxtLine(tt.line)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, rec)
+		})
+	}
+
+	_, err := parseTodoTxtLine("Call Mom due:tomorrow")
+	assert.Error(t, err)
+}
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	dueAt := time.Date(2025, 3, 1, 17, 30, 0, 0, time.UTC)
+	rec := Record{Title: "Water plants", Priority: "high", Tags: []string{"home"}, DueAt: &dueAt, RRule: "FREQ=WEEKLY", ExternalID: "abc"}
+
+	got, err := parseTodoTxtLine(formatTodoTxt(rec))
+
+	assert.NoError(t, err)
+	assert.Equal(t, rec, got)
+}
+
+func TestContentID(t *testing.T) {
+	created := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	rec := Record{Title: "Call Mom", Tags: []string{"family"}}
+	id := contentID(rec)
+
+	assert.Equal(t, id, contentID(Record{ID: 9, Title: "Call Mom", Tags: []string{"family"}, CreatedAt: &created}))
+	assert.NotEqual(t, id, contentID(Record{Title: "Call Dad", Tags: []string{"family"}}))
+	assert.Len(t, id, len("sha256:")+64)
+}
+
+func TestCSVFormulaCells(t *testing.T) {
+	rec := Record{
+		Title:       `=HYPERLINK("http://evil.example","Click")`,
+		Content:     "+1",
+		Description: "-2",
+		Tags:        []string{"@home"},
+		ExternalID:  "'quoted",
+	}
+	var buf strings.Builder
+	e := newCSVEncoder(&buf)
+	assert.NoError(t, e.write(rec))
+	assert.NoError(t, e.close())
+
+	out := buf.String()
+	assert.Contains(t, out, `"'=HYPERLINK(""http://evil.example"",""Click"")"`)
+	assert.Contains(t, out, ",'+1,'-2,")
+	assert.Contains(t, out, ",'@home,")
+	assert.Contains(t, out, ",''quoted,")
+
+	rows, err := parseCSV(strings.NewReader(out))
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 1) {
+		assert.NoError(t, rows[0].err)
+		assert.Equal(t, rec, rows[0].rec)
+	}
+}
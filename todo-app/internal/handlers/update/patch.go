@@ -0,0 +1,175 @@
+package update
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/etag"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+const maxPatchBytes = 1 << 20
+
+// PatchToUpdate validates a merge patch and converts it into an UpdateTask
+// request that only carries the fields present in the patch.
+func PatchToUpdate(id int64, patch requests.PatchTaskRequest) (*dbpb.UpdateTaskRequest, error) {
+	req := &dbpb.UpdateTaskRequest{Id: id}
+	if patch.Title.Set {
+		if patch.Title.Null || patch.Title.Value == "" {
+			return nil, errors.New("title cannot be removed")
+		}
+		if utf8.RuneCountInString(patch.Title.Value) > 255 {
+			return nil, errors.New("title must be up to 255 characters")
+		}
+		req.Title = &patch.Title.Value
+	}
+	if patch.Content.Set {
+		// content is NOT NULL in the db, so removing it leaves an empty string.
+		if utf8.RuneCountInString(patch.Content.Value) > 255 {
+			return nil, errors.New("content must be up to 255 characters")
+		}
+		req.Content = &patch.Content.Value
+	}
+	if patch.IsDone.Set {
+		if patch.IsDone.Null {
+			return nil, errors.New("is_done cannot be removed")
+		}
+		req.IsDone = &patch.IsDone.Value
+	}
+	if patch.DueAt.Set {
+		if patch.DueAt.Null {
+			req.ClearDueAt = true
+		} else {
+			req.DueAt = timestamppb.New(patch.DueAt.Value)
+		}
+	}
+	if patch.Priority.Set {
+		// Removing the priority puts the task back to the default one.
+		priority := "normal"
+		if !patch.Priority.Null {
+			priority = patch.Priority.Value
+		}
+		if validate.Var(priority, requests.PriorityTag) != nil {
+			return nil, errors.New("priority must be low, normal, high or urgent")
+		}
+		req.Priority = &priority
+	}
+	if patch.Tags.Set {
+		if validate.Var(patch.Tags.Value, requests.TagsTag) != nil {
+			return nil, errors.New("up to 20 tags of up to 50 characters are allowed")
+		}
+		req.Tags = &dbpb.TagList{Values: patch.Tags.Value}
+	}
+	if patch.Description.Set {
+		if validate.Var(patch.Description.Value, requests.DescriptionTag) != nil {
+			return nil, errors.New("description must be up to 10000 characters")
+		}
+		req.Description = &patch.Description.Value
+	}
+	if patch.ProjectID.Set {
+		// Removing the project (null or 0) moves the task out of it.
+		if patch.ProjectID.Value < 0 {
+			return nil, errors.New("project_id must be positive")
+		}
+		req.ProjectId = &patch.ProjectID.Value
+	}
+	if patch.RRule.Set {
+		// Removing the rule (null or "") makes the task a one-off again.
+		if utf8.RuneCountInString(patch.RRule.Value) > 255 {
+			return nil, errors.New("rrule must be up to 255 characters")
+		}
+		req.Rrule = &patch.RRule.Value
+	}
+	return req, nil
+}
+
+// PatchTask applies an RFC 7396 merge patch to a task. A request without a
+// body keeps the old behaviour of PATCH /tasks/{id} and marks the task done.
+func PatchTask(log *slog.Logger, storage dbpb.PostgresClient) http.HandlerFunc {
+	markAsDone := MarkAsDone(log, storage)
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/handlers.go|PatchTask()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchBytes))
+		if err != nil {
+			Err := "invalid request body"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if len(bytes.TrimSpace(body)) == 0 {
+			markAsDone(w, r)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			Err := "Content-Type must be " + mergePatchContentType
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusUnsupportedMediaType, Err)
+			return
+		}
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			Err := "Invalid task ID"
+			log.Info(Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		var patch requests.PatchTaskRequest
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&patch); err != nil {
+			Err := "invalid merge patch"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+
+		updateReq, err := PatchToUpdate(int64(id), patch)
+		if err != nil {
+			log.Info("invalid merge patch", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		updateReq.ExpectedVersion, err = etag.ExpectedVersion(r)
+		if err != nil {
+			log.Info(err.Error())
+			httperr.Render(w, r, http.StatusPreconditionFailed, err.Error())
+			return
+		}
+
+		task, err := storage.UpdateTask(r.Context(), updateReq)
+		if err != nil {
+			Err := "Failed to patch task with ID: " + idStr
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		etag.Set(w, task.GetVersion())
+		render.JSON(w, r, &task)
+	}
+}
@@ -0,0 +1,147 @@
+package update
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/domain/requests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchToUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantErr     string
+		wantTitle   *string
+		wantContent *string
+		wantIsDone  *bool
+	}{
+		{
+			name: "Empty patch changes nothing",
+			body: `{}`,
+		},
+		{
+			name:      "Only title",
+			body:      `{"title": "new"}`,
+			wantTitle: ptr("new"),
+		},
+		{
+			name:       "is_done false is kept",
+			body:       `{"is_done": false}`,
+			wantIsDone: ptr(false),
+		},
+		{
+			name:        "Null content clears it",
+			body:        `{"content": null}`,
+			wantContent: ptr(""),
+		},
+		{
+			name:    "Null title is rejected",
+			body:    `{"title": null}`,
+			wantErr: "title cannot be removed",
+		},
+		{
+			name:    "Null is_done is rejected",
+			body:    `{"is_done": null}`,
+			wantErr: "is_done cannot be removed",
+		},
+		{
+			name:    "Too long title",
+			body:    `{"title": "` + strings.Repeat("a", 256) + `"}`,
+			wantErr: "title must be up to 255 characters",
+		},
+		{
+			name:      "Multibyte title",
+			body:      `{"title": "` + strings.Repeat("й", 255) + `"}`,
+			wantTitle: ptr(strings.Repeat("й", 255)),
+		},
+		{
+			name:    "Too long multibyte title",
+			body:    `{"title": "` + strings.Repeat("й", 256) + `"}`,
+			wantErr: "title must be up to 255 characters",
+		},
+		{
+			name:        "Multibyte content",
+			body:        `{"content": "` + strings.Repeat("й", 255) + `"}`,
+			wantContent: ptr(strings.Repeat("й", 255)),
+		},
+		{
+			name:    "Too long description",
+			body:    `{"description": "` + strings.Repeat("й", 10001) + `"}`,
+			wantErr: "description must be up to 10000 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch requests.PatchTaskRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &patch))
+
+			req, err := PatchToUpdate(7, patch)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), req.GetId())
+			assert.Equal(t, tt.wantTitle, req.Title)
+			assert.Equal(t, tt.wantContent, req.Content)
+			assert.Equal(t, tt.wantIsDone, req.IsDone)
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestPatchToUpdateDetails(t *testing.T) {
+	decode := func(t *testing.T, body string) requests.PatchTaskRequest {
+		var patch requests.PatchTaskRequest
+		require.NoError(t, json.Unmarshal([]byte(body), &patch))
+		return patch
+	}
+
+	t.Run("Null due_at clears it", func(t *testing.T) {
+		req, err := PatchToUpdate(1, decode(t, `{"due_at": null}`))
+		require.NoError(t, err)
+		assert.True(t, req.GetClearDueAt())
+		assert.Nil(t, req.GetDueAt())
+	})
+
+	t.Run("due_at is set", func(t *testing.T) {
+		req, err := PatchToUpdate(1, decode(t, `{"due_at": "2025-05-01T10:00:00Z"}`))
+		require.NoError(t, err)
+		assert.False(t, req.GetClearDueAt())
+		assert.Equal(t, "2025-05-01T10:00:00Z", req.GetDueAt().AsTime().Format(time.RFC3339))
+	})
+
+	t.Run("Null priority resets to normal", func(t *testing.T) {
+		req, err := PatchToUpdate(1, decode(t, `{"priority": null}`))
+		require.NoError(t, err)
+		assert.Equal(t, ptr("normal"), req.Priority)
+	})
+
+	t.Run("Unknown priority", func(t *testing.T) {
+		_, err := PatchToUpdate(1, decode(t, `{"priority": "asap"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Null tags remove all tags", func(t *testing.T) {
+		req, err := PatchToUpdate(1, decode(t, `{"tags": null}`))
+		require.NoError(t, err)
+		require.NotNil(t, req.GetTags())
+		assert.Empty(t, req.GetTags().GetValues())
+	})
+
+	t.Run("Missing tags are kept", func(t *testing.T) {
+		req, err := PatchToUpdate(1, decode(t, `{"description": "# notes"}`))
+		require.NoError(t, err)
+		assert.Nil(t, req.GetTags())
+		assert.Equal(t, ptr("# notes"), req.Description)
+	})
+}
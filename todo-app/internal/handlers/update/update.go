@@ -7,18 +7,18 @@ import (
 	"net/http"
 	"strconv"
 	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/etag"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-type KafkaProducer interface {
-	SendApiEvent(apiRequest *requests.ApiRequest) error
-}
-
-func UpdateTask(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaProducer) http.HandlerFunc {
+func UpdateTask(log *slog.Logger, storage dbpb.PostgresClient) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		fn := "internal/http-server/handlers/handlers.go|UpdateTask()"
 		log = log.With(
@@ -31,7 +31,7 @@ func UpdateTask(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer Kaf
 		if err != nil {
 			Err := "Invalid task ID"
 			log.Info(Err)
-			http.Error(w, Err, http.StatusBadRequest)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
 			return
 		}
 		req := requests.UpdateTaskRequest{}
@@ -39,36 +39,63 @@ func UpdateTask(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer Kaf
 		if errors.Is(err, io.EOF) {
 			Err := "request body is empty"
 			log.Info(Err)
-			render.JSON(w, r, Err)
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
+		}
+		if err != nil {
+			Err := "invalid request body"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
 			return
 		}
 
-		task, err := storage.UpdateTask(r.Context(),
-			&dbpb.UpdateTaskRequest{
-				Id:      int64(id),
-				Title:   &req.Title,
-				Content: &req.Content,
-				IsDone:  &req.IsDone,
-			},
-		)
+		expected, err := etag.ExpectedVersion(r)
 		if err != nil {
-			Err := "Failed to fetch task with ID: " + idStr
-			log.Error(Err)
-			http.Error(w, Err, http.StatusBadRequest)
+			log.Info(err.Error())
+			httperr.Render(w, r, http.StatusPreconditionFailed, err.Error())
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		render.JSON(w, r, &task)
-		event := &requests.ApiRequest{
-			Action: "updated",
+		if err := validate.IsValid(req); err != nil {
+			Err := "title, content and is_done are required, title and content up to 255 characters, priority must be low, normal, high or urgent, up to 20 tags of up to 50 characters, description up to 10000 characters"
+			log.Info(Err, slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, Err)
+			return
 		}
 
-		if err := kafkaProducer.SendApiEvent(event); err != nil {
-			log.Error("failed to send kafka even", (slog.String("error", err.Error())))
+		// PUT replaces the task, so optional fields left out go back to defaults.
+		priority := req.Priority
+		if priority == "" {
+			priority = "normal"
 		}
+		updateReq := &dbpb.UpdateTaskRequest{
+			Id:              int64(id),
+			Title:           req.Title,
+			Content:         req.Content,
+			IsDone:          req.IsDone,
+			ExpectedVersion: expected,
+			ClearDueAt:      req.DueAt == nil,
+			Priority:        &priority,
+			Tags:            &dbpb.TagList{Values: req.Tags},
+			Description:     &req.Description,
+			ProjectId:       &req.ProjectID,
+			Rrule:           &req.RRule,
+		}
+		if req.DueAt != nil {
+			updateReq.DueAt = timestamppb.New(*req.DueAt)
+		}
+
+		task, err := storage.UpdateTask(r.Context(), updateReq)
+		if err != nil {
+			Err := "Failed to update task with ID: " + idStr
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		etag.Set(w, task.GetVersion())
+		render.JSON(w, r, &task)
 	}
 }
-func MarkAsDone(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer KafkaProducer) http.HandlerFunc {
+func MarkAsDone(log *slog.Logger, storage dbpb.PostgresClient) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		fn := "internal/http-server/handlers/handlers.go|MarkAsDone()"
 		log = log.With(
@@ -76,27 +103,40 @@ func MarkAsDone(log *slog.Logger, storage dbpb.PostgresClient, kafkaProducer Kaf
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 		idStr := chi.URLParam(r, "id")
+		expected, err := etag.ExpectedVersion(r)
+		if err != nil {
+			log.Info(err.Error())
+			httperr.Render(w, r, http.StatusPreconditionFailed, err.Error())
+			return
+		}
+		// ?cascade=true completes open subtasks too; without it they block the
+		// parent with 409 Conflict.
+		var cascade bool
+		if v := r.URL.Query().Get("cascade"); v != "" {
+			cascade, err = strconv.ParseBool(v)
+			if err != nil {
+				Err := "cascade must be true or false"
+				log.Info(Err)
+				httperr.Render(w, r, http.StatusBadRequest, Err)
+				return
+			}
+		}
 
 		task, err := storage.MarkAsDone(r.Context(),
 			&dbpb.MarkAsDoneRequest{
-				Id: idStr,
+				Id:              idStr,
+				ExpectedVersion: expected,
+				Cascade:         cascade,
 			},
 		)
 
 		if err != nil {
 			Err := "Failed to MarkAsDone task with ID: " + idStr
-			log.Error(Err)
-			http.Error(w, Err, http.StatusBadRequest)
+			log.Error(Err, slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
+		etag.Set(w, task.GetVersion())
 		render.JSON(w, r, &task)
-		event := &requests.ApiRequest{
-			Action: "marked",
-		}
-
-		if err := kafkaProducer.SendApiEvent(event); err != nil {
-			log.Error("failed to send kafka even", (slog.String("error", err.Error())))
-		}
 	}
 }
@@ -0,0 +1,293 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"todo-app/internal/domain/requests"
+	"todo-app/internal/lib/httperr"
+	"todo-app/internal/lib/validate"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=WebhookStore
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, in *dbpb.CreateWebhookRequest, opts ...grpc.CallOption) (*dbpb.Webhook, error)
+	GetWebhook(ctx context.Context, in *dbpb.GetWebhookRequest, opts ...grpc.CallOption) (*dbpb.Webhook, error)
+	ListWebhooks(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*dbpb.ListWebhooksResponse, error)
+	UpdateWebhook(ctx context.Context, in *dbpb.UpdateWebhookRequest, opts ...grpc.CallOption) (*dbpb.Webhook, error)
+	DeleteWebhook(ctx context.Context, in *dbpb.DeleteWebhookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListWebhookDeliveries(ctx context.Context, in *dbpb.ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*dbpb.ListWebhookDeliveriesResponse, error)
+	RedeliverWebhook(ctx context.Context, in *dbpb.RedeliverWebhookRequest, opts ...grpc.CallOption) (*dbpb.WebhookDelivery, error)
+}
+
+type DeliveriesPage struct {
+	Items      []*dbpb.WebhookDelivery `json:"items"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+func urlID(w http.ResponseWriter, r *http.Request, log *slog.Logger, param, Err string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id < 1 {
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return 0, false
+	}
+	return id, true
+}
+
+func webhookID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return urlID(w, r, log, "id", "Invalid webhook ID")
+}
+
+func deliveryID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return urlID(w, r, log, "did", "Invalid delivery ID")
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, log *slog.Logger, v any) bool {
+	err := render.DecodeJSON(r.Body, v)
+	if errors.Is(err, io.EOF) {
+		Err := "request body is empty"
+		log.Info(Err)
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	if err != nil {
+		Err := "invalid request body"
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	if err := validate.IsValid(v); err != nil {
+		Err := "url must be an absolute URL of up to 2048 characters, event_types up to 20 names, description up to 255 characters"
+		log.Info(Err, slog.String("err", err.Error()))
+		httperr.Render(w, r, http.StatusBadRequest, Err)
+		return false
+	}
+	return true
+}
+
+// parseListQuery reads limit and after of GET /webhooks/{id}/deliveries.
+func parseListQuery(q url.Values) (*dbpb.ListWebhookDeliveriesRequest, error) {
+	req := &dbpb.ListWebhookDeliveriesRequest{After: q.Get("after")}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		req.Limit = int32(limit)
+	}
+	return req, nil
+}
+
+// CreateWebhook registers a webhook. The response carries its signing secret,
+// which is not shown again.
+func CreateWebhook(log *slog.Logger, storage WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/webhooks.go|CreateWebhook()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.CreateWebhookRequest
+		if !decodeBody(w, r, log, &req) {
+			return
+		}
+
+		webhook, err := storage.CreateWebhook(r.Context(), &dbpb.CreateWebhookRequest{
+			Url:         req.URL,
+			EventTypes:  req.EventTypes,
+			Description: req.Description,
+		})
+		if err != nil {
+			log.Error("Failed to create webhook", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, webhook)
+	}
+}
+
+func ListWebhooks(log *slog.Logger, storage WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/webhooks.go|ListWebhooks()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		resp, err := storage.ListWebhooks(r.Context(), &emptypb.Empty{})
+		if err != nil {
+			log.Error("Failed to list webhooks", slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		webhooks := resp.GetWebhooks()
+		if webhooks == nil {
+			webhooks = []*dbpb.Webhook{}
+		}
+		render.JSON(w, r, webhooks)
+	}
+}
+
+func GetWebhook(log *slog.Logger, storage WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/webhooks.go|GetWebhook()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := webhookID(w, r, log)
+		if !ok {
+			return
+		}
+
+		webhook, err := storage.GetWebhook(r.Context(), &dbpb.GetWebhookRequest{Id: id})
+		if err != nil {
+			log.Error("Failed to fetch webhook", slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, webhook)
+	}
+}
+
+// UpdateWebhook changes the fields present in the body. "active": false pauses
+// deliveries without losing them; "rotate_secret": true returns a new secret.
+func UpdateWebhook(log *slog.Logger, storage WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/webhooks.go|UpdateWebhook()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := webhookID(w, r, log)
+		if !ok {
+			return
+		}
+		var req requests.UpdateWebhookRequest
+		if !decodeBody(w, r, log, &req) {
+			return
+		}
+
+		update := &dbpb.UpdateWebhookRequest{
+			Id:           id,
+			Url:          req.URL,
+			Description:  req.Description,
+			Active:       req.Active,
+			RotateSecret: req.RotateSecret,
+		}
+		if req.EventTypes != nil {
+			update.EventTypes = &dbpb.TagList{Values: *req.EventTypes}
+		}
+		webhook, err := storage.UpdateWebhook(r.Context(), update)
+		if err != nil {
+			log.Error("Failed to update webhook", slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, webhook)
+	}
+}
+
+// DeleteWebhook removes the webhook and its delivery log.
+func DeleteWebhook(log *slog.Logger, storage WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/webhooks.go|DeleteWebhook()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := webhookID(w, r, log)
+		if !ok {
+			return
+		}
+
+		_, err := storage.DeleteWebhook(r.Context(), &dbpb.DeleteWebhookRequest{Id: id})
+		if err != nil {
+			log.Error("Failed to delete webhook", slog.Int64("id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.JSON(w, r, "webhook deleted")
+	}
+}
+
+// ListDeliveries returns a page of the webhook's deliveries, newest first,
+// each with the log of its attempts and their response codes.
+func ListDeliveries(log *slog.Logger, storage WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/webhooks.go|ListDeliveries()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := webhookID(w, r, log)
+		if !ok {
+			return
+		}
+		listReq, err := parseListQuery(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query", slog.String("err", err.Error()))
+			httperr.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		listReq.WebhookId = id
+
+		resp, err := storage.ListWebhookDeliveries(r.Context(), listReq)
+		if err != nil {
+			log.Error("Failed to list deliveries", slog.Int64("webhook_id", id), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		page := DeliveriesPage{
+			Items:      resp.GetDeliveries(),
+			NextCursor: resp.GetNextCursor(),
+		}
+		if page.Items == nil {
+			page.Items = []*dbpb.WebhookDelivery{}
+		}
+		render.JSON(w, r, page)
+	}
+}
+
+// Redeliver queues a delivery to be sent again, whatever became of it. It is
+// sent within the dispatcher's poll interval, so the response is 202.
+func Redeliver(log *slog.Logger, storage WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fn := "internal/http-server/handlers/webhooks.go|Redeliver()"
+		log := log.With(
+			slog.String("fn", fn),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+		id, ok := webhookID(w, r, log)
+		if !ok {
+			return
+		}
+		did, ok := deliveryID(w, r, log)
+		if !ok {
+			return
+		}
+
+		delivery, err := storage.RedeliverWebhook(r.Context(), &dbpb.RedeliverWebhookRequest{WebhookId: id, DeliveryId: did})
+		if err != nil {
+			log.Error("Failed to redeliver", slog.Int64("delivery_id", did), slog.String("err", err.Error()))
+			httperr.RenderGRPC(w, r, err)
+			return
+		}
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, delivery)
+	}
+}
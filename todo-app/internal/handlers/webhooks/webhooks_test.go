@@ -0,0 +1,227 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/internal/handlers/webhooks/mocks"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func withParams(req *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestCreateWebhook(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(store *mocks.WebhookStore)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			body: `{"url":"https://ci.example.com/hook","event_types":["task.created","task.done"]}`,
+			mockSetup: func(store *mocks.WebhookStore) {
+				store.On("CreateWebhook", mock.Anything, &dbpb.CreateWebhookRequest{
+					Url:        "https://ci.example.com/hook",
+					EventTypes: []string{"task.created", "task.done"},
+				}).Return(&dbpb.Webhook{Id: 1, Url: "https://ci.example.com/hook", Active: true, Secret: "whsec_1"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1,"url":"https://ci.example.com/hook","active":true,"secret":"whsec_1"}`,
+		},
+		{
+			name:           "Invalid URL",
+			body:           `{"url":"not a url"}`,
+			mockSetup:      func(store *mocks.WebhookStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Empty event type",
+			body:           `{"url":"https://ci.example.com/hook","event_types":[""]}`,
+			mockSetup:      func(store *mocks.WebhookStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown event type",
+			body: `{"url":"https://ci.example.com/hook","event_types":["task.exploded"]}`,
+			mockSetup: func(store *mocks.WebhookStore) {
+				store.On("CreateWebhook", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.InvalidArgument, `unknown event type "task.exploded"`))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"ERROR","error":"unknown event type \"task.exploded\""}`,
+		},
+		{
+			name:           "Empty body",
+			body:           "",
+			mockSetup:      func(store *mocks.WebhookStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewWebhookStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			CreateWebhook(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, strings.TrimSuffix(rr.Body.String(), "\n"))
+			}
+		})
+	}
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+	active := false
+
+	tests := []struct {
+		name     string
+		body     string
+		expected *dbpb.UpdateWebhookRequest
+	}{
+		{
+			name:     "Pause",
+			body:     `{"active":false}`,
+			expected: &dbpb.UpdateWebhookRequest{Id: 3, Active: &active},
+		},
+		{
+			name:     "Subscribe to everything",
+			body:     `{"event_types":[]}`,
+			expected: &dbpb.UpdateWebhookRequest{Id: 3, EventTypes: &dbpb.TagList{Values: []string{}}},
+		},
+		{
+			name:     "Rotate secret",
+			body:     `{"rotate_secret":true}`,
+			expected: &dbpb.UpdateWebhookRequest{Id: 3, RotateSecret: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewWebhookStore(t)
+			store.On("UpdateWebhook", mock.Anything, tt.expected).Return(&dbpb.Webhook{Id: 3}, nil)
+
+			req, err := http.NewRequest(http.MethodPatch, "/webhooks/3", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req = withParams(req, map[string]string{"id": "3"})
+			rr := httptest.NewRecorder()
+
+			UpdateWebhook(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+	}
+}
+
+func TestListDeliveries(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	t.Run("Page", func(t *testing.T) {
+		store := mocks.NewWebhookStore(t)
+		store.On("ListWebhookDeliveries", mock.Anything, &dbpb.ListWebhookDeliveriesRequest{WebhookId: 3, Limit: 10, After: "abc"}).
+			Return(&dbpb.ListWebhookDeliveriesResponse{
+				Deliveries: []*dbpb.WebhookDelivery{{Id: 9, Status: "failed", ResponseCode: 500}},
+				NextCursor: "def",
+			}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, "/webhooks/3/deliveries?limit=10&after=abc", nil)
+		require.NoError(t, err)
+		req = withParams(req, map[string]string{"id": "3"})
+		rr := httptest.NewRecorder()
+
+		ListDeliveries(log, store).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"items":[{"id":9,"status":"failed","response_code":500}],"next_cursor":"def"}`, rr.Body.String())
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/webhooks/3/deliveries?limit=500", nil)
+		require.NoError(t, err)
+		req = withParams(req, map[string]string{"id": "3"})
+		rr := httptest.NewRecorder()
+
+		ListDeliveries(log, mocks.NewWebhookStore(t)).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRedeliver(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+
+	tests := []struct {
+		name           string
+		did            string
+		mockSetup      func(store *mocks.WebhookStore)
+		expectedStatus int
+	}{
+		{
+			name: "Queued",
+			did:  "9",
+			mockSetup: func(store *mocks.WebhookStore) {
+				store.On("RedeliverWebhook", mock.Anything, &dbpb.RedeliverWebhookRequest{WebhookId: 3, DeliveryId: 9}).
+					Return(&dbpb.WebhookDelivery{Id: 9, Status: "pending"}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "Not found",
+			did:  "10",
+			mockSetup: func(store *mocks.WebhookStore) {
+				store.On("RedeliverWebhook", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.NotFound, "delivery not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			did:            "x",
+			mockSetup:      func(store *mocks.WebhookStore) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewWebhookStore(t)
+			tt.mockSetup(store)
+
+			req, err := http.NewRequest(http.MethodPost, "/webhooks/3/deliveries/"+tt.did+"/redeliver", nil)
+			require.NoError(t, err)
+			req = withParams(req, map[string]string{"id": "3", "did": tt.did})
+			rr := httptest.NewRecorder()
+
+			Redeliver(log, store).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
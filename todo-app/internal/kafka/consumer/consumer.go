@@ -0,0 +1,456 @@
+// Package consumer reads the events topic in a consumer group and hands every
+// event to a set of sinks.
+//
+// A sink that fails gets the event again from the retry topics
+// (<topic>.retry.1, .2, ...), each of which holds it back a little longer than
+// the one before. Only the sinks that failed see the retry. Once the retry
+// topics are exhausted, or when a sink reports a permanent error or the message
+// cannot be decoded at all, the message goes to the dead-letter topic
+// (<topic>.dlq) with the reason in its headers.
+//
+// Offsets are marked once a message is handled, including when it was handed
+// on to a retry or dead-letter topic, and committed by the group in the
+// background. Delivery is at least once: sinks must tolerate seeing an event
+// twice, the recent event ids are only remembered to skip the common case.
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/lib/backoff"
+	"todo-app/internal/lib/logger/sl"
+
+	"github.com/IBM/sarama"
+)
+
+// Sink processes events. Handle is called concurrently for events of
+// different partitions.
+type Sink interface {
+	// Name identifies the sink in retry headers and logs; keep it stable.
+	Name() string
+	Handle(ctx context.Context, event *events.Event) error
+}
+
+// Permanent marks err as one that retrying will not fix, so the event goes to
+// the dead-letter topic right away.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// IsPermanent reports whether err or one it wraps was marked by Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+type Config struct {
+	Enabled bool   `yaml:"enabled" env:"CONSUMER_ENABLED"`
+	Group   string `yaml:"group" env:"CONSUMER_GROUP" env-default:"todo-app"`
+	// RetryDelays has one entry per retry topic: an event on
+	// <topic>.retry.<n> is handled no sooner than the n-th delay after the
+	// failure that put it there.
+	RetryDelays []time.Duration `yaml:"retry_delays"`
+	// DedupSize is how many event ids are remembered to skip redeliveries.
+	DedupSize int `yaml:"dedup_size" env:"CONSUMER_DEDUP_SIZE" env-default:"10000"`
+	// HandleTimeout bounds one call of a sink.
+	HandleTimeout time.Duration `yaml:"handle_timeout" env:"CONSUMER_HANDLE_TIMEOUT" env-default:"10s"`
+}
+
+var defaultRetryDelays = []time.Duration{10 * time.Second, time.Minute, 10 * time.Minute}
+
+// Headers added to the messages on the retry and dead-letter topics. The
+// headers of the original message are kept.
+const (
+	headerAttempt   = "retry_attempt"
+	headerRetryAt   = "retry_at"
+	headerSinks     = "retry_sinks"
+	headerError     = "error"
+	headerTopic     = "original_topic"
+	headerPartition = "original_partition"
+	headerOffset    = "original_offset"
+)
+
+const maxForwardBackoff = 30 * time.Second
+
+// NewGroup joins the consumer group of cfg. New groups start at the oldest
+// message, so events published before the first deployment are not lost.
+func NewGroup(brokers []string, cfg Config) (sarama.ConsumerGroup, error) {
+	config := sarama.NewConfig()
+	config.ClientID = "todo-app-consumer"
+	config.Net.DialTimeout = 5 * time.Second
+	config.Consumer.Return.Errors = true
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Consumer.Offsets.AutoCommit.Enable = true
+	config.Consumer.Offsets.AutoCommit.Interval = time.Second
+	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategySticky()}
+
+	group, err := sarama.NewConsumerGroup(brokers, cfg.Group, config)
+	if err != nil {
+		return nil, fmt.Errorf("create consumer group: %w", err)
+	}
+	return group, nil
+}
+
+// NewProducer connects the producer for the retry and dead-letter topics.
+func NewProducer(brokers []string) (sarama.SyncProducer, error) {
+	config := sarama.NewConfig()
+	config.ClientID = "todo-app-consumer"
+	config.Net.DialTimeout = 5 * time.Second
+	config.Net.MaxOpenRequests = 1
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Idempotent = true
+	config.Producer.Retry.Max = 3
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("create producer: %w", err)
+	}
+	return producer, nil
+}
+
+// Consumer is the sarama.ConsumerGroupHandler that runs the sinks.
+type Consumer struct {
+	topic    string
+	delays   []time.Duration
+	timeout  time.Duration
+	sinks    []Sink
+	producer sarama.SyncProducer
+	seen     *dedup
+	log      *slog.Logger
+	now      func() time.Time
+}
+
+// New creates a consumer of topic. producer is used for the retry and
+// dead-letter topics.
+func New(topic string, cfg Config, producer sarama.SyncProducer, log *slog.Logger, sinks ...Sink) *Consumer {
+	delays := cfg.RetryDelays
+	if len(delays) == 0 {
+		delays = defaultRetryDelays
+	}
+	return &Consumer{
+		topic:    topic,
+		delays:   delays,
+		timeout:  cfg.HandleTimeout,
+		sinks:    sinks,
+		producer: producer,
+		seen:     newDedup(cfg.DedupSize),
+		log:      log,
+		now:      time.Now,
+	}
+}
+
+// RetryTopic is the topic of the n-th retry, counting from 1.
+func (c *Consumer) RetryTopic(n int) string {
+	return c.topic + ".retry." + strconv.Itoa(n)
+}
+
+// DeadLetterTopic receives the events that could not be handled.
+func (c *Consumer) DeadLetterTopic() string {
+	return c.topic + ".dlq"
+}
+
+// Topics are the topics the group subscribes to: the events topic and its
+// retry topics.
+func (c *Consumer) Topics() []string {
+	topics := []string{c.topic}
+	for n := range c.delays {
+		topics = append(topics, c.RetryTopic(n+1))
+	}
+	return topics
+}
+
+// Run consumes in group until ctx is done. Consume returns on every
+// rebalance, so it is called in a loop, with a growing pause after errors.
+func (c *Consumer) Run(ctx context.Context, group sarama.ConsumerGroup) {
+	const op = "todo-app/internal/kafka/consumer|Run()"
+	log := c.log.With(slog.String("op", op))
+
+	go func() {
+		for err := range group.Errors() {
+			log.Error("consumer group error", sl.Err(err))
+		}
+	}()
+
+	var failures int
+	for {
+		err := group.Consume(ctx, c.Topics(), c)
+		if ctx.Err() != nil || errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return
+		}
+		var wait time.Duration
+		if err != nil {
+			failures++
+			wait = backoff.Delay(time.Second, maxForwardBackoff, failures)
+			log.Error("failed to consume", sl.Err(err), slog.Duration("retry_in", wait))
+		} else {
+			failures = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim handles the messages of one partition in order. A message that
+// is not marked when the session ends, because it waited for its retry time or
+// could not be forwarded, is delivered again to whoever gets the partition.
+func (c *Consumer) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx := sess.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			// The messages of a retry topic all wait the same delay, so
+			// waiting for the head of a partition never holds up one that is
+			// due sooner.
+			if !c.waitUntil(ctx, retryAt(msg)) {
+				return nil
+			}
+			if err := c.handle(ctx, msg); err != nil {
+				return nil
+			}
+			sess.MarkMessage(msg, "")
+		}
+	}
+}
+
+// handle runs the sinks for msg and forwards it to a retry or dead-letter
+// topic if any of them failed. It only fails when forwarding did not succeed
+// before ctx was done, in which case msg must not be marked.
+func (c *Consumer) handle(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	const op = "todo-app/internal/kafka/consumer|handle()"
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("topic", msg.Topic),
+		slog.Int("partition", int(msg.Partition)),
+		slog.Int64("offset", msg.Offset),
+	)
+
+	var event events.Event
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		log.Error("dropping undecodable message to the dead-letter topic", sl.Err(err))
+		return c.deadLetter(ctx, msg, nil, fmt.Errorf("decode event: %w", err))
+	}
+	if event.ID == "" || event.Type == "" || event.Version > events.Version {
+		err := fmt.Errorf("unsupported event (id %q, type %q, version %d)", event.ID, event.Type, event.Version)
+		log.Error("dropping unsupported message to the dead-letter topic", sl.Err(err))
+		return c.deadLetter(ctx, msg, nil, err)
+	}
+	log = log.With(slog.String("event_id", event.ID), slog.String("event_type", event.Type))
+
+	attempt := retryAttempt(msg)
+	if attempt == 0 && !c.seen.add(event.ID) {
+		log.Debug("skipping redelivered event")
+		return nil
+	}
+
+	var retry, dead []string
+	var retryErr, deadErr error
+	for _, sink := range c.sinksFor(msg) {
+		err := c.call(ctx, sink, &event)
+		if err == nil {
+			continue
+		}
+		log.Warn("sink failed", slog.String("sink", sink.Name()), slog.Int("attempt", attempt), sl.Err(err))
+		err = fmt.Errorf("%s: %w", sink.Name(), err)
+		if IsPermanent(err) || attempt >= len(c.delays) {
+			dead = append(dead, sink.Name())
+			deadErr = errors.Join(deadErr, err)
+		} else {
+			retry = append(retry, sink.Name())
+			retryErr = errors.Join(retryErr, err)
+		}
+	}
+
+	var err error
+	if len(retry) > 0 {
+		err = c.retry(ctx, msg, attempt+1, retry, retryErr)
+	}
+	if err == nil && len(dead) > 0 {
+		log.Error("sending event to the dead-letter topic", slog.Any("sinks", dead), sl.Err(deadErr))
+		err = c.deadLetter(ctx, msg, dead, deadErr)
+	}
+	if err != nil && attempt == 0 {
+		// It comes back; let it in then.
+		c.seen.remove(event.ID)
+	}
+	return err
+}
+
+// sinksFor returns the sinks msg is for: those that failed it before, or all
+// of them for the events topic.
+func (c *Consumer) sinksFor(msg *sarama.ConsumerMessage) []Sink {
+	names := header(msg, headerSinks)
+	if names == "" {
+		return c.sinks
+	}
+	var sinks []Sink
+	for _, name := range strings.Split(names, ",") {
+		for _, sink := range c.sinks {
+			if sink.Name() == name {
+				sinks = append(sinks, sink)
+			}
+		}
+	}
+	return sinks
+}
+
+func (c *Consumer) call(ctx context.Context, sink Sink, event *events.Event) (err error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = Permanent(fmt.Errorf("panic: %v", r))
+		}
+	}()
+	return sink.Handle(ctx, event)
+}
+
+func (c *Consumer) retry(ctx context.Context, msg *sarama.ConsumerMessage, attempt int, sinks []string, cause error) error {
+	retryAt := c.now().Add(c.delays[attempt-1])
+	return c.forward(ctx, msg, c.RetryTopic(attempt),
+		sarama.RecordHeader{Key: []byte(headerAttempt), Value: []byte(strconv.Itoa(attempt))},
+		sarama.RecordHeader{Key: []byte(headerRetryAt), Value: []byte(retryAt.UTC().Format(time.RFC3339Nano))},
+		sarama.RecordHeader{Key: []byte(headerSinks), Value: []byte(strings.Join(sinks, ","))},
+		sarama.RecordHeader{Key: []byte(headerError), Value: []byte(cause.Error())},
+	)
+}
+
+// deadLetter forwards msg to the dead-letter topic. sinks are the sinks that
+// gave up on it, nil when the message itself is broken.
+func (c *Consumer) deadLetter(ctx context.Context, msg *sarama.ConsumerMessage, sinks []string, cause error) error {
+	return c.forward(ctx, msg, c.DeadLetterTopic(),
+		sarama.RecordHeader{Key: []byte(headerAttempt), Value: []byte(strconv.Itoa(retryAttempt(msg)))},
+		sarama.RecordHeader{Key: []byte(headerSinks), Value: []byte(strings.Join(sinks, ","))},
+		sarama.RecordHeader{Key: []byte(headerError), Value: []byte(cause.Error())},
+	)
+}
+
+// forward copies msg to topic with the given headers, replacing earlier
+// values of the same keys. It keeps trying until ctx is done: skipping the
+// message would lose it, and the next ones cannot overtake it anyway.
+func (c *Consumer) forward(ctx context.Context, msg *sarama.ConsumerMessage, topic string, headers ...sarama.RecordHeader) error {
+	const op = "todo-app/internal/kafka/consumer|forward()"
+
+	out := &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.ByteEncoder(msg.Key),
+		Value: sarama.ByteEncoder(msg.Value),
+	}
+	replaced := map[string]bool{}
+	for _, h := range headers {
+		replaced[string(h.Key)] = true
+	}
+	origin := origin(msg)
+	for _, h := range origin {
+		replaced[string(h.Key)] = true
+	}
+	for _, h := range msg.Headers {
+		if h != nil && !replaced[string(h.Key)] {
+			out.Headers = append(out.Headers, *h)
+		}
+	}
+	out.Headers = append(out.Headers, headers...)
+	out.Headers = append(out.Headers, origin...)
+
+	var failures int
+	for {
+		_, _, err := c.producer.SendMessage(out)
+		if err == nil {
+			return nil
+		}
+		failures++
+		wait := backoff.Delay(100*time.Millisecond, maxForwardBackoff, failures)
+		c.log.Error("failed to forward event",
+			slog.String("op", op),
+			slog.String("topic", topic),
+			slog.Duration("retry_in", wait),
+			sl.Err(err))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("forward to %s: %w", topic, err)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// origin returns the headers that point to where msg was first published,
+// which a forwarded message inherits.
+func origin(msg *sarama.ConsumerMessage) []sarama.RecordHeader {
+	if header(msg, headerTopic) != "" {
+		var out []sarama.RecordHeader
+		for _, h := range msg.Headers {
+			switch string(h.Key) {
+			case headerTopic, headerPartition, headerOffset:
+				out = append(out, *h)
+			}
+		}
+		return out
+	}
+	return []sarama.RecordHeader{
+		{Key: []byte(headerTopic), Value: []byte(msg.Topic)},
+		{Key: []byte(headerPartition), Value: []byte(strconv.Itoa(int(msg.Partition)))},
+		{Key: []byte(headerOffset), Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	}
+}
+
+// waitUntil sleeps until t and reports whether it got there before ctx was done.
+func (c *Consumer) waitUntil(ctx context.Context, t time.Time) bool {
+	d := t.Sub(c.now())
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func header(msg *sarama.ConsumerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// retryAttempt is the number of earlier failed attempts, 0 on the events topic.
+func retryAttempt(msg *sarama.ConsumerMessage) int {
+	n, _ := strconv.Atoi(header(msg, headerAttempt))
+	return n
+}
+
+func retryAt(msg *sarama.ConsumerMessage) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, header(msg, headerRetryAt))
+	return t
+}
@@ -0,0 +1,248 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSink struct {
+	name string
+	err  error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *fakeSink) Name() string { return s.name }
+
+func (s *fakeSink) Handle(_ context.Context, event *events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, event.ID)
+	return s.err
+}
+
+func newTestConsumer(t *testing.T, producer sarama.SyncProducer, sinks ...Sink) *Consumer {
+	t.Helper()
+	cfg := Config{RetryDelays: []time.Duration{time.Second, time.Minute}, DedupSize: 10}
+	return New("api-events", cfg, producer, slogdiscard.NewDiscardLogger(), sinks...)
+}
+
+func message(t *testing.T, topic string, event *events.Event, headers ...*sarama.RecordHeader) *sarama.ConsumerMessage {
+	t.Helper()
+	value, err := json.Marshal(event)
+	require.NoError(t, err)
+	return &sarama.ConsumerMessage{
+		Topic:     topic,
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte(event.Key()),
+		Value:     value,
+		Headers:   append([]*sarama.RecordHeader{{Key: []byte("event_id"), Value: []byte(event.ID)}}, headers...),
+	}
+}
+
+func testEvent(id string) *events.Event {
+	return &events.Event{ID: id, Version: events.Version, Type: events.TaskUpdated, UserID: 7, TaskID: 3}
+}
+
+// expectForward expects one message on topic and passes its headers to check.
+func expectForward(producer *mocks.SyncProducer, topic string, check func(headers map[string]string) error) {
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Topic != topic {
+			return errors.New("sent to " + msg.Topic + ", want " + topic)
+		}
+		headers := map[string]string{}
+		for _, h := range msg.Headers {
+			headers[string(h.Key)] = string(h.Value)
+		}
+		return check(headers)
+	})
+}
+
+func TestTopics(t *testing.T) {
+	c := newTestConsumer(t, nil)
+	assert.Equal(t, []string{"api-events", "api-events.retry.1", "api-events.retry.2"}, c.Topics())
+	assert.Equal(t, "api-events.dlq", c.DeadLetterTopic())
+}
+
+func TestHandleSkipsRedeliveries(t *testing.T) {
+	sink := &fakeSink{name: "a"}
+	c := newTestConsumer(t, mocks.NewSyncProducer(t, nil), sink)
+
+	msg := message(t, "api-events", testEvent("e1"))
+	require.NoError(t, c.handle(context.Background(), msg))
+	require.NoError(t, c.handle(context.Background(), msg))
+	assert.Equal(t, []string{"e1"}, sink.calls)
+}
+
+func TestHandleRetriesFailedSinksOnly(t *testing.T) {
+	ok := &fakeSink{name: "ok"}
+	failing := &fakeSink{name: "failing", err: errors.New("unavailable")}
+	producer := mocks.NewSyncProducer(t, nil)
+	c := newTestConsumer(t, producer, ok, failing)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	var retried map[string]string
+	expectForward(producer, "api-events.retry.1", func(headers map[string]string) error {
+		retried = headers
+		return nil
+	})
+	require.NoError(t, c.handle(context.Background(), message(t, "api-events", testEvent("e1"))))
+	assert.Equal(t, "1", retried[headerAttempt])
+	assert.Equal(t, "failing", retried[headerSinks])
+	assert.Equal(t, now.Add(time.Second).Format(time.RFC3339Nano), retried[headerRetryAt])
+	assert.Equal(t, "api-events", retried[headerTopic])
+	assert.Equal(t, "42", retried[headerOffset])
+	assert.Equal(t, "e1", retried["event_id"])
+
+	// The retry goes to the failing sink alone, and succeeds.
+	failing.err = nil
+	retry := message(t, "api-events.retry.1", testEvent("e1"),
+		&sarama.RecordHeader{Key: []byte(headerAttempt), Value: []byte("1")},
+		&sarama.RecordHeader{Key: []byte(headerSinks), Value: []byte("failing")},
+	)
+	require.NoError(t, c.handle(context.Background(), retry))
+	assert.Equal(t, []string{"e1"}, ok.calls)
+	assert.Equal(t, []string{"e1", "e1"}, failing.calls)
+}
+
+func TestHandleDeadLetters(t *testing.T) {
+	tests := []struct {
+		name    string
+		sinkErr error
+		msg     func(t *testing.T) *sarama.ConsumerMessage
+		sinks   string
+	}{
+		{
+			name:    "retries exhausted",
+			sinkErr: errors.New("unavailable"),
+			msg: func(t *testing.T) *sarama.ConsumerMessage {
+				return message(t, "api-events.retry.2", testEvent("e1"),
+					&sarama.RecordHeader{Key: []byte(headerAttempt), Value: []byte("2")},
+					&sarama.RecordHeader{Key: []byte(headerSinks), Value: []byte("a")},
+					&sarama.RecordHeader{Key: []byte(headerTopic), Value: []byte("api-events")},
+					&sarama.RecordHeader{Key: []byte(headerOffset), Value: []byte("42")},
+				)
+			},
+			sinks: "a",
+		},
+		{
+			name:    "permanent error",
+			sinkErr: Permanent(errors.New("bad endpoint")),
+			msg: func(t *testing.T) *sarama.ConsumerMessage {
+				return message(t, "api-events", testEvent("e1"))
+			},
+			sinks: "a",
+		},
+		{
+			name: "undecodable",
+			msg: func(t *testing.T) *sarama.ConsumerMessage {
+				return &sarama.ConsumerMessage{Topic: "api-events", Offset: 42, Value: []byte("{")}
+			},
+		},
+		{
+			name: "newer version",
+			msg: func(t *testing.T) *sarama.ConsumerMessage {
+				event := testEvent("e1")
+				event.Version = events.Version + 1
+				return message(t, "api-events", event)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := mocks.NewSyncProducer(t, nil)
+			c := newTestConsumer(t, producer, &fakeSink{name: "a", err: tt.sinkErr})
+			expectForward(producer, "api-events.dlq", func(headers map[string]string) error {
+				if headers[headerSinks] != tt.sinks || headers[headerError] == "" {
+					return errors.New("unexpected dead-letter headers")
+				}
+				if headers[headerTopic] != "api-events" || headers[headerOffset] != "42" {
+					return errors.New("dead letter does not point to the original message")
+				}
+				return nil
+			})
+			require.NoError(t, c.handle(context.Background(), tt.msg(t)))
+		})
+	}
+}
+
+func TestHandleForgetsEventsItCouldNotForward(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	c := newTestConsumer(t, producer, &fakeSink{name: "a", err: errors.New("unavailable")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := c.handle(ctx, message(t, "api-events", testEvent("e1")))
+	require.ErrorIs(t, err, sarama.ErrOutOfBrokers)
+	assert.True(t, c.seen.add("e1"), "the event must be handled again when it comes back")
+}
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func TestConsumeClaim(t *testing.T) {
+	sink := &fakeSink{name: "a"}
+	c := newTestConsumer(t, mocks.NewSyncProducer(t, nil), sink)
+	due := message(t, "api-events.retry.1", testEvent("e2"),
+		&sarama.RecordHeader{Key: []byte(headerAttempt), Value: []byte("1")},
+		&sarama.RecordHeader{Key: []byte(headerRetryAt), Value: []byte(time.Now().Add(-time.Second).Format(time.RFC3339Nano))},
+	)
+	due.Offset = 43
+	notDue := message(t, "api-events.retry.1", testEvent("e3"),
+		&sarama.RecordHeader{Key: []byte(headerAttempt), Value: []byte("1")},
+		&sarama.RecordHeader{Key: []byte(headerRetryAt), Value: []byte(time.Now().Add(time.Hour).Format(time.RFC3339Nano))},
+	)
+	notDue.Offset = 44
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 3)}
+	claim.messages <- message(t, "api-events", testEvent("e1"))
+	claim.messages <- due
+	claim.messages <- notDue
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	sess := &fakeSession{ctx: ctx}
+
+	require.NoError(t, c.ConsumeClaim(sess, claim))
+	assert.Equal(t, []int64{42, 43}, sess.marked, "the message waiting for its retry time must not be marked")
+	assert.Equal(t, []string{"e1", "e2"}, sink.calls)
+}
+
+func TestDedupEvictsOldest(t *testing.T) {
+	d := newDedup(2)
+	assert.True(t, d.add("a"))
+	assert.True(t, d.add("b"))
+	assert.False(t, d.add("a"))
+	assert.True(t, d.add("c"))
+	assert.True(t, d.add("a"), "a should have been evicted")
+}
@@ -0,0 +1,47 @@
+package consumer
+
+import (
+	"container/list"
+	"sync"
+)
+
+// dedup remembers the most recent event ids, evicting the oldest.
+type dedup struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	ids   map[string]*list.Element
+}
+
+func newDedup(size int) *dedup {
+	return &dedup{size: size, order: list.New(), ids: map[string]*list.Element{}}
+}
+
+// add remembers id and reports whether it was new. With a size of 0 every id
+// is new.
+func (d *dedup) add(id string) bool {
+	if d.size <= 0 {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.ids[id]; ok {
+		return false
+	}
+	d.ids[id] = d.order.PushFront(id)
+	if d.order.Len() > d.size {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.ids, oldest.Value.(string))
+	}
+	return true
+}
+
+func (d *dedup) remove(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if e, ok := d.ids[id]; ok {
+		d.order.Remove(e)
+		delete(d.ids, id)
+	}
+}
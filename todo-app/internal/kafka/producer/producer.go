@@ -4,9 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strconv"
 
 	"time"
-	"todo-app/internal/domain/requests"
+	"todo-app/internal/domain/events"
 
 	"github.com/IBM/sarama"
 )
@@ -74,16 +75,25 @@ func validateBrokers(brokers []string, config *sarama.Config, logger *slog.Logge
 	return nil
 }
 
-func (p *Producer) SendApiEvent(apiRequest *requests.ApiRequest) error {
-	jsonData, err := json.Marshal(apiRequest)
+// SendEvent publishes event keyed by its task, so the events of a task keep
+// their order. The id, type and envelope version are also sent as headers, the
+// same as the db service's outbox relay, for consumers that route or
+// deduplicate without decoding the value.
+func (p *Producer) SendEvent(event *events.Event) error {
+	jsonData, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("marshal error: %w", err)
 	}
 
 	msg := &sarama.ProducerMessage{
 		Topic: p.topic,
-		Key:   sarama.StringEncoder(apiRequest.Action),
+		Key:   sarama.StringEncoder(event.Key()),
 		Value: sarama.ByteEncoder(jsonData),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event_id"), Value: []byte(event.ID)},
+			{Key: []byte("event_type"), Value: []byte(event.Type)},
+			{Key: []byte("event_version"), Value: []byte(strconv.Itoa(event.Version))},
+		},
 	}
 
 	partition, offset, err := p.producer.SendMessage(msg)
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/lib/logger/slogdiscard"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendEvent(t *testing.T) {
+	sp := mocks.NewSyncProducer(t, nil)
+	defer sp.Close()
+	sp.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		key, err := msg.Key.Encode()
+		require.NoError(t, err)
+		if string(key) != "5" {
+			return fmt.Errorf("key = %q, want the task id", key)
+		}
+		headers := map[string]string{}
+		for _, h := range msg.Headers {
+			headers[string(h.Key)] = string(h.Value)
+		}
+		assert.Equal(t, "task.done", headers["event_type"])
+		assert.Equal(t, "1", headers["event_version"])
+		assert.NotEmpty(t, headers["event_id"])
+
+		value, err := msg.Value.Encode()
+		require.NoError(t, err)
+		var got events.Event
+		require.NoError(t, json.Unmarshal(value, &got))
+		assert.Equal(t, events.TaskDone, got.Type)
+		assert.True(t, got.After.IsDone)
+		return nil
+	})
+
+	p := &Producer{producer: sp, topic: "tasks", logger: slogdiscard.NewDiscardLogger()}
+	event := events.New(context.Background(), events.TaskDone).WithTask(&dbpb.Task{Id: 5}, &dbpb.Task{Id: 5, IsDone: true})
+
+	require.NoError(t, p.SendEvent(event))
+}
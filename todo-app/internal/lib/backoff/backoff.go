@@ -0,0 +1,17 @@
+// Package backoff computes the delays of exponential retries.
+package backoff
+
+import "time"
+
+// Delay doubles base for every failure after the first, up to max. It is
+// base for the first failure.
+func Delay(base, max time.Duration, failures int) time.Duration {
+	d := base
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
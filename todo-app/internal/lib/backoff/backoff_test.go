@@ -0,0 +1,28 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "No failures", failures: 0, want: time.Second},
+		{name: "First failure", failures: 1, want: time.Second},
+		{name: "Second failure", failures: 2, want: 2 * time.Second},
+		{name: "Fourth failure", failures: 4, want: 8 * time.Second},
+		{name: "Capped", failures: 6, want: 30 * time.Second},
+		{name: "Many failures", failures: 1000, want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Delay(time.Second, 30*time.Second, tt.failures))
+		})
+	}
+}
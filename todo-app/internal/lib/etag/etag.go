@@ -0,0 +1,62 @@
+package etag
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMatch means If-Match names no version of the task, so the request
+// can never succeed and must be answered with 412.
+var ErrNoMatch = errors.New("precondition does not name a task version")
+
+// Format renders a task version as a strong entity tag.
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Set adds the ETag header for the given task version.
+func Set(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", Format(version))
+}
+
+func tags(header string) []string {
+	var out []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// ExpectedVersion reads If-Match and returns the version a write must find.
+// A missing header or "*" returns nil: the write is unconditional. Only a
+// single strong tag can be checked by the db service.
+func ExpectedVersion(r *http.Request) (*int64, error) {
+	list := tags(r.Header.Get("If-Match"))
+	if len(list) == 0 || (len(list) == 1 && list[0] == "*") {
+		return nil, nil
+	}
+	if len(list) > 1 || strings.HasPrefix(list[0], "W/") {
+		return nil, ErrNoMatch
+	}
+	version, err := strconv.ParseInt(strings.Trim(list[0], `"`), 10, 64)
+	if err != nil {
+		return nil, ErrNoMatch
+	}
+	return &version, nil
+}
+
+// NoneMatch reports whether If-None-Match matches the version, in which case
+// a GET is answered with 304. Weak comparison is used as RFC 9110 requires.
+func NoneMatch(r *http.Request, version int64) bool {
+	current := Format(version)
+	for _, tag := range tags(r.Header.Get("If-None-Match")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			return true
+		}
+	}
+	return false
+}
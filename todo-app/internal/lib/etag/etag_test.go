@@ -0,0 +1,69 @@
+package etag
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    *int64
+		wantErr bool
+	}{
+		{name: "No header"},
+		{name: "Any", header: "*"},
+		{name: "Strong tag", header: `"3"`, want: ptr(3)},
+		{name: "Weak tag never matches", header: `W/"3"`, wantErr: true},
+		{name: "Garbage", header: `"abc"`, wantErr: true},
+		{name: "Several tags", header: `"3", "4"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPut, "/", nil)
+			require.NoError(t, err)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			got, err := ExpectedVersion(r)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrNoMatch)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: `"2"`, want: true},
+		{header: `W/"2"`, want: true},
+		{header: `"1", "2"`, want: true},
+		{header: `"1"`, want: false},
+		{header: "*", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			r.Header.Set("If-None-Match", tt.header)
+			assert.Equal(t, tt.want, NoneMatch(r, 2))
+		})
+	}
+}
+
+func ptr(v int64) *int64 {
+	return &v
+}
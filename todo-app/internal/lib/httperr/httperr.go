@@ -0,0 +1,55 @@
+package httperr
+
+import (
+	"net/http"
+	"todo-app/internal/domain/response"
+
+	"github.com/go-chi/render"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FromGRPC maps an error returned by the db service to an HTTP status and a
+// message that is safe to show to the client. Messages of server-side failures
+// are replaced with a generic text.
+func FromGRPC(err error) (int, string) {
+	st, _ := status.FromError(err)
+	switch st.Code() {
+	case codes.OK:
+		return http.StatusOK, ""
+	case codes.NotFound:
+		return http.StatusNotFound, st.Message()
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest, st.Message()
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict, st.Message()
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed, st.Message()
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized, st.Message()
+	case codes.PermissionDenied:
+		return http.StatusForbidden, st.Message()
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests, st.Message()
+	case codes.Unimplemented:
+		return http.StatusNotImplemented, "not implemented"
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable, "service unavailable"
+	case codes.DeadlineExceeded, codes.Canceled:
+		return http.StatusGatewayTimeout, "request timed out"
+	default:
+		return http.StatusInternalServerError, "internal error"
+	}
+}
+
+// Render answers with the JSON error body shared by all todo-app handlers.
+func Render(w http.ResponseWriter, r *http.Request, code int, msg string) {
+	render.Status(r, code)
+	render.JSON(w, r, response.Error(msg))
+}
+
+// RenderGRPC translates a db service error with FromGRPC and renders it.
+func RenderGRPC(w http.ResponseWriter, r *http.Request, err error) {
+	code, msg := FromGRPC(err)
+	Render(w, r, code, msg)
+}
@@ -0,0 +1,35 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromGRPC(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantMsg    string
+	}{
+		{"Not found", status.Error(codes.NotFound, "task not found"), http.StatusNotFound, "task not found"},
+		{"Invalid argument", status.Error(codes.InvalidArgument, "title is required"), http.StatusBadRequest, "title is required"},
+		{"Already exists", status.Error(codes.AlreadyExists, "duplicate"), http.StatusConflict, "duplicate"},
+		{"Unavailable hides details", status.Error(codes.Unavailable, "dial tcp 10.0.0.1:5432"), http.StatusServiceUnavailable, "service unavailable"},
+		{"Internal hides details", status.Error(codes.Internal, "db/internal/handlers|GetTask(): boom"), http.StatusInternalServerError, "internal error"},
+		{"Plain error", errors.New("boom"), http.StatusInternalServerError, "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, msg := FromGRPC(tt.err)
+			assert.Equal(t, tt.wantStatus, code)
+			assert.Equal(t, tt.wantMsg, msg)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package ical writes iCalendar (RFC 5545) data.
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLine is the longest a content line may be, in octets without the CRLF.
+const maxLine = 75
+
+// Writer builds iCalendar content lines: it escapes TEXT values, folds long
+// lines and ends each with CRLF.
+type Writer struct {
+	buf bytes.Buffer
+}
+
+// Begin opens a component such as VCALENDAR or VTODO.
+func (w *Writer) Begin(component string) {
+	w.Raw("BEGIN", component)
+}
+
+func (w *Writer) End(component string) {
+	w.Raw("END", component)
+}
+
+// Text writes a property of type TEXT.
+func (w *Writer) Text(name, value string) {
+	w.Raw(name, escape(value))
+}
+
+// TextList writes a property holding a list of TEXT values, like CATEGORIES.
+func (w *Writer) TextList(name string, values []string) {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = escape(v)
+	}
+	w.Raw(name, strings.Join(escaped, ","))
+}
+
+// Time writes a DATE-TIME property in UTC.
+func (w *Writer) Time(name string, t time.Time) {
+	w.Raw(name, t.UTC().Format("20060102T150405Z"))
+}
+
+// Raw writes a property whose name may carry parameters and whose value is
+// already in iCalendar form.
+func (w *Writer) Raw(name, value string) {
+	line := name + ":" + value
+	// Continuation lines start with a space, which counts.
+	for limit := maxLine; len(line) > limit; limit = maxLine - 1 {
+		n := limit
+		for n > 0 && !utf8.RuneStart(line[n]) {
+			n--
+		}
+		w.buf.WriteString(line[:n])
+		w.buf.WriteString("\r\n ")
+		line = line[n:]
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
+
+// Bytes returns what was written.
+func (w *Writer) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// escape escapes a TEXT value. Control characters other than tab are not
+// allowed and are dropped; line breaks become \n.
+func escape(s string) string {
+	var b strings.Builder
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == ';' || r == ',':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
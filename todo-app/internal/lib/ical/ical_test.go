@@ -0,0 +1,40 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	var w Writer
+	w.Begin("VTODO")
+	w.Text("SUMMARY", "Buy milk, eggs; and\r\nbread \\ butter\x00")
+	w.TextList("CATEGORIES", []string{"home", "a,b"})
+	w.Time("DUE", time.Date(2025, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600)))
+	w.End("VTODO")
+
+	assert.Equal(t, "BEGIN:VTODO\r\n"+
+		`SUMMARY:Buy milk\, eggs\; and\nbread \\ butter`+"\r\n"+
+		`CATEGORIES:home,a\,b`+"\r\n"+
+		"DUE:20250301T113000Z\r\n"+
+		"END:VTODO\r\n", string(w.Bytes()))
+}
+
+func TestFolding(t *testing.T) {
+	var w Writer
+	w.Text("DESCRIPTION", strings.Repeat("ä", 100))
+
+	lines := strings.Split(strings.TrimSuffix(string(w.Bytes()), "\r\n"), "\r\n")
+	assert.Len(t, lines, 3)
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), 75, "line %d", i)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "), "line %d", i)
+		}
+	}
+	unfolded := lines[0] + strings.TrimPrefix(lines[1], " ") + strings.TrimPrefix(lines[2], " ")
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("ä", 100), unfolded)
+}
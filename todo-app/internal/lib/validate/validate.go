@@ -9,3 +9,8 @@ var valid = validator.New()
 func IsValid(i interface{}) error {
 	return valid.Struct(i)
 }
+
+// Var validates a single value against a validator tag, e.g. "oneof=a b".
+func Var(field interface{}, tag string) error {
+	return valid.Var(field, tag)
+}
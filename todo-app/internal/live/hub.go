@@ -0,0 +1,301 @@
+// Package live fans the db service's event streams out to the connections of
+// GET /tasks/events.
+//
+// A user's connections share one WatchTasks stream, opened with the first of
+// them and closed with the last, which reconnects from the last event it got
+// when it breaks. The stream keeps the user's recent events, so a connection
+// resuming after one of them is caught up without asking the db service; one
+// resuming from further back gets a stream of its own.
+package live
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+	"todo-app/internal/lib/logger/sl"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+)
+
+// StartMetadataKey is the header in which WatchTasks sends the id it streams
+// events after, also when asked for new events only.
+const StartMetadataKey = "x-after-id"
+
+type Config struct {
+	// Replay is how many recent events of each watched user are kept for
+	// connections that resume.
+	Replay int `yaml:"replay" env:"LIVE_REPLAY" env-default:"256"`
+	// Buffer is how many events may wait for a connection. One that falls
+	// further behind is closed and has to resume.
+	Buffer int `yaml:"buffer" env:"LIVE_BUFFER" env-default:"64"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Watcher
+type Watcher interface {
+	WatchTasks(ctx context.Context, in *dbpb.WatchTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[dbpb.TaskEvent], error)
+}
+
+// ErrLagged closes the subscriptions that did not keep up with their events.
+var ErrLagged = errors.New("subscriber fell behind")
+
+// ErrClosed closes the subscriptions when the hub shuts down.
+var ErrClosed = errors.New("hub closed")
+
+// Subscription receives a user's events on Events, in id order, until it is
+// closed. Events is closed when the hub drops the subscription; Err says why.
+type Subscription struct {
+	Events <-chan *dbpb.TaskEvent
+
+	hub  *Hub
+	feed *feed
+	ch   chan *dbpb.TaskEvent
+	// err is set when the hub closes ch; guarded by hub.mu.
+	err error
+}
+
+// Err returns why Events was closed, or nil while it is open.
+func (s *Subscription) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.err
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.leave(s)
+}
+
+// feed is a WatchTasks stream and the subscriptions it serves.
+type feed struct {
+	userID int64
+	shared bool
+	cancel context.CancelFunc
+
+	// Guarded by hub.mu.
+	subs map[*Subscription]struct{}
+	// recent holds the last events, oldest first.
+	recent []*dbpb.TaskEvent
+	// floor is the id of the event just before recent, or where the stream
+	// started, -1 until it has started.
+	floor int64
+}
+
+// since returns the recent events after the one with id after. Streams send
+// events in the order their transactions committed, which ids do not always
+// follow, so they are found by position. ok is false when after is not among
+// them.
+func (f *feed) since(after int64) (events []*dbpb.TaskEvent, ok bool) {
+	if f.floor < 0 {
+		return nil, false
+	}
+	if after == f.floor {
+		return f.recent, true
+	}
+	for i, event := range f.recent {
+		if event.GetId() == after {
+			return f.recent[i+1:], true
+		}
+	}
+	return nil, false
+}
+
+type Hub struct {
+	store Watcher
+	cfg   Config
+	log   *slog.Logger
+
+	mu sync.Mutex
+	// feeds holds the shared feed of each watched user, streams every feed.
+	feeds   map[int64]*feed
+	streams map[*feed]struct{}
+	closed  bool
+}
+
+func New(store Watcher, cfg Config, log *slog.Logger) *Hub {
+	return &Hub{
+		store:   store,
+		cfg:     cfg,
+		log:     log,
+		feeds:   make(map[int64]*feed),
+		streams: make(map[*feed]struct{}),
+	}
+}
+
+// Subscribe returns a subscription to the events of userID after the event
+// with id after, or to the events from now on if after is 0.
+func (h *Hub) Subscribe(userID, after int64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		ch := make(chan *dbpb.TaskEvent)
+		close(ch)
+		return &Subscription{Events: ch, hub: h, ch: ch, err: ErrClosed}
+	}
+
+	var replay []*dbpb.TaskEvent
+	f := h.feeds[userID]
+	switch {
+	case f == nil:
+		f = h.start(userID, after, true)
+		h.feeds[userID] = f
+	case after > 0:
+		var ok bool
+		if replay, ok = f.since(after); !ok {
+			f = h.start(userID, after, false)
+		}
+	}
+
+	ch := make(chan *dbpb.TaskEvent, h.cfg.Buffer+len(replay))
+	for _, event := range replay {
+		ch <- event
+	}
+	sub := &Subscription{Events: ch, hub: h, feed: f, ch: ch}
+	f.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close ends every subscription and stream, so that the connections of
+// GET /tasks/events return before the server shuts down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for f := range h.streams {
+		for sub := range f.subs {
+			h.drop(sub, ErrClosed)
+		}
+	}
+}
+
+// start opens a feed of userID's events after the given id. The caller holds
+// h.mu.
+func (h *Hub) start(userID, after int64, shared bool) *feed {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &feed{
+		userID: userID,
+		shared: shared,
+		cancel: cancel,
+		subs:   make(map[*Subscription]struct{}),
+		floor:  -1,
+	}
+	if !shared {
+		// Only its own subscription reads it, from the start.
+		f.floor = after
+	}
+	h.streams[f] = struct{}{}
+	go h.run(mwAuth.WithUser(ctx, userID), f, after)
+	return f
+}
+
+// run streams f's events, reconnecting from the last one received when the
+// stream breaks, until f is cancelled.
+func (h *Hub) run(ctx context.Context, f *feed, after int64) {
+	const op = "todo-app/internal/live|run()"
+	log := h.log.With(slog.String("op", op), slog.Int64("user_id", f.userID))
+
+	delay := time.Second
+	for {
+		err := h.watch(ctx, f, &after)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn("event stream broken, reconnecting", slog.Duration("in", delay), sl.Err(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, 30*time.Second)
+	}
+}
+
+func (h *Hub) watch(ctx context.Context, f *feed, after *int64) error {
+	stream, err := h.store.WatchTasks(ctx, &dbpb.WatchTasksRequest{AfterId: *after})
+	if err != nil {
+		return err
+	}
+	header, err := stream.Header()
+	if err != nil {
+		return err
+	}
+	if values := header.Get(StartMetadataKey); len(values) > 0 {
+		start, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		*after = start
+		h.mu.Lock()
+		if f.floor < 0 {
+			f.floor = start
+		}
+		h.mu.Unlock()
+	}
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		*after = event.GetId()
+		h.publish(f, event)
+	}
+}
+
+// publish hands event to the subscriptions of f, dropping those whose buffer
+// is full.
+func (h *Hub) publish(f *feed, event *dbpb.TaskEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if f.shared {
+		f.recent = append(f.recent, event)
+		if over := len(f.recent) - h.cfg.Replay; over > 0 {
+			f.floor = f.recent[over-1].GetId()
+			f.recent = append(f.recent[:0:0], f.recent[over:]...)
+		}
+	}
+	for sub := range f.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			h.drop(sub, ErrLagged)
+		}
+	}
+}
+
+// drop closes sub with err. The caller holds h.mu.
+func (h *Hub) drop(sub *Subscription, err error) {
+	h.leave(sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+// leave detaches sub from its feed and stops the feed when it was the last
+// one. The caller holds h.mu.
+func (h *Hub) leave(sub *Subscription) {
+	f := sub.feed
+	if f == nil {
+		return
+	}
+	sub.feed = nil
+	delete(f.subs, sub)
+	if len(f.subs) > 0 {
+		return
+	}
+	f.cancel()
+	delete(h.streams, f)
+	if f.shared && h.feeds[f.userID] == f {
+		delete(h.feeds, f.userID)
+	}
+}
@@ -0,0 +1,265 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+	"todo-app/internal/lib/logger/slogdiscard"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeStream is a WatchTasks stream the test feeds through events; closing
+// fail breaks it.
+type fakeStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	req    *dbpb.WatchTasksRequest
+	start  int64
+	events chan *dbpb.TaskEvent
+	fail   chan struct{}
+}
+
+func (s *fakeStream) Header() (metadata.MD, error) {
+	return metadata.Pairs(StartMetadataKey, strconv.FormatInt(s.start, 10)), nil
+}
+
+func (s *fakeStream) Recv() (*dbpb.TaskEvent, error) {
+	select {
+	case event := <-s.events:
+		return event, nil
+	case <-s.fail:
+		return nil, errors.New("connection reset")
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+// fakeWatcher hands every stream it opens to the test. New events start
+// after id 100.
+type fakeWatcher struct {
+	streams chan *fakeStream
+}
+
+func (w *fakeWatcher) WatchTasks(ctx context.Context, in *dbpb.WatchTasksRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[dbpb.TaskEvent], error) {
+	s := &fakeStream{
+		ctx:    ctx,
+		req:    in,
+		start:  in.GetAfterId(),
+		events: make(chan *dbpb.TaskEvent),
+		fail:   make(chan struct{}),
+	}
+	if s.start == 0 {
+		s.start = 100
+	}
+	if claims, ok := mwAuth.ClaimsFromContext(ctx); !ok || claims.UserID == 0 {
+		return nil, errors.New("no user")
+	}
+	w.streams <- s
+	return s, nil
+}
+
+func newHub(cfg Config) (*Hub, *fakeWatcher) {
+	w := &fakeWatcher{streams: make(chan *fakeStream, 4)}
+	return New(w, cfg, slogdiscard.NewDiscardLogger()), w
+}
+
+func (w *fakeWatcher) next(t *testing.T) *fakeStream {
+	t.Helper()
+	select {
+	case s := <-w.streams:
+		return s
+	case <-time.After(time.Second):
+		t.Fatal("no stream opened")
+		return nil
+	}
+}
+
+func (w *fakeWatcher) none(t *testing.T) {
+	t.Helper()
+	select {
+	case s := <-w.streams:
+		t.Fatalf("unexpected stream after %d", s.req.GetAfterId())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func receive(t *testing.T, sub *Subscription) *dbpb.TaskEvent {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events:
+		require.True(t, ok, "subscription closed: %v", sub.Err())
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func event(id int64) *dbpb.TaskEvent {
+	return &dbpb.TaskEvent{Id: id, EventType: "task.updated"}
+}
+
+// started waits until the feed of sub knows where its stream starts.
+func started(t *testing.T, h *Hub, sub *Subscription) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return sub.feed.floor >= 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestConnectionsShareAStream(t *testing.T) {
+	h, w := newHub(Config{Replay: 10, Buffer: 10})
+	first := h.Subscribe(7, 0)
+	defer first.Close()
+	second := h.Subscribe(7, 0)
+	defer second.Close()
+
+	stream := w.next(t)
+	assert.EqualValues(t, 0, stream.req.GetAfterId())
+	w.none(t)
+
+	stream.events <- event(101)
+	assert.EqualValues(t, 101, receive(t, first).GetId())
+	assert.EqualValues(t, 101, receive(t, second).GetId())
+}
+
+func TestResumeFromRecentEvents(t *testing.T) {
+	h, w := newHub(Config{Replay: 10, Buffer: 10})
+	first := h.Subscribe(7, 0)
+	defer first.Close()
+	stream := w.next(t)
+	for _, id := range []int64{101, 105, 109} {
+		stream.events <- event(id)
+		receive(t, first)
+	}
+
+	resumed := h.Subscribe(7, 101)
+	defer resumed.Close()
+	w.none(t)
+	assert.EqualValues(t, 105, receive(t, resumed).GetId())
+	assert.EqualValues(t, 109, receive(t, resumed).GetId())
+}
+
+// TestResumeFollowsStreamOrder resumes after an event sent before one with a
+// lower id, as happens when transactions commit out of id order.
+func TestResumeFollowsStreamOrder(t *testing.T) {
+	h, w := newHub(Config{Replay: 10, Buffer: 10})
+	first := h.Subscribe(7, 0)
+	defer first.Close()
+	stream := w.next(t)
+	for _, id := range []int64{101, 109, 105} {
+		stream.events <- event(id)
+		receive(t, first)
+	}
+
+	resumed := h.Subscribe(7, 109)
+	defer resumed.Close()
+	w.none(t)
+	assert.EqualValues(t, 105, receive(t, resumed).GetId())
+}
+
+func TestResumeFromOlderEventsOpensAStream(t *testing.T) {
+	h, w := newHub(Config{Replay: 2, Buffer: 10})
+	first := h.Subscribe(7, 0)
+	defer first.Close()
+	stream := w.next(t)
+	started(t, h, first)
+	for _, id := range []int64{101, 102, 103} {
+		stream.events <- event(id)
+		receive(t, first)
+	}
+
+	// Only 102 and 103 are kept, so 101 cannot be replayed.
+	resumed := h.Subscribe(7, 100)
+	defer resumed.Close()
+	own := w.next(t)
+	assert.EqualValues(t, 100, own.req.GetAfterId())
+	own.events <- event(101)
+	assert.EqualValues(t, 101, receive(t, resumed).GetId())
+
+	caughtUp := h.Subscribe(7, 101)
+	defer caughtUp.Close()
+	w.none(t)
+	assert.EqualValues(t, 102, receive(t, caughtUp).GetId())
+	assert.EqualValues(t, 103, receive(t, caughtUp).GetId())
+}
+
+func TestStreamReconnectsAfterLastEvent(t *testing.T) {
+	h, w := newHub(Config{Replay: 10, Buffer: 10})
+	sub := h.Subscribe(7, 0)
+	defer sub.Close()
+	stream := w.next(t)
+	stream.events <- event(104)
+	receive(t, sub)
+
+	close(stream.fail)
+	select {
+	case again := <-w.streams:
+		assert.EqualValues(t, 104, again.req.GetAfterId())
+	case <-time.After(3 * time.Second):
+		t.Fatal("stream not reopened")
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	h, w := newHub(Config{Replay: 10, Buffer: 1})
+	slow := h.Subscribe(7, 0)
+	defer slow.Close()
+	fast := h.Subscribe(7, 0)
+	defer fast.Close()
+	stream := w.next(t)
+
+	stream.events <- event(101)
+	receive(t, fast)
+	stream.events <- event(102)
+	receive(t, fast)
+
+	assert.EqualValues(t, 101, receive(t, slow).GetId())
+	_, ok := <-slow.Events
+	assert.False(t, ok)
+	assert.ErrorIs(t, slow.Err(), ErrLagged)
+}
+
+func TestLastSubscriberStopsTheStream(t *testing.T) {
+	h, w := newHub(Config{Replay: 10, Buffer: 10})
+	sub := h.Subscribe(7, 0)
+	stream := w.next(t)
+	sub.Close()
+	sub.Close()
+
+	select {
+	case <-stream.ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stream not cancelled")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	assert.Empty(t, h.feeds)
+	assert.Empty(t, h.streams)
+}
+
+func TestClose(t *testing.T) {
+	h, w := newHub(Config{Replay: 10, Buffer: 10})
+	sub := h.Subscribe(7, 0)
+	w.next(t)
+
+	h.Close()
+	_, ok := <-sub.Events
+	assert.False(t, ok)
+	assert.ErrorIs(t, sub.Err(), ErrClosed)
+
+	late := h.Subscribe(8, 0)
+	_, ok = <-late.Events
+	assert.False(t, ok)
+	w.none(t)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"todo-app/internal/domain/response"
 	"todo-app/internal/token"
 	"strings"
 
@@ -15,6 +16,18 @@ type contextKey string
 
 const UserKey contextKey = "user"
 
+// ClaimsFromContext returns the claims AuthMiddleware stored in the request context.
+func ClaimsFromContext(ctx context.Context) (*token.Claims, bool) {
+	claims, ok := ctx.Value(UserKey).(*token.Claims)
+	return claims, ok
+}
+
+// WithUser returns a context that acts as userID, for work done on a user's
+// behalf outside of a request, such as handling their events.
+func WithUser(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, UserKey, &token.Claims{UserID: userID, TokenType: "access"})
+}
+
 func AuthMiddleware(tokenMn *token.TokenManager, log *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -23,7 +36,7 @@ func AuthMiddleware(tokenMn *token.TokenManager, log *slog.Logger) func(http.Han
 			if authHeader == "" {
 				log.Warn("Authorization header missing")
 				render.Status(r, http.StatusUnauthorized)
-				render.JSON(w, r, map[string]string{"error": "token required"})
+				render.JSON(w, r, response.Error("token required"))
 				return
 			}
 
@@ -34,11 +47,11 @@ func AuthMiddleware(tokenMn *token.TokenManager, log *slog.Logger) func(http.Han
 				if errors.Is(err, token.ErrTokenExpired) {
 					log.Warn("Token expired", "error", err)
 					render.Status(r, http.StatusUnauthorized)
-					render.JSON(w, r, map[string]string{"error": "token expired"})
+					render.JSON(w, r, response.Error("token expired"))
 				} else {
 					log.Warn("Token validation failed", "error", err)
 					render.Status(r, http.StatusUnauthorized)
-					render.JSON(w, r, map[string]string{"error": "invalid token"})
+					render.JSON(w, r, response.Error("invalid token"))
 				}
 				return
 
@@ -47,7 +60,7 @@ func AuthMiddleware(tokenMn *token.TokenManager, log *slog.Logger) func(http.Han
 			if claims.TokenType != "access" {
 				log.Warn("Invalid token type", "type", claims.TokenType)
 				render.Status(r, http.StatusUnauthorized)
-				render.JSON(w, r, map[string]string{"error": "invalid token type"})
+				render.JSON(w, r, response.Error("invalid token type"))
 				return
 			}
 
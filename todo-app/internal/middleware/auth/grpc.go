@@ -0,0 +1,61 @@
+package mwAuth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UserIDMetadataKey must match the key the db service reads the caller from.
+const UserIDMetadataKey = "x-user-id"
+
+// RequestIDMetadataKey passes the HTTP request id on, so the db service can tie
+// its audit records to the access log.
+const RequestIDMetadataKey = "x-request-id"
+
+// validRequestID matches the ids chi's RequestID middleware makes
+// ("host/AbCdEf1234-000001") and sane ids of clients. Anything else, such as
+// an X-Request-Id header with control characters or of unbounded length, is
+// not passed on.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/-]{1,128}$`)
+
+// requestID returns the request id to pass to the db service, or a fresh one
+// when the request has none or one that does not fit validRequestID.
+func requestID(ctx context.Context) string {
+	if id := middleware.GetReqID(ctx); validRequestID.MatchString(id) {
+		return id
+	}
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func withCaller(ctx context.Context) context.Context {
+	ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, requestID(ctx))
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, UserIDMetadataKey, strconv.FormatInt(claims.UserID, 10))
+}
+
+// UnaryClientInterceptor forwards the authenticated user id and the request id
+// to the db service on every call.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withCaller(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withCaller(ctx), desc, cc, method, opts...)
+	}
+}
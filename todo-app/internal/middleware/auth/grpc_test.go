@@ -0,0 +1,51 @@
+package mwAuth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithCallerRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		wantKept bool
+	}{
+		{name: "Made by chi", id: "todo-app-5f7d/Ab3dEf9Hk2-000042", wantKept: true},
+		{name: "UUID", id: "0b7e5f9c-6c1a-4c39-9f0e-3f3c2d1e8a77", wantKept: true},
+		{name: "Missing", id: ""},
+		{name: "Newline", id: "req-1\nlevel=ERROR msg=forged"},
+		{name: "Too long", id: strings.Repeat("a", 129)},
+		{name: "Not ASCII", id: "запрос-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), middleware.RequestIDKey, tt.id)
+
+			md, ok := metadata.FromOutgoingContext(withCaller(ctx))
+			require.True(t, ok)
+			got := md.Get(RequestIDMetadataKey)
+			require.Len(t, got, 1)
+			if tt.wantKept {
+				assert.Equal(t, tt.id, got[0])
+			} else {
+				assert.NotEqual(t, tt.id, got[0])
+				assert.Regexp(t, validRequestID, got[0])
+			}
+		})
+	}
+}
+
+func TestWithCallerUserID(t *testing.T) {
+	ctx := WithUser(context.Background(), 42)
+
+	md, ok := metadata.FromOutgoingContext(withCaller(ctx))
+	require.True(t, ok)
+	assert.Equal(t, []string{"42"}, md.Get(UserIDMetadataKey))
+}
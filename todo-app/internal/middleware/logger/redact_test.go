@@ -0,0 +1,49 @@
+package mwLogger
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := middleware.DefaultLogger
+	middleware.DefaultLogger = middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: log.New(&buf, "", 0), NoColor: true})
+	t.Cleanup(func() { middleware.DefaultLogger = defaultLogger })
+
+	var got string
+	handler := Redacted(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("token") + " " + r.RequestURI
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/calendar.ics?lang=en&token=s3cr3t-feed-token&tok%65n=again", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := buf.String()
+	assert.NotContains(t, line, "s3cr3t-feed-token")
+	assert.NotContains(t, line, "again")
+	assert.Contains(t, line, "/calendar.ics?lang=en&token=REDACTED&tok%65n=REDACTED")
+	// The handler still gets the token.
+	assert.Equal(t, "s3cr3t-feed-token /calendar.ics?lang=en&token=s3cr3t-feed-token&tok%65n=again", got)
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "/tasks", want: "/tasks"},
+		{uri: "/tasks?limit=5", want: "/tasks?limit=5"},
+		{uri: "/calendar.ics?token=abc", want: "/calendar.ics?token=REDACTED"},
+		{uri: "/calendar.ics?token", want: "/calendar.ics?token=REDACTED"},
+		{uri: "/calendar.ics?%zz=abc", want: "/calendar.ics?%zz=REDACTED"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, redactQuery(tt.uri), tt.uri)
+	}
+}
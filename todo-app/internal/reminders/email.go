@@ -0,0 +1,121 @@
+package reminders
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig is the mail server email reminders go through. Without a Host
+// email reminders are not sent.
+type SMTPConfig struct {
+	Host     string        `yaml:"host" env:"SMTP_HOST"`
+	Port     int           `yaml:"port" env:"SMTP_PORT" env-default:"587"`
+	Username string        `yaml:"username" env:"SMTP_USERNAME"`
+	Password string        `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string        `yaml:"from" env:"SMTP_FROM" env-default:"todo-app@localhost"`
+	Timeout  time.Duration `yaml:"timeout" env:"SMTP_TIMEOUT" env-default:"10s"`
+}
+
+// Email mails reminders to the address in the user's notification settings.
+// It uses STARTTLS when the server offers it, and authenticates when a
+// Username is set.
+type Email struct {
+	cfg SMTPConfig
+	now func() time.Time
+}
+
+func NewEmail(cfg SMTPConfig) *Email {
+	return &Email{cfg: cfg, now: time.Now}
+}
+
+func (e *Email) Name() string { return ChannelEmail }
+
+func (e *Email) Send(ctx context.Context, r Reminder) error {
+	if r.Email == "" {
+		return fmt.Errorf("%w: no email address in the notification settings", ErrUndeliverable)
+	}
+	msg, err := e.message(r)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(r.Email); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message returns the mail for r. Its Message-ID is the same for every
+// attempt, so clients can drop duplicates.
+func (e *Email) message(r Reminder) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", e.cfg.From)
+	header("To", r.Email)
+	header("Subject", mime.QEncoding.Encode("utf-8", "Reminder: "+r.Title))
+	header("Date", e.now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%d.%d.reminder@todo-app>", r.ID, r.DueAt.Unix()))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	_, err := fmt.Fprintf(body, "%s\r\n\r\nDue %s.\r\n", r.Title, r.DueAt.UTC().Format("Mon, 02 Jan 2006 15:04 MST"))
+	if err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+package reminders
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpServer accepts one session on a local port, like the SMTP stand-in of
+// compose.yaml, and hands the envelope and message to the test.
+type smtpServer struct {
+	addr *net.TCPAddr
+	from string
+	to   []string
+	data chan string
+}
+
+func startSMTP(t *testing.T) *smtpServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	s := &smtpServer{addr: ln.Addr().(*net.TCPAddr), data: make(chan string, 1)}
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				s.from = strings.Trim(strings.TrimPrefix(cmd, "MAIL FROM:"), "<>")
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				s.to = append(s.to, strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<>"))
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var msg strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					msg.WriteString(line)
+				}
+				s.data <- msg.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return s
+}
+
+func TestEmailSend(t *testing.T) {
+	srv := startSMTP(t)
+	email := NewEmail(SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    srv.addr.Port,
+		From:    "reminders@todo.example",
+		Timeout: time.Second,
+	})
+	email.now = func() time.Time { return now }
+
+	err := email.Send(context.Background(), Reminder{
+		ID:     3,
+		UserID: 7,
+		TaskID: 12,
+		Title:  "Steuererklärung\r\nBcc: eve@example.com",
+		DueAt:  dueAt.AsTime(),
+		Email:  "ann@example.com",
+	})
+	require.NoError(t, err)
+
+	var data string
+	select {
+	case data = <-srv.data:
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+	assert.Equal(t, "reminders@todo.example", srv.from)
+	assert.Equal(t, []string{"ann@example.com"}, srv.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "ann@example.com", msg.Header.Get("To"))
+	assert.Empty(t, msg.Header.Get("Bcc"))
+	assert.Equal(t, "<3."+strconv.FormatInt(dueAt.AsTime().Unix(), 10)+".reminder@todo-app>", msg.Header.Get("Message-ID"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Reminder: Steuererklärung\r\nBcc: eve@example.com", subject)
+
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Due Sat, 01 Mar 2025 10:00 UTC.")
+}
+
+func TestEmailWithoutAddress(t *testing.T) {
+	err := NewEmail(SMTPConfig{Host: "127.0.0.1", Port: 1, Timeout: time.Second}).Send(context.Background(), Reminder{ID: 3})
+
+	assert.ErrorIs(t, err, ErrUndeliverable)
+}
@@ -0,0 +1,44 @@
+package reminders
+
+import (
+	"context"
+	"time"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+)
+
+// maxTitle is the longest notification title the db service takes.
+const maxTitle = 255
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=NotificationStore
+type NotificationStore interface {
+	CreateNotification(ctx context.Context, in *dbpb.CreateNotificationRequest, opts ...grpc.CallOption) (*dbpb.Notification, error)
+}
+
+// Inbox puts reminders in the user's in-app inbox, GET /notifications.
+type Inbox struct {
+	store NotificationStore
+}
+
+func NewInbox(store NotificationStore) *Inbox {
+	return &Inbox{store: store}
+}
+
+func (i *Inbox) Name() string { return ChannelInApp }
+
+func (i *Inbox) Send(ctx context.Context, r Reminder) error {
+	title := []rune("Reminder: " + r.Title)
+	if len(title) > maxTitle {
+		title = append(title[:maxTitle-1], '…')
+	}
+	_, err := i.store.CreateNotification(mwAuth.WithUser(ctx, r.UserID), &dbpb.CreateNotificationRequest{
+		Kind:     "reminder",
+		Title:    string(title),
+		Body:     "Due " + r.DueAt.UTC().Format(time.RFC3339),
+		TaskId:   r.TaskID,
+		DedupKey: r.Key(),
+	})
+	return err
+}
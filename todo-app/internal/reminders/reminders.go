@@ -0,0 +1,208 @@
+// Package reminders sends the reminders users set on their tasks when they
+// fall due.
+//
+// The db service stores the reminders and works out which are due, so they
+// survive restarts. Scheduler polls it for them; ClaimDueReminders leases
+// each one to a single replica with SELECT ... FOR UPDATE SKIP LOCKED, so
+// replicas never send the same reminder at once. A reminder goes out through
+// each of its channels; channels that fail are retried with exponential
+// backoff while the others are not sent again. Sink, a consumer sink, gives
+// tasks a default reminder when they get a due date.
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+	"todo-app/internal/lib/backoff"
+	"todo-app/internal/lib/logger/sl"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Channel names, as stored with the reminders.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelInApp   = "in_app"
+)
+
+type Config struct {
+	Enabled      bool          `yaml:"enabled" env:"REMINDERS_ENABLED" env-default:"true"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"REMINDERS_POLL_INTERVAL" env-default:"15s"`
+	BatchSize    int           `yaml:"batch_size" env:"REMINDERS_BATCH_SIZE" env-default:"50"`
+	// Lease is how long a reminder is reserved for the replica sending it.
+	// It must be longer than sending through every channel takes.
+	Lease time.Duration `yaml:"lease" env:"REMINDERS_LEASE" env-default:"2m"`
+	// MaxAttempts includes the first one.
+	MaxAttempts int `yaml:"max_attempts" env:"REMINDERS_MAX_ATTEMPTS" env-default:"5"`
+	// The n-th retry waits BaseDelay * 2^(n-1), at most MaxDelay.
+	BaseDelay time.Duration `yaml:"base_delay" env:"REMINDERS_BASE_DELAY" env-default:"1m"`
+	MaxDelay  time.Duration `yaml:"max_delay" env:"REMINDERS_MAX_DELAY" env-default:"30m"`
+	// MaxLateness drops reminders that should have gone out longer ago, so
+	// an outage does not end in a burst of stale reminders.
+	MaxLateness time.Duration `yaml:"max_lateness" env:"REMINDERS_MAX_LATENESS" env-default:"1h"`
+	SMTP        SMTPConfig    `yaml:"smtp"`
+	// Default is added to tasks by the consumer sink, see Sink.
+	Default DefaultReminder `yaml:"default"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Store
+type Store interface {
+	ClaimDueReminders(ctx context.Context, in *dbpb.ClaimDueRemindersRequest, opts ...grpc.CallOption) (*dbpb.ReminderJobs, error)
+	RecordReminderAttempt(ctx context.Context, in *dbpb.RecordReminderAttemptRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+// Reminder is a reminder that is due.
+type Reminder struct {
+	ID            int64
+	UserID        int64
+	TaskID        int64
+	Title         string
+	DueAt         time.Time
+	OffsetMinutes int32
+	// Email is the address from the user's notification settings.
+	Email string
+}
+
+// Key identifies the reminder for the due date it fires for; channels
+// deduplicate on it, since a reminder may be sent more than once.
+func (r Reminder) Key() string {
+	return fmt.Sprintf("reminder:%d:%d", r.ID, r.DueAt.Unix())
+}
+
+// Channel delivers reminders one way.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, r Reminder) error
+}
+
+// ErrUndeliverable is returned by channels that cannot deliver a reminder
+// however often they try, such as email to a user without an address.
+var ErrUndeliverable = errors.New("reminder cannot be delivered")
+
+type Scheduler struct {
+	store    Store
+	cfg      Config
+	channels map[string]Channel
+	log      *slog.Logger
+	now      func() time.Time
+}
+
+func New(store Store, cfg Config, log *slog.Logger, channels ...Channel) *Scheduler {
+	s := &Scheduler{
+		store:    store,
+		cfg:      cfg,
+		channels: make(map[string]Channel, len(channels)),
+		log:      log,
+		now:      time.Now,
+	}
+	for _, ch := range channels {
+		s.channels[ch.Name()] = ch
+	}
+	return s
+}
+
+// Run sends the reminders that are due until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	const op = "todo-app/internal/reminders|Run()"
+	log := s.log.With(slog.String("op", op))
+
+	for {
+		jobs, err := s.store.ClaimDueReminders(ctx, &dbpb.ClaimDueRemindersRequest{
+			Limit:              int32(s.cfg.BatchSize),
+			LeaseSeconds:       int32(s.cfg.Lease / time.Second),
+			MaxLatenessSeconds: int32(s.cfg.MaxLateness / time.Second),
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Error("failed to claim due reminders", sl.Err(err))
+		}
+		var wg sync.WaitGroup
+		for _, job := range jobs.GetJobs() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.fire(ctx, job)
+			}()
+		}
+		wg.Wait()
+
+		wait := s.cfg.PollInterval
+		if err == nil && len(jobs.GetJobs()) == s.cfg.BatchSize {
+			// More are due.
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// fire sends job through the channels that did not get it yet and records
+// the outcome. Should recording fail, the lease runs out and the channels
+// that got it are sent it again.
+func (s *Scheduler) fire(ctx context.Context, job *dbpb.ReminderJob) {
+	const op = "todo-app/internal/reminders|fire()"
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("reminder_id", job.GetReminderId()),
+		slog.Int64("task_id", job.GetTaskId()),
+	)
+
+	r := Reminder{
+		ID:            job.GetReminderId(),
+		UserID:        job.GetUserId(),
+		TaskID:        job.GetTaskId(),
+		Title:         job.GetTaskTitle(),
+		DueAt:         job.GetDueAt().AsTime(),
+		OffsetMinutes: job.GetOffsetMinutes(),
+		Email:         job.GetEmail(),
+	}
+	var sent []string
+	failed := false
+	for _, name := range job.GetChannels() {
+		if slices.Contains(job.GetSentChannels(), name) {
+			continue
+		}
+		ch, ok := s.channels[name]
+		if !ok {
+			log.Warn("reminder channel is not configured", slog.String("channel", name))
+			continue
+		}
+		err := ch.Send(ctx, r)
+		switch {
+		case errors.Is(err, ErrUndeliverable):
+			log.Info("reminder not delivered", slog.String("channel", name), sl.Err(err))
+		case err != nil:
+			failed = true
+			log.Warn("failed to send reminder", slog.String("channel", name), sl.Err(err))
+		default:
+			sent = append(sent, name)
+		}
+	}
+
+	record := &dbpb.RecordReminderAttemptRequest{
+		ReminderId:   job.GetReminderId(),
+		DueAt:        job.GetDueAt(),
+		SentChannels: sent,
+	}
+	attempts := int(job.GetAttempts()) + 1
+	if failed && attempts < s.cfg.MaxAttempts {
+		record.RetryAt = timestamppb.New(s.now().Add(backoff.Delay(s.cfg.BaseDelay, s.cfg.MaxDelay, attempts)))
+	} else if failed {
+		log.Error("giving up on reminder", slog.Int("attempts", attempts))
+	}
+	if _, err := s.store.RecordReminderAttempt(mwAuth.WithUser(ctx, job.GetUserId()), record); err != nil {
+		log.Error("failed to record reminder attempt", sl.Err(err))
+	}
+}
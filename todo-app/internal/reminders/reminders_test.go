@@ -0,0 +1,183 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-app/internal/lib/logger/slogdiscard"
+	mwAuth "todo-app/internal/middleware/auth"
+	"todo-app/internal/reminders/mocks"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// channel records the reminders it gets and fails with err.
+type channel struct {
+	name string
+	err  error
+	got  []Reminder
+}
+
+func (c *channel) Name() string { return c.name }
+
+func (c *channel) Send(_ context.Context, r Reminder) error {
+	c.got = append(c.got, r)
+	return c.err
+}
+
+var (
+	now   = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	dueAt = timestamppb.New(time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC))
+)
+
+func newScheduler(store Store, channels ...Channel) *Scheduler {
+	s := New(store, Config{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}, slogdiscard.NewDiscardLogger(), channels...)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+// forUser matches contexts that call the db service as userID.
+func forUser(userID int64) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		claims, ok := mwAuth.ClaimsFromContext(ctx)
+		return ok && claims.UserID == userID
+	})
+}
+
+func TestFireSendsThroughEachChannel(t *testing.T) {
+	email := &channel{name: ChannelEmail}
+	inbox := &channel{name: ChannelInApp}
+	store := mocks.NewStore(t)
+	store.On("RecordReminderAttempt", forUser(7), &dbpb.RecordReminderAttemptRequest{
+		ReminderId:   3,
+		DueAt:        dueAt,
+		SentChannels: []string{ChannelEmail, ChannelInApp},
+	}).Return(&emptypb.Empty{}, nil)
+
+	newScheduler(store, email, inbox).fire(context.Background(), &dbpb.ReminderJob{
+		ReminderId:    3,
+		UserId:        7,
+		TaskId:        12,
+		TaskTitle:     "File taxes",
+		DueAt:         dueAt,
+		OffsetMinutes: 60,
+		Channels:      []string{ChannelEmail, ChannelInApp},
+		Email:         "ann@example.com",
+	})
+
+	want := Reminder{ID: 3, UserID: 7, TaskID: 12, Title: "File taxes", DueAt: dueAt.AsTime(), OffsetMinutes: 60, Email: "ann@example.com"}
+	assert.Equal(t, []Reminder{want}, email.got)
+	assert.Equal(t, []Reminder{want}, inbox.got)
+}
+
+func TestFireRetriesFailedChannelsOnly(t *testing.T) {
+	email := &channel{name: ChannelEmail}
+	inbox := &channel{name: ChannelInApp}
+	hook := &channel{name: ChannelWebhook, err: errors.New("connection refused")}
+	store := mocks.NewStore(t)
+	store.On("RecordReminderAttempt", forUser(7), &dbpb.RecordReminderAttemptRequest{
+		ReminderId:   3,
+		DueAt:        dueAt,
+		SentChannels: []string{ChannelInApp},
+		// The second attempt waits twice the base delay.
+		RetryAt: timestamppb.New(now.Add(2 * time.Minute)),
+	}).Return(&emptypb.Empty{}, nil)
+
+	newScheduler(store, email, inbox, hook).fire(context.Background(), &dbpb.ReminderJob{
+		ReminderId:   3,
+		UserId:       7,
+		DueAt:        dueAt,
+		Channels:     []string{ChannelEmail, ChannelInApp, ChannelWebhook},
+		SentChannels: []string{ChannelEmail},
+		Attempts:     1,
+	})
+
+	assert.Empty(t, email.got)
+	assert.Len(t, inbox.got, 1)
+	assert.Len(t, hook.got, 1)
+}
+
+func TestFireGivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempts int32
+	}{
+		{name: "Last attempt", err: errors.New("connection refused"), attempts: 2},
+		{name: "Undeliverable", err: ErrUndeliverable, attempts: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewStore(t)
+			store.On("RecordReminderAttempt", mock.Anything, &dbpb.RecordReminderAttemptRequest{
+				ReminderId: 3,
+				DueAt:      dueAt,
+			}).Return(&emptypb.Empty{}, nil)
+
+			newScheduler(store, &channel{name: ChannelEmail, err: tt.err}).fire(context.Background(), &dbpb.ReminderJob{
+				ReminderId: 3,
+				UserId:     7,
+				DueAt:      dueAt,
+				Channels:   []string{ChannelEmail},
+				Attempts:   tt.attempts,
+			})
+		})
+	}
+}
+
+func TestRunClaimsDueReminders(t *testing.T) {
+	inbox := &channel{name: ChannelInApp}
+	store := mocks.NewStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store.On("ClaimDueReminders", mock.Anything, &dbpb.ClaimDueRemindersRequest{
+		Limit:              10,
+		LeaseSeconds:       120,
+		MaxLatenessSeconds: 3600,
+	}).Return(&dbpb.ReminderJobs{Jobs: []*dbpb.ReminderJob{
+		{ReminderId: 3, UserId: 7, DueAt: dueAt, Channels: []string{ChannelInApp}},
+	}}, nil).Once()
+	store.On("RecordReminderAttempt", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(&emptypb.Empty{}, nil)
+
+	s := New(store, Config{
+		PollInterval: time.Hour,
+		BatchSize:    10,
+		Lease:        2 * time.Minute,
+		MaxLateness:  time.Hour,
+	}, slogdiscard.NewDiscardLogger(), inbox)
+	s.Run(ctx)
+
+	assert.Len(t, inbox.got, 1)
+}
+
+func TestWebhookEventID(t *testing.T) {
+	r := Reminder{ID: 3, DueAt: dueAt.AsTime()}
+	id := eventID(r.Key())
+
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	assert.Equal(t, id, eventID(r.Key()))
+	r.DueAt = r.DueAt.Add(time.Hour)
+	assert.NotEqual(t, id, eventID(r.Key()))
+}
+
+func TestInboxSend(t *testing.T) {
+	store := mocks.NewNotificationStore(t)
+	store.On("CreateNotification", forUser(7), &dbpb.CreateNotificationRequest{
+		Kind:     "reminder",
+		Title:    "Reminder: File taxes",
+		Body:     "Due 2025-03-01T10:00:00Z",
+		TaskId:   12,
+		DedupKey: "reminder:3:1740823200",
+	}).Return(&dbpb.Notification{Id: 1}, nil)
+
+	err := NewInbox(store).Send(context.Background(), Reminder{ID: 3, UserID: 7, TaskID: 12, Title: "File taxes", DueAt: dueAt.AsTime()})
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,79 @@
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/kafka/consumer"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultReminder is the reminder a task gets when it is given a due date.
+// Without channels tasks get none.
+type DefaultReminder struct {
+	// Offset is how long before the due date it fires, in whole minutes.
+	Offset   time.Duration `yaml:"offset" env:"REMINDERS_DEFAULT_OFFSET" env-default:"0s"`
+	Channels []string      `yaml:"channels" env:"REMINDERS_DEFAULT_CHANNELS" env-default:"in_app"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ReminderCreator
+type ReminderCreator interface {
+	CreateReminder(ctx context.Context, in *dbpb.CreateReminderRequest, opts ...grpc.CallOption) (*dbpb.Reminder, error)
+}
+
+// Sink is the consumer sink of the scheduler: it stores the default reminder
+// of every task that is given a due date, which Scheduler then sends. Nothing
+// needs cancelling, since reminders follow the due date of their task and
+// are not sent for tasks that are done, trashed or deleted.
+type Sink struct {
+	store ReminderCreator
+	cfg   DefaultReminder
+}
+
+func NewSink(store ReminderCreator, cfg DefaultReminder) *Sink {
+	return &Sink{store: store, cfg: cfg}
+}
+
+func (s *Sink) Name() string { return "notifications" }
+
+// Handle creates the default reminder when a task is created with a due date
+// or given one. Only the owner of a task can add reminders to it, so tasks
+// that an editor of a shared task gives a due date get none.
+func (s *Sink) Handle(ctx context.Context, event *events.Event) error {
+	if len(s.cfg.Channels) == 0 || event.UserID == 0 || !givesDueDate(event) {
+		return nil
+	}
+	_, err := s.store.CreateReminder(mwAuth.WithUser(ctx, event.UserID), &dbpb.CreateReminderRequest{
+		TaskId:        event.TaskID,
+		OffsetMinutes: int32(s.cfg.Offset / time.Minute),
+		Channels:      s.cfg.Channels,
+	})
+	switch status.Code(err) {
+	case codes.OK,
+		// The user set that reminder already, or as many as a task can have.
+		codes.AlreadyExists, codes.FailedPrecondition,
+		// The task is gone, or not the caller's.
+		codes.NotFound:
+		return nil
+	case codes.InvalidArgument:
+		return consumer.Permanent(fmt.Errorf("create reminder: %w", err))
+	}
+	return fmt.Errorf("create reminder: %w", err)
+}
+
+func givesDueDate(event *events.Event) bool {
+	if event.Type != events.TaskCreated && event.Type != events.TaskUpdated {
+		return false
+	}
+	after := event.After
+	if after == nil || after.DueAt == nil || after.IsDone || after.DeletedAt != nil {
+		return false
+	}
+	return event.Before == nil || event.Before.DueAt == nil
+}
@@ -0,0 +1,81 @@
+package reminders
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/kafka/consumer"
+	"todo-app/internal/reminders/mocks"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSink(t *testing.T) {
+	due := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	later := due.Add(24 * time.Hour)
+	tests := []struct {
+		name   string
+		event  *events.Event
+		create bool
+	}{
+		{"Created with due date", &events.Event{Type: events.TaskCreated, UserID: 7, TaskID: 1, After: &events.Task{ID: 1, DueAt: &due}}, true},
+		{"Created without due date", &events.Event{Type: events.TaskCreated, UserID: 7, TaskID: 1, After: &events.Task{ID: 1}}, false},
+		{"Given a due date", &events.Event{Type: events.TaskUpdated, UserID: 7, TaskID: 1, Before: &events.Task{ID: 1}, After: &events.Task{ID: 1, DueAt: &due}}, true},
+		// The reminders of the task follow the due date by themselves.
+		{"Due date moved", &events.Event{Type: events.TaskUpdated, UserID: 7, TaskID: 1, Before: &events.Task{ID: 1, DueAt: &due}, After: &events.Task{ID: 1, DueAt: &later}}, false},
+		{"Done", &events.Event{Type: events.TaskDone, UserID: 7, TaskID: 1, Before: &events.Task{ID: 1}, After: &events.Task{ID: 1, DueAt: &due, IsDone: true}}, false},
+		{"Without user", &events.Event{Type: events.TaskCreated, TaskID: 1, After: &events.Task{ID: 1, DueAt: &due}}, false},
+		{"Comment", &events.Event{Type: events.CommentAdded, UserID: 7, TaskID: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewReminderCreator(t)
+			if tt.create {
+				store.On("CreateReminder", forUser(7), &dbpb.CreateReminderRequest{
+					TaskId:        1,
+					OffsetMinutes: 15,
+					Channels:      []string{ChannelInApp},
+				}).Return(&dbpb.Reminder{Id: 3}, nil)
+			}
+			sink := NewSink(store, DefaultReminder{Offset: 15 * time.Minute, Channels: []string{ChannelInApp}})
+			assert.NoError(t, sink.Handle(context.Background(), tt.event))
+		})
+	}
+}
+
+func TestSinkErrors(t *testing.T) {
+	due := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	event := &events.Event{Type: events.TaskCreated, UserID: 7, TaskID: 1, After: &events.Task{ID: 1, DueAt: &due}}
+	tests := []struct {
+		name      string
+		err       error
+		wantErr   bool
+		permanent bool
+	}{
+		{name: "Already set", err: status.Error(codes.AlreadyExists, "already exists")},
+		{name: "Not the owner", err: status.Error(codes.NotFound, "not found")},
+		{name: "Too many reminders", err: status.Error(codes.FailedPrecondition, "too many")},
+		{name: "Bad channel", err: status.Error(codes.InvalidArgument, "unknown channel"), wantErr: true, permanent: true},
+		{name: "Unavailable", err: status.Error(codes.Unavailable, "unavailable"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewReminderCreator(t)
+			store.On("CreateReminder", mock.Anything, mock.Anything).Return(nil, tt.err)
+			err := NewSink(store, DefaultReminder{Channels: []string{ChannelInApp}}).Handle(context.Background(), event)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.permanent, consumer.IsPermanent(err))
+		})
+	}
+}
+
+func TestSinkWithoutChannels(t *testing.T) {
+	due := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	event := &events.Event{Type: events.TaskCreated, UserID: 7, TaskID: 1, After: &events.Task{ID: 1, DueAt: &due}}
+	assert.NoError(t, NewSink(mocks.NewReminderCreator(t), DefaultReminder{}).Handle(context.Background(), event))
+}
@@ -0,0 +1,55 @@
+package reminders
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"strconv"
+	"time"
+	"todo-app/internal/domain/events"
+)
+
+// EventHandler takes events for the user's webhooks; webhook.Dispatcher is
+// one.
+type EventHandler interface {
+	Handle(ctx context.Context, event *events.Event) error
+}
+
+// Webhook sends reminders as reminder.due events to the webhooks subscribed
+// to them.
+type Webhook struct {
+	handler EventHandler
+	now     func() time.Time
+}
+
+func NewWebhook(handler EventHandler) *Webhook {
+	return &Webhook{handler: handler, now: time.Now}
+}
+
+func (w *Webhook) Name() string { return ChannelWebhook }
+
+func (w *Webhook) Send(ctx context.Context, r Reminder) error {
+	event := &events.Event{
+		ID:         eventID(r.Key()),
+		Version:    events.Version,
+		Type:       events.ReminderDue,
+		OccurredAt: w.now().UTC(),
+		UserID:     r.UserID,
+		TaskID:     r.TaskID,
+		Data: map[string]string{
+			"reminder_id":    strconv.FormatInt(r.ID, 10),
+			"due_at":         r.DueAt.UTC().Format(time.RFC3339),
+			"offset_minutes": strconv.Itoa(int(r.OffsetMinutes)),
+		},
+	}
+	return w.handler.Handle(ctx, event)
+}
+
+// eventID returns a name-based (version 5 style) UUID of key, so a reminder
+// sent again is the same event and is not delivered to a webhook twice.
+func eventID(key string) string {
+	b := sha1.Sum([]byte(key))
+	b[6] = b[6]&0x0f | 0x50
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
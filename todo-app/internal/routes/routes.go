@@ -3,12 +3,25 @@ package routes
 import (
 	"log/slog"
 	"net/http"
+	"todo-app/internal/handlers/attachments"
+	"todo-app/internal/handlers/batch"
+	"todo-app/internal/handlers/calendar"
+	"todo-app/internal/handlers/comments"
 	"todo-app/internal/handlers/create"
 	"todo-app/internal/handlers/delete"
+	"todo-app/internal/handlers/feed"
+	"todo-app/internal/handlers/history"
+	"todo-app/internal/handlers/notifications"
+	"todo-app/internal/handlers/projects"
 	"todo-app/internal/handlers/read"
+	"todo-app/internal/handlers/reminders"
+	"todo-app/internal/handlers/shares"
+	"todo-app/internal/handlers/transfer"
 	"todo-app/internal/handlers/update"
+	"todo-app/internal/handlers/webhooks"
 	kafka "todo-app/internal/kafka/producer"
 	mwAuth "todo-app/internal/middleware/auth"
+	mwLogger "todo-app/internal/middleware/logger"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -16,9 +29,12 @@ import (
 	"todo-app/internal/token"
 	"os"
 	"github.com/rail52/myprojects/dbpb"
+	"todo-app/internal/storage/blob"
+	"todo-app/internal/live"
+	"todo-app/internal/users"
 )
 
-func NewRouter(log *slog.Logger,client dbpb.PostgresClient, kafkaProducer *kafka.Producer) http.Handler {
+func NewRouter(log *slog.Logger,client dbpb.PostgresClient, kafkaProducer *kafka.Producer, blobs blob.Store, limits attachments.Limits, hub *live.Hub, finder *users.Client) http.Handler {
 	// tokenManager (public key)
 	TokenMn, err := token.NewTokenManagerRSA(os.Getenv("JWT_PUBLIC_KEY_PATH"))
 	if err != nil {
@@ -29,16 +45,85 @@ func NewRouter(log *slog.Logger,client dbpb.PostgresClient, kafkaProducer *kafka
 
 	router.Use(middleware.RequestID)
 	router.Use(middleware.Recoverer)
-	router.Use(middleware.Logger)
+	router.Use(mwLogger.Redacted)
 
 	router.Route("/tasks", func(r chi.Router) {
 		r.Use(mwAuth.AuthMiddleware(TokenMn, log))
-		r.Post("/", create.CreateTask(log, client, kafkaProducer))
+		r.Post("/", create.CreateTask(log, client))
 		r.Get("/", read.GetTasks(log, client, kafkaProducer))
+		r.Get("/trash", read.GetTrash(log, client, kafkaProducer))
+		r.Get("/search", read.SearchTasks(log, client))
+		r.Get("/events", feed.TaskEvents(log, hub))
+		r.Get("/export", transfer.Export(log, client))
+		r.Post("/import", transfer.Import(log, client))
 		r.Get("/{id}", read.GetTask(log, client, kafkaProducer))
-		r.Put("/{id}", update.UpdateTask(log, client, kafkaProducer))
-		r.Patch("/{id}", update.MarkAsDone(log, client, kafkaProducer))
-		r.Delete("/{id}", delete.DeleteTask(log, client, kafkaProducer))
+		r.Put("/{id}", update.UpdateTask(log, client))
+		r.Patch("/{id}", update.PatchTask(log, client))
+		r.Delete("/{id}", delete.DeleteTask(log, client))
+		r.Post("/{id}/subtasks", create.CreateSubtask(log, client))
+		r.Get("/{id}/subtasks", read.GetSubtasks(log, client, kafkaProducer))
+		r.Get("/{id}/occurrences", read.GetOccurrences(log, client))
+		r.Post("/{id}/restore", delete.RestoreTask(log, client))
+		r.Get("/{id}/history", history.GetTaskHistory(log, client))
+		r.Post("/{id}/revert", history.RevertTask(log, client))
+		r.Post("/{id}/comments", comments.CreateComment(log, client))
+		r.Get("/{id}/comments", comments.ListComments(log, client))
+		r.Patch("/{id}/comments/{cid}", comments.UpdateComment(log, client))
+		r.Delete("/{id}/comments/{cid}", comments.DeleteComment(log, client))
+		r.Post("/{id}/attachments", attachments.UploadAttachment(log, client, blobs, limits))
+		r.Get("/{id}/attachments", attachments.ListAttachments(log, client))
+		r.Get("/{id}/attachments/{aid}", attachments.DownloadAttachment(log, client, blobs))
+		r.Delete("/{id}/attachments/{aid}", attachments.DeleteAttachment(log, client, blobs))
+		r.Post("/{id}/reminders", reminders.CreateReminder(log, client))
+		r.Get("/{id}/reminders", reminders.ListReminders(log, client))
+		r.Delete("/{id}/reminders/{rid}", reminders.DeleteReminder(log, client))
+		r.Post("/{id}/shares", shares.CreateTaskShare(log, client, finder))
+		r.Get("/{id}/shares", shares.ListTaskShares(log, client))
+		r.Delete("/{id}/shares/{userId}", shares.DeleteTaskShare(log, client))
+	})
+
+	router.With(mwAuth.AuthMiddleware(TokenMn, log)).Post("/tasks:batch", batch.BatchTasks(log, client))
+	// Calendar apps cannot send the Authorization header; the feed takes a
+	// token of its own.
+	router.Get(calendar.FeedPath, calendar.Feed(log, client))
+
+	router.Route("/calendar", func(r chi.Router) {
+		r.Use(mwAuth.AuthMiddleware(TokenMn, log))
+		r.Post("/token", calendar.CreateToken(log, client))
+		r.Get("/token", calendar.GetToken(log, client))
+		r.Delete("/token", calendar.RevokeToken(log, client))
+	})
+
+	router.Route("/projects", func(r chi.Router) {
+		r.Use(mwAuth.AuthMiddleware(TokenMn, log))
+		r.Post("/", projects.CreateProject(log, client))
+		r.Get("/", projects.ListProjects(log, client))
+		r.Get("/{id}", projects.GetProject(log, client))
+		r.Patch("/{id}", projects.UpdateProject(log, client))
+		r.Delete("/{id}", projects.DeleteProject(log, client))
+		r.Get("/{id}/tasks", read.GetProjectTasks(log, client, kafkaProducer))
+		r.Post("/{id}/shares", shares.CreateProjectShare(log, client, finder))
+		r.Get("/{id}/shares", shares.ListProjectShares(log, client))
+		r.Delete("/{id}/shares/{userId}", shares.DeleteProjectShare(log, client))
+	})
+
+	router.Route("/webhooks", func(r chi.Router) {
+		r.Use(mwAuth.AuthMiddleware(TokenMn, log))
+		r.Post("/", webhooks.CreateWebhook(log, client))
+		r.Get("/", webhooks.ListWebhooks(log, client))
+		r.Get("/{id}", webhooks.GetWebhook(log, client))
+		r.Patch("/{id}", webhooks.UpdateWebhook(log, client))
+		r.Delete("/{id}", webhooks.DeleteWebhook(log, client))
+		r.Get("/{id}/deliveries", webhooks.ListDeliveries(log, client))
+		r.Post("/{id}/deliveries/{did}/redeliver", webhooks.Redeliver(log, client))
+	})
+
+	router.Route("/notifications", func(r chi.Router) {
+		r.Use(mwAuth.AuthMiddleware(TokenMn, log))
+		r.Get("/", notifications.ListNotifications(log, client))
+		r.Post("/read", notifications.MarkRead(log, client))
+		r.Get("/settings", notifications.GetSettings(log, client))
+		r.Put("/settings", notifications.UpdateSettings(log, client))
 	})
 
 	return router
@@ -0,0 +1,147 @@
+// Package analytics is a consumer sink that counts events per user, day and
+// type, and adds the counts to the db service's daily statistics in batches.
+//
+// Counts are kept in memory between flushes, so those of the last interval
+// are lost if the process dies without shutting down; the statistics are meant
+// for trends, not accounting.
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/lib/logger/sl"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type Config struct {
+	FlushInterval time.Duration `yaml:"flush_interval" env:"ANALYTICS_FLUSH_INTERVAL" env-default:"1m"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=StatsStore
+type StatsStore interface {
+	AddTaskStats(ctx context.Context, in *dbpb.AddTaskStatsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type key struct {
+	day       string
+	eventType string
+}
+
+// Aggregator is the sink. Run must be running for the counts to be stored.
+type Aggregator struct {
+	store    StatsStore
+	interval time.Duration
+	log      *slog.Logger
+
+	mu     sync.Mutex
+	counts map[int64]map[key]*dbpb.TaskStat
+}
+
+func New(store StatsStore, cfg Config, log *slog.Logger) *Aggregator {
+	return &Aggregator{
+		store:    store,
+		interval: cfg.FlushInterval,
+		log:      log,
+		counts:   map[int64]map[key]*dbpb.TaskStat{},
+	}
+}
+
+func (a *Aggregator) Name() string { return "analytics" }
+
+// Handle counts event for its user on the UTC day it occurred. Events without
+// a user are not counted.
+func (a *Aggregator) Handle(_ context.Context, event *events.Event) error {
+	if event.UserID == 0 {
+		return nil
+	}
+	stat := &dbpb.TaskStat{
+		Day:       event.OccurredAt.UTC().Format(time.DateOnly),
+		EventType: event.Type,
+		Count:     1,
+	}
+	if event.Type == events.TaskDone && event.After != nil {
+		if lead := event.After.UpdatedAt.Sub(event.After.CreatedAt); lead > 0 {
+			stat.CompletionSeconds = int64(lead / time.Second)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.add(event.UserID, stat)
+	return nil
+}
+
+// add sums stat into the counts; a.mu must be held.
+func (a *Aggregator) add(uid int64, stat *dbpb.TaskStat) {
+	user, ok := a.counts[uid]
+	if !ok {
+		user = map[key]*dbpb.TaskStat{}
+		a.counts[uid] = user
+	}
+	k := key{day: stat.GetDay(), eventType: stat.GetEventType()}
+	if sum, ok := user[k]; ok {
+		sum.Count += stat.GetCount()
+		sum.CompletionSeconds += stat.GetCompletionSeconds()
+		return
+	}
+	user[k] = stat
+}
+
+// Flush stores the counts gathered so far, one call per user. Counts that
+// could not be stored are kept for the next flush.
+func (a *Aggregator) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	counts := a.counts
+	a.counts = map[int64]map[key]*dbpb.TaskStat{}
+	a.mu.Unlock()
+
+	var errs error
+	for uid, user := range counts {
+		req := &dbpb.AddTaskStatsRequest{}
+		for _, stat := range user {
+			req.Stats = append(req.Stats, stat)
+		}
+		if _, err := a.store.AddTaskStats(mwAuth.WithUser(ctx, uid), req); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("user %d: %w", uid, err))
+			a.mu.Lock()
+			for _, stat := range req.Stats {
+				a.add(uid, stat)
+			}
+			a.mu.Unlock()
+		}
+	}
+	return errs
+}
+
+// Run flushes every interval until ctx is done, and once more after that.
+func (a *Aggregator) Run(ctx context.Context) {
+	const op = "todo-app/internal/sinks/analytics|Run()"
+	log := a.log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := a.Flush(flushCtx); err != nil {
+				log.Error("failed to store the last event counts", sl.Err(err))
+			}
+			return
+		case <-ticker.C:
+			if err := a.Flush(ctx); err != nil {
+				log.Error("failed to store event counts", sl.Err(err))
+			}
+		}
+	}
+}
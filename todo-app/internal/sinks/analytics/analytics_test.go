@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/lib/logger/slogdiscard"
+	mwAuth "todo-app/internal/middleware/auth"
+	"todo-app/internal/sinks/analytics/mocks"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func forUser(uid int64) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		claims, ok := mwAuth.ClaimsFromContext(ctx)
+		return ok && claims.UserID == uid
+	})
+}
+
+func sorted(req *dbpb.AddTaskStatsRequest) []*dbpb.TaskStat {
+	stats := append([]*dbpb.TaskStat(nil), req.GetStats()...)
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].GetDay() != stats[j].GetDay() {
+			return stats[i].GetDay() < stats[j].GetDay()
+		}
+		return stats[i].GetEventType() < stats[j].GetEventType()
+	})
+	return stats
+}
+
+func TestAggregator(t *testing.T) {
+	store := mocks.NewStatsStore(t)
+	a := New(store, Config{FlushInterval: time.Minute}, slogdiscard.NewDiscardLogger())
+	ctx := context.Background()
+	day := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	created := day.Add(-2 * time.Hour)
+
+	for _, e := range []*events.Event{
+		{Type: events.TaskCreated, UserID: 7, OccurredAt: day},
+		{Type: events.TaskCreated, UserID: 7, OccurredAt: day},
+		{Type: events.TaskDone, UserID: 7, OccurredAt: day, After: &events.Task{CreatedAt: created, UpdatedAt: day}},
+		{Type: events.TaskCreated, UserID: 7, OccurredAt: day.Add(time.Hour)},
+		{Type: events.TaskFetched, OccurredAt: day},
+	} {
+		require.NoError(t, a.Handle(ctx, e))
+	}
+
+	var got *dbpb.AddTaskStatsRequest
+	store.On("AddTaskStats", forUser(7), mock.Anything).
+		Run(func(args mock.Arguments) { got = args.Get(1).(*dbpb.AddTaskStatsRequest) }).
+		Return(&emptypb.Empty{}, nil).Once()
+	require.NoError(t, a.Flush(ctx))
+
+	stats := sorted(got)
+	require.Len(t, stats, 3)
+	assert.Equal(t, "2026-03-01", stats[0].GetDay())
+	assert.Equal(t, events.TaskCreated, stats[0].GetEventType())
+	assert.EqualValues(t, 2, stats[0].GetCount())
+	assert.Equal(t, events.TaskDone, stats[1].GetEventType())
+	assert.EqualValues(t, 1, stats[1].GetCount())
+	assert.EqualValues(t, 7200, stats[1].GetCompletionSeconds())
+	assert.Equal(t, "2026-03-02", stats[2].GetDay())
+
+	// Nothing is left to flush.
+	require.NoError(t, a.Flush(ctx))
+}
+
+func TestAggregatorKeepsCountsThatFailedToStore(t *testing.T) {
+	store := mocks.NewStatsStore(t)
+	a := New(store, Config{FlushInterval: time.Minute}, slogdiscard.NewDiscardLogger())
+	ctx := context.Background()
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, a.Handle(ctx, &events.Event{Type: events.TaskUpdated, UserID: 7, OccurredAt: day}))
+	store.On("AddTaskStats", forUser(7), mock.Anything).Return(nil, errors.New("unavailable")).Once()
+	require.Error(t, a.Flush(ctx))
+
+	require.NoError(t, a.Handle(ctx, &events.Event{Type: events.TaskUpdated, UserID: 7, OccurredAt: day}))
+	var got *dbpb.AddTaskStatsRequest
+	store.On("AddTaskStats", forUser(7), mock.Anything).
+		Run(func(args mock.Arguments) { got = args.Get(1).(*dbpb.AddTaskStatsRequest) }).
+		Return(&emptypb.Empty{}, nil).Once()
+	require.NoError(t, a.Flush(ctx))
+	require.Len(t, got.GetStats(), 1)
+	assert.EqualValues(t, 2, got.GetStats()[0].GetCount())
+}
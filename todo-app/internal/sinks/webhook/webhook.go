@@ -0,0 +1,260 @@
+// Package webhook is a consumer sink that calls the webhooks users register
+// for their events.
+//
+// Every event becomes one delivery per subscribed webhook, stored by the db
+// service, and is sent right away. Failed deliveries are retried by Run with
+// exponential backoff until they succeed or run out of attempts; each attempt
+// is logged with its response code.
+//
+// A delivery is a POST of the event as JSON. X-Webhook-Signature holds
+// "sha256=" and the hex HMAC-SHA256, keyed with the webhook's secret, of
+// X-Webhook-Timestamp (Unix seconds), a dot and the body. Receivers should
+// check it, reject old timestamps and deduplicate on X-Event-ID, since a
+// delivery may arrive more than once.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/kafka/consumer"
+	"todo-app/internal/lib/backoff"
+	"todo-app/internal/lib/logger/sl"
+	mwAuth "todo-app/internal/middleware/auth"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Config struct {
+	Timeout time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT" env-default:"5s"`
+	// MaxAttempts includes the first one.
+	MaxAttempts int `yaml:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"8"`
+	// The n-th retry waits BaseDelay * 2^(n-1), at most MaxDelay.
+	BaseDelay    time.Duration `yaml:"base_delay" env:"WEBHOOKS_BASE_DELAY" env-default:"30s"`
+	MaxDelay     time.Duration `yaml:"max_delay" env:"WEBHOOKS_MAX_DELAY" env-default:"1h"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOKS_POLL_INTERVAL" env-default:"5s"`
+	BatchSize    int           `yaml:"batch_size" env:"WEBHOOKS_BATCH_SIZE" env-default:"20"`
+	// Lease is how long a delivery is reserved for the replica sending it.
+	// It must be longer than Timeout.
+	Lease time.Duration `yaml:"lease" env:"WEBHOOKS_LEASE" env-default:"1m"`
+	// AllowPrivateNetworks lets webhooks call loopback and private addresses,
+	// which are refused by default so users cannot reach internal services.
+	AllowPrivateNetworks bool `yaml:"allow_private_networks" env:"WEBHOOKS_ALLOW_PRIVATE_NETWORKS"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=DeliveryStore
+type DeliveryStore interface {
+	EnqueueWebhookDeliveries(ctx context.Context, in *dbpb.EnqueueWebhookDeliveriesRequest, opts ...grpc.CallOption) (*dbpb.WebhookJobs, error)
+	ClaimWebhookDeliveries(ctx context.Context, in *dbpb.ClaimWebhookDeliveriesRequest, opts ...grpc.CallOption) (*dbpb.WebhookJobs, error)
+	RecordWebhookAttempt(ctx context.Context, in *dbpb.RecordWebhookAttemptRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type Dispatcher struct {
+	store  DeliveryStore
+	cfg    Config
+	client *http.Client
+	log    *slog.Logger
+	now    func() time.Time
+}
+
+func New(store DeliveryStore, cfg Config, log *slog.Logger) *Dispatcher {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = publicOnly
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &Dispatcher{
+		store: store,
+		cfg:   cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+			// A redirect is reported as the response it is.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		log: log,
+		now: time.Now,
+	}
+}
+
+func (d *Dispatcher) Name() string { return "webhooks" }
+
+// Handle creates the deliveries of event and sends them. Only failing to
+// create them fails the event; failed sends are retried by Run.
+func (d *Dispatcher) Handle(ctx context.Context, event *events.Event) error {
+	if event.UserID == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return consumer.Permanent(fmt.Errorf("marshal event: %w", err))
+	}
+	jobs, err := d.store.EnqueueWebhookDeliveries(mwAuth.WithUser(ctx, event.UserID), &dbpb.EnqueueWebhookDeliveriesRequest{
+		EventId:      event.ID,
+		EventType:    event.Type,
+		Payload:      payload,
+		LeaseSeconds: int32(d.cfg.Lease / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("enqueue deliveries: %w", err)
+	}
+	d.deliverAll(ctx, jobs.GetJobs())
+	return nil
+}
+
+// Run sends the deliveries that are due for a retry until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	const op = "todo-app/internal/sinks/webhook|Run()"
+	log := d.log.With(slog.String("op", op))
+
+	for {
+		jobs, err := d.store.ClaimWebhookDeliveries(ctx, &dbpb.ClaimWebhookDeliveriesRequest{
+			Limit:        int32(d.cfg.BatchSize),
+			LeaseSeconds: int32(d.cfg.Lease / time.Second),
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Error("failed to claim webhook deliveries", sl.Err(err))
+		}
+		d.deliverAll(ctx, jobs.GetJobs())
+
+		wait := d.cfg.PollInterval
+		if err == nil && len(jobs.GetJobs()) == d.cfg.BatchSize {
+			// More are due.
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (d *Dispatcher) deliverAll(ctx context.Context, jobs []*dbpb.WebhookJob) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, job)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver sends job once and records the outcome. Should recording fail, the
+// lease runs out and the delivery is sent again.
+func (d *Dispatcher) deliver(ctx context.Context, job *dbpb.WebhookJob) {
+	const op = "todo-app/internal/sinks/webhook|deliver()"
+	log := d.log.With(
+		slog.String("op", op),
+		slog.Int64("webhook_id", job.GetWebhookId()),
+		slog.Int64("delivery_id", job.GetDeliveryId()),
+	)
+
+	start := d.now()
+	code, err := d.send(ctx, job)
+	record := &dbpb.RecordWebhookAttemptRequest{
+		DeliveryId:   job.GetDeliveryId(),
+		Delivered:    err == nil,
+		ResponseCode: int32(code),
+		DurationMs:   d.now().Sub(start).Milliseconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+		attempts := int(job.GetAttempts()) + 1
+		if retryable(code) && attempts < d.cfg.MaxAttempts {
+			record.RetryAt = timestamppb.New(d.now().Add(backoff.Delay(d.cfg.BaseDelay, d.cfg.MaxDelay, attempts)))
+		}
+		log.Warn("webhook delivery failed",
+			slog.Int("attempt", attempts),
+			slog.Bool("will_retry", record.RetryAt != nil),
+			sl.Err(err))
+	}
+	if _, err := d.store.RecordWebhookAttempt(mwAuth.WithUser(ctx, job.GetUserId()), record); err != nil {
+		log.Error("failed to record webhook attempt", sl.Err(err))
+	}
+}
+
+// send posts the payload of job and returns the response code, 0 if there
+// was no response.
+func (d *Dispatcher) send(ctx context.Context, job *dbpb.WebhookJob) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.GetUrl(), bytes.NewReader(job.GetPayload()))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-app-webhooks")
+	req.Header.Set("X-Webhook-ID", strconv.FormatInt(job.GetWebhookId(), 10))
+	req.Header.Set("X-Delivery-ID", strconv.FormatInt(job.GetDeliveryId(), 10))
+	req.Header.Set("X-Event-ID", job.GetEventId())
+	req.Header.Set("X-Event-Type", job.GetEventType())
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", Sign(job.GetSecret(), timestamp, job.GetPayload()))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, errors.New(resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the X-Webhook-Signature of body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryable reports whether a failed delivery may succeed later: no
+// response, server errors, timeouts and rate limits.
+func retryable(code int) bool {
+	return code == 0 || code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+var errPrivateAddress = errors.New("webhooks may not call private addresses")
+
+// publicOnly refuses connections to loopback, private, link-local and
+// unspecified addresses. It runs after name resolution, so names pointing
+// there are refused too.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return errPrivateAddress
+	}
+	return nil
+}
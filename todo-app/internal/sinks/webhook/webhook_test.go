@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"todo-app/internal/domain/events"
+	"todo-app/internal/lib/logger/slogdiscard"
+	mwAuth "todo-app/internal/middleware/auth"
+	"todo-app/internal/sinks/webhook/mocks"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var testConfig = Config{
+	Timeout:              time.Second,
+	MaxAttempts:          5,
+	BaseDelay:            time.Minute,
+	MaxDelay:             time.Hour,
+	Lease:                time.Minute,
+	AllowPrivateNetworks: true,
+}
+
+func forUser(uid int64) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		claims, ok := mwAuth.ClaimsFromContext(ctx)
+		return ok && claims.UserID == uid
+	})
+}
+
+func TestHandle(t *testing.T) {
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	store := mocks.NewDeliveryStore(t)
+	d := New(store, testConfig, slogdiscard.NewDiscardLogger())
+	event := &events.Event{ID: "e1", Type: events.TaskDone, UserID: 7, TaskID: 3}
+
+	var payload []byte
+	store.On("EnqueueWebhookDeliveries", forUser(7), mock.MatchedBy(func(req *dbpb.EnqueueWebhookDeliveriesRequest) bool {
+		return req.GetEventId() == "e1" && req.GetEventType() == events.TaskDone && req.GetLeaseSeconds() == 60
+	})).
+		Run(func(args mock.Arguments) { payload = args.Get(1).(*dbpb.EnqueueWebhookDeliveriesRequest).GetPayload() }).
+		Return(&dbpb.WebhookJobs{Jobs: []*dbpb.WebhookJob{{
+			DeliveryId: 11, WebhookId: 5, UserId: 7, Url: srv.URL, Secret: "whsec_test",
+			EventId: "e1", EventType: events.TaskDone,
+		}}}, nil).Once()
+	var record *dbpb.RecordWebhookAttemptRequest
+	store.On("RecordWebhookAttempt", forUser(7), mock.Anything).
+		Run(func(args mock.Arguments) { record = args.Get(1).(*dbpb.RecordWebhookAttemptRequest) }).
+		Return(&emptypb.Empty{}, nil).Once()
+
+	require.NoError(t, d.Handle(context.Background(), event))
+	require.NotNil(t, record)
+	assert.True(t, record.GetDelivered())
+	assert.EqualValues(t, http.StatusNoContent, record.GetResponseCode())
+	assert.EqualValues(t, 11, record.GetDeliveryId())
+
+	assert.JSONEq(t, `{"id":"e1","version":0,"type":"task.done","occurred_at":"0001-01-01T00:00:00Z","user_id":7,"task_id":3}`, string(payload))
+	assert.Equal(t, "e1", header.Get("X-Event-ID"))
+	assert.Equal(t, "11", header.Get("X-Delivery-ID"))
+	timestamp, err := strconv.ParseInt(header.Get("X-Webhook-Timestamp"), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign("whsec_test", timestamp, body), header.Get("X-Webhook-Signature"))
+}
+
+func TestHandleSkipsEventsWithoutUser(t *testing.T) {
+	d := New(mocks.NewDeliveryStore(t), testConfig, slogdiscard.NewDiscardLogger())
+	require.NoError(t, d.Handle(context.Background(), &events.Event{ID: "e1", Type: events.TaskFetched}))
+}
+
+func TestDeliverFailures(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		status   int
+		attempts int32
+		retryIn  time.Duration
+	}{
+		{"server error", http.StatusBadGateway, 0, time.Minute},
+		{"third attempt", http.StatusServiceUnavailable, 2, 4 * time.Minute},
+		{"rate limited", http.StatusTooManyRequests, 1, 2 * time.Minute},
+		{"client error", http.StatusNotFound, 0, 0},
+		{"out of attempts", http.StatusInternalServerError, 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			store := mocks.NewDeliveryStore(t)
+			d := New(store, testConfig, slogdiscard.NewDiscardLogger())
+			d.now = func() time.Time { return now }
+			var record *dbpb.RecordWebhookAttemptRequest
+			store.On("RecordWebhookAttempt", forUser(7), mock.Anything).
+				Run(func(args mock.Arguments) { record = args.Get(1).(*dbpb.RecordWebhookAttemptRequest) }).
+				Return(&emptypb.Empty{}, nil).Once()
+
+			d.deliver(context.Background(), &dbpb.WebhookJob{DeliveryId: 1, UserId: 7, Url: srv.URL, Attempts: tt.attempts})
+
+			require.NotNil(t, record)
+			assert.False(t, record.GetDelivered())
+			assert.EqualValues(t, tt.status, record.GetResponseCode())
+			assert.NotEmpty(t, record.GetError())
+			if tt.retryIn == 0 {
+				assert.Nil(t, record.GetRetryAt())
+			} else {
+				require.NotNil(t, record.GetRetryAt())
+				assert.Equal(t, now.Add(tt.retryIn), record.GetRetryAt().AsTime())
+			}
+		})
+	}
+}
+
+func TestPrivateAddressesAreRefused(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	cfg := testConfig
+	cfg.AllowPrivateNetworks = false
+	store := mocks.NewDeliveryStore(t)
+	d := New(store, cfg, slogdiscard.NewDiscardLogger())
+	var record *dbpb.RecordWebhookAttemptRequest
+	store.On("RecordWebhookAttempt", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { record = args.Get(1).(*dbpb.RecordWebhookAttemptRequest) }).
+		Return(&emptypb.Empty{}, nil).Once()
+
+	d.deliver(context.Background(), &dbpb.WebhookJob{DeliveryId: 1, UserId: 7, Url: srv.URL})
+
+	assert.False(t, called)
+	require.NotNil(t, record)
+	assert.Contains(t, record.GetError(), errPrivateAddress.Error())
+	assert.NotNil(t, record.GetRetryAt())
+}
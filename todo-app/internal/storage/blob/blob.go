@@ -0,0 +1,29 @@
+// Package blob defines where todo-app keeps the bytes of task attachments.
+// Implementations live in the fs and s3 subpackages.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// AttachmentKey is where the bytes of an attachment are kept.
+func AttachmentKey(taskID, id int64) string {
+	return fmt.Sprintf("tasks/%d/%d", taskID, id)
+}
+
+// Store keeps opaque objects under slash-separated keys.
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any previous
+	// object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the object for reading; Seek lets callers serve byte
+	// ranges. It returns ErrNotFound for unknown keys.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the object. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,55 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"todo-app/internal/storage/blob"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	s, err := New(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "tasks/1/2", strings.NewReader("hello world"), 11, "text/plain"))
+
+	obj, err := s.Open(ctx, "tasks/1/2")
+	require.NoError(t, err)
+	_, err = obj.Seek(6, io.SeekStart)
+	require.NoError(t, err)
+	got, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(got))
+	require.NoError(t, obj.Close())
+
+	require.NoError(t, s.Delete(ctx, "tasks/1/2"))
+	_, err = s.Open(ctx, "tasks/1/2")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+	assert.NoError(t, s.Delete(ctx, "tasks/1/2"))
+}
+
+func TestPutShortRead(t *testing.T) {
+	s, err := New(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	err = s.Put(context.Background(), "a", strings.NewReader("abc"), 5, "")
+	assert.Error(t, err)
+	_, err = s.Open(context.Background(), "a")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+func TestInvalidKey(t *testing.T) {
+	s, err := New(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../etc/passwd", "a/../../b", "/abs"} {
+		_, err := s.Open(context.Background(), key)
+		assert.Error(t, err, key)
+		assert.NotErrorIs(t, err, blob.ErrNotFound, key)
+	}
+}
@@ -0,0 +1,219 @@
+// Package s3 is a blob.Store for S3 and S3-compatible services such as MinIO.
+// It speaks the REST API directly and signs requests with AWS Signature
+// Version 4.
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"todo-app/internal/storage/blob"
+)
+
+type Config struct {
+	// Endpoint is the service URL, e.g. https://s3.eu-central-1.amazonaws.com
+	// or http://minio:9000.
+	Endpoint  string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region    string `yaml:"region" env:"S3_REGION" env-default:"us-east-1"`
+	Bucket    string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKey string `yaml:"access_key" env:"S3_ACCESS_KEY"`
+	SecretKey string `yaml:"secret_key" env:"S3_SECRET_KEY"`
+	// PathStyle puts the bucket into the path instead of the host name, which
+	// most S3-compatible services need.
+	PathStyle bool `yaml:"path_style" env:"S3_PATH_STYLE" env-default:"true"`
+}
+
+type Store struct {
+	cfg      Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+func New(cfg Config) (*Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("s3.New: invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3.New: bucket is required")
+	}
+	return &Store{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+// objectURL returns the URL of key together with its already escaped path.
+func (s *Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	path := strings.TrimSuffix(u.Path, "/")
+	if s.cfg.PathStyle {
+		path += "/" + s.cfg.Bucket
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	path += "/" + key
+	u.Path = path
+	u.RawPath = escapePath(path)
+	return &u
+}
+
+func (s *Store) do(ctx context.Context, method, key string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	s.sign(req)
+	return s.client.Do(req)
+}
+
+// statusError reads the S3 error document into the returned error.
+func statusError(op string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s: %s: %s", op, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), io.NopCloser(r))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError("s3 put "+key, resp)
+	}
+	return nil
+}
+
+// Open checks that the object exists and learns its size; the content is
+// fetched lazily by the returned reader.
+func (s *Store) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, blob.ErrNotFound
+	default:
+		return nil, fmt.Errorf("s3 head %s: %s", key, resp.Status)
+	}
+	if resp.ContentLength < 0 {
+		return nil, fmt.Errorf("s3 head %s: no content length", key)
+	}
+	return &object{ctx: ctx, store: s, key: key, size: resp.ContentLength}, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return statusError("s3 delete "+key, resp)
+	}
+	return nil
+}
+
+// object reads an S3 object with ranged GETs. A Seek drops the current
+// response and the next Read starts a new one at the new offset.
+type object struct {
+	ctx   context.Context
+	store *Store
+	key   string
+	size  int64
+	off   int64
+	body  io.ReadCloser
+}
+
+func (o *object) Read(p []byte) (int, error) {
+	if o.off >= o.size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		header := http.Header{"Range": {fmt.Sprintf("bytes=%d-", o.off)}}
+		resp, err := o.store.do(o.ctx, http.MethodGet, o.key, nil, header)
+		if err != nil {
+			return 0, err
+		}
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+		case http.StatusOK:
+			// The server ignored Range; skip to the offset ourselves.
+			if _, err := io.CopyN(io.Discard, resp.Body, o.off); err != nil {
+				resp.Body.Close()
+				return 0, err
+			}
+		case http.StatusNotFound:
+			resp.Body.Close()
+			return 0, blob.ErrNotFound
+		default:
+			defer resp.Body.Close()
+			return 0, statusError("s3 get "+o.key, resp)
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.off += int64(n)
+	if err == io.EOF && o.off < o.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (o *object) Seek(offset int64, whence int) (int64, error) {
+	var off int64
+	switch whence {
+	case io.SeekStart:
+		off = offset
+	case io.SeekCurrent:
+		off = o.off + offset
+	case io.SeekEnd:
+		off = o.size + offset
+	default:
+		return 0, fmt.Errorf("s3 object: invalid whence %d", whence)
+	}
+	if off < 0 {
+		return 0, fmt.Errorf("s3 object: negative position")
+	}
+	if off != o.off && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.off = off
+	return off, nil
+}
+
+func (o *object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
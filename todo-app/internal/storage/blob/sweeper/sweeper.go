@@ -0,0 +1,101 @@
+// Package sweeper removes the blobs of attachments that the db service has
+// deleted.
+//
+// Attachments do not only go through DELETE /tasks/{id}/attachments/{aid}:
+// they are deleted with their task when it is deleted for good or purged from
+// the trash, and with its project, all inside the db service. A trigger there
+// leaves a tombstone for every one of them. Sweeper leases the tombstones with
+// ClaimBlobDeletions, removes the blobs and drops the tombstones; a tombstone
+// whose blob could not be removed is handed out again when its lease ends.
+package sweeper
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"todo-app/internal/lib/logger/sl"
+	"todo-app/internal/storage/blob"
+
+	"github.com/rail52/myprojects/dbpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type Config struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"ATTACHMENTS_SWEEP_INTERVAL" env-default:"1m"`
+	BatchSize    int           `yaml:"batch_size" env:"ATTACHMENTS_SWEEP_BATCH_SIZE" env-default:"100"`
+	// Lease is how long tombstones are reserved for the replica removing
+	// their blobs.
+	Lease time.Duration `yaml:"lease" env:"ATTACHMENTS_SWEEP_LEASE" env-default:"5m"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Store
+type Store interface {
+	ClaimBlobDeletions(ctx context.Context, in *dbpb.ClaimBlobDeletionsRequest, opts ...grpc.CallOption) (*dbpb.BlobDeletions, error)
+	CompleteBlobDeletions(ctx context.Context, in *dbpb.CompleteBlobDeletionsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type Sweeper struct {
+	store Store
+	blobs blob.Store
+	cfg   Config
+	log   *slog.Logger
+}
+
+func New(store Store, blobs blob.Store, cfg Config, log *slog.Logger) *Sweeper {
+	return &Sweeper{store: store, blobs: blobs, cfg: cfg, log: log}
+}
+
+// Run removes the blobs of deleted attachments until ctx is done.
+func (s *Sweeper) Run(ctx context.Context) {
+	const op = "todo-app/internal/storage/blob/sweeper|Run()"
+	log := s.log.With(slog.String("op", op))
+
+	for {
+		n, err := s.Sweep(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("failed to sweep attachment blobs", sl.Err(err))
+		}
+
+		wait := s.cfg.PollInterval
+		if err == nil && n == s.cfg.BatchSize {
+			// More are waiting.
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Sweep handles one batch of tombstones and returns how many it claimed.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	const op = "todo-app/internal/storage/blob/sweeper|Sweep()"
+	log := s.log.With(slog.String("op", op))
+
+	claimed, err := s.store.ClaimBlobDeletions(ctx, &dbpb.ClaimBlobDeletionsRequest{
+		Limit:        int32(s.cfg.BatchSize),
+		LeaseSeconds: int32(s.cfg.Lease / time.Second),
+	})
+	if err != nil {
+		return 0, err
+	}
+	deletions := claimed.GetDeletions()
+	var done []int64
+	for _, d := range deletions {
+		key := blob.AttachmentKey(d.GetTaskId(), d.GetAttachmentId())
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Warn("failed to delete attachment blob", slog.String("key", key), sl.Err(err))
+			continue
+		}
+		done = append(done, d.GetId())
+	}
+	if len(done) > 0 {
+		if _, err := s.store.CompleteBlobDeletions(ctx, &dbpb.CompleteBlobDeletionsRequest{Ids: done}); err != nil {
+			return len(deletions), err
+		}
+	}
+	return len(deletions), nil
+}
@@ -0,0 +1,86 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"todo-app/internal/lib/logger/slogdiscard"
+	"todo-app/internal/storage/blob"
+	"todo-app/internal/storage/blob/sweeper/mocks"
+
+	"github.com/rail52/myprojects/dbpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// blobs is an in-memory blob.Store whose Delete fails for the keys in broken.
+type blobs struct {
+	objects map[string]string
+	broken  map[string]bool
+}
+
+func (b *blobs) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(r)
+	b.objects[key] = string(data)
+	return err
+}
+
+func (b *blobs) Open(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, blob.ErrNotFound
+	}
+	return nopCloser{strings.NewReader(data)}, nil
+}
+
+func (b *blobs) Delete(_ context.Context, key string) error {
+	if b.broken[key] {
+		return errors.New("storage is down")
+	}
+	delete(b.objects, key)
+	return nil
+}
+
+type nopCloser struct{ io.ReadSeeker }
+
+func (nopCloser) Close() error { return nil }
+
+func TestSweep(t *testing.T) {
+	store := mocks.NewStore(t)
+	b := &blobs{
+		objects: map[string]string{"tasks/1/10": "a", "tasks/2/20": "b", "tasks/3/30": "c"},
+		broken:  map[string]bool{"tasks/2/20": true},
+	}
+	s := New(store, b, Config{BatchSize: 10}, slogdiscard.NewDiscardLogger())
+
+	store.On("ClaimBlobDeletions", mock.Anything, &dbpb.ClaimBlobDeletionsRequest{Limit: 10}).
+		Return(&dbpb.BlobDeletions{Deletions: []*dbpb.BlobDeletion{
+			{Id: 1, TaskId: 1, AttachmentId: 10},
+			{Id: 2, TaskId: 2, AttachmentId: 20},
+			// Never stored, e.g. an upload that failed: nothing to remove.
+			{Id: 3, TaskId: 4, AttachmentId: 40},
+		}}, nil)
+	// The broken blob keeps its tombstone, to be claimed again later.
+	store.On("CompleteBlobDeletions", mock.Anything, &dbpb.CompleteBlobDeletionsRequest{Ids: []int64{1, 3}}).
+		Return(&emptypb.Empty{}, nil)
+
+	n, err := s.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, map[string]string{"tasks/2/20": "b", "tasks/3/30": "c"}, b.objects)
+}
+
+func TestSweepNothingToDo(t *testing.T) {
+	store := mocks.NewStore(t)
+	s := New(store, &blobs{}, Config{BatchSize: 10}, slogdiscard.NewDiscardLogger())
+
+	store.On("ClaimBlobDeletions", mock.Anything, mock.Anything).Return(&dbpb.BlobDeletions{}, nil)
+
+	n, err := s.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
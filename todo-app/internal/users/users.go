@@ -0,0 +1,69 @@
+// Package users asks the auth service about users other than the caller, so
+// tasks can be shared with people by their email address.
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNotFound means no user has the address.
+var ErrNotFound = errors.New("user not found")
+
+type User struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
+
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New returns a client of the auth service at baseURL, such as
+// "http://auth-service:8081".
+func New(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Lookup finds the user with email. authorization is the Authorization
+// header of the request being served: the auth service only answers users
+// who are signed in.
+func (c *Client) Lookup(ctx context.Context, authorization, email string) (User, error) {
+	u := c.baseURL + "/auth/users/lookup?" + url.Values{"email": {email}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return User{}, err
+	}
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return User{}, fmt.Errorf("lookup user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return User{}, ErrNotFound
+	default:
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return User{}, fmt.Errorf("lookup user: %s", resp.Status)
+	}
+	var user User
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&user); err != nil {
+		return User{}, fmt.Errorf("lookup user: %w", err)
+	}
+	return user, nil
+}
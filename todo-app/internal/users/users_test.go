@@ -0,0 +1,41 @@
+package users
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/auth/users/lookup", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Query().Get("email") {
+		case "ann+work@mail.com":
+			_, _ = w.Write([]byte(`{"id":7,"email":"ann+work@mail.com"}`))
+		case "nobody@mail.com":
+			http.Error(w, `{"error":"user not found"}`, http.StatusNotFound)
+		default:
+			http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	c := New(srv.URL+"/", time.Second)
+	ctx := context.Background()
+
+	user, err := c.Lookup(ctx, "Bearer token", "ann+work@mail.com")
+	require.NoError(t, err)
+	assert.Equal(t, User{ID: 7, Email: "ann+work@mail.com"}, user)
+
+	_, err = c.Lookup(ctx, "Bearer token", "nobody@mail.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = c.Lookup(ctx, "Bearer token", "ann@mail.com")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}